@@ -1,7 +1,11 @@
 package main
 
 import (
+	"encoding/hex"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	_ "github.com/JonathanGzzBen/ingenialists/api/v1/docs"
 	"github.com/JonathanGzzBen/ingenialists/api/v1/repository"
@@ -50,9 +54,85 @@ func main() {
 			RedirectURL:  "http://127.0.0.1:8080/v1/auth/google-callback",
 			Scopes:       []string{"openid", "profile", "email"},
 		},
-		CategoriesRepo: repository.NewCategoriesGormRepository(db),
-		UsersRepo:      repository.NewUsersGormRepository(db),
-		ArticlesRepo:   repository.NewArticlesGormRepository(db),
+		// Categories change rarely, and anyone can read them
+		CacheControl: map[string]string{
+			"/v1/categories/":     "public, max-age=300",
+			"/v1/categories/tree": "public, max-age=300",
+		},
+		CategoriesRepo:    repository.NewCategoriesGormRepository(db),
+		UsersRepo:         repository.NewUsersGormRepository(db),
+		ArticlesRepo:      repository.NewArticlesGormRepository(db),
+		RefreshTokensRepo: repository.NewRefreshTokensGormRepository(db),
+		APIKeysRepo:       repository.NewAPIKeysGormRepository(db),
+		RolesRepo:         repository.NewRolesGormRepository(db),
+		RoleRequestsRepo:  repository.NewRoleRequestsGormRepository(db),
+		NotificationsRepo: repository.NewNotificationsGormRepository(db),
+		AuditRepo:         repository.NewAuditGormRepository(db),
+		SeriesRepo:        repository.NewSeriesGormRepository(db),
+		UnitOfWork:        repository.NewGormUnitOfWork(db),
+		Cache:             &repository.CacheConfig{SingleFlight: true},
+		// Logging in and writing are limited more than reading
+		RateLimit: &server.RateLimitConfig{
+			Default: server.RateLimitPolicy{Rate: 10, Burst: 50},
+			Writes:  server.RateLimitPolicy{Rate: 1, Burst: 20},
+			Routes: map[string]server.RateLimitPolicy{
+				"GET /v1/auth/google-login":    {Rate: 0.1, Burst: 5},
+				"GET /v1/auth/google-callback": {Rate: 0.1, Burst: 5},
+				"POST /v1/auth/refresh":        {Rate: 0.1, Burst: 5},
+				"POST /v1/articles/":           {Rate: 0.05, Burst: 10},
+			},
+		},
+	}
+	// Key used to encrypt Google refresh tokens at rest,
+	// hex encoded 32 bytes. In development a random one is
+	// used if it's not set, stored tokens are lost on restart
+	if k := os.Getenv("ING_TOKEN_ENCRYPTION_KEY"); len(k) != 0 {
+		key, err := hex.DecodeString(k)
+		if err != nil || len(key) != 32 {
+			panic("Environment variable ING_TOKEN_ENCRYPTION_KEY must be 32 hex encoded bytes")
+		}
+		serverConfig.TokenEncryptionKey = key
+	} else if os.Getenv("ING_ENVIRONMENT") != "development" {
+		panic("Environment variable ING_TOKEN_ENCRYPTION_KEY missing")
+	}
+	if d := os.Getenv("ING_TRASH_RETENTION_DAYS"); len(d) != 0 {
+		days, err := strconv.Atoi(d)
+		if err != nil || days <= 0 {
+			panic("Environment variable ING_TRASH_RETENTION_DAYS must be a positive number")
+		}
+		serverConfig.TrashRetention = time.Duration(days) * 24 * time.Hour
+	}
+	if ttl := os.Getenv("ING_CACHE_TTL_SECONDS"); len(ttl) != 0 {
+		seconds, err := strconv.Atoi(ttl)
+		if err != nil || seconds <= 0 {
+			panic("Environment variable ING_CACHE_TTL_SECONDS must be a positive number")
+		}
+		serverConfig.Cache.TTL = time.Duration(seconds) * time.Second
+	}
+	// Origins of the web clients that call the API,
+	// comma separated
+	if origins := splitList(os.Getenv("ING_CORS_ORIGINS")); len(origins) != 0 {
+		serverConfig.CORS = &server.CORSConfig{
+			AllowedOrigins: origins,
+			MaxAge:         time.Hour,
+		}
+	} else if os.Getenv("ING_ENVIRONMENT") == "development" {
+		serverConfig.CORS = &server.DevelopmentCORSConfig
+	}
+	// Certificate and key of the server, if set it serves HTTPS
+	certFile, keyFile := os.Getenv("ING_TLS_CERT_FILE"), os.Getenv("ING_TLS_KEY_FILE")
+	if len(certFile) != 0 || len(keyFile) != 0 {
+		if len(certFile) == 0 || len(keyFile) == 0 {
+			panic("Environment variables ING_TLS_CERT_FILE and ING_TLS_KEY_FILE must be set together")
+		}
+		serverConfig.TLS = &server.TLSConfig{CertFile: certFile, KeyFile: keyFile}
+	}
+	// Proxies in front of the server, comma separated
+	if proxies := splitList(os.Getenv("ING_TRUSTED_PROXIES")); len(proxies) != 0 {
+		serverConfig.TrustedProxies = proxies
+	}
+	if os.Getenv("ING_ENVIRONMENT") != "development" {
+		serverConfig.Security.HSTSMaxAge = 365 * 24 * time.Hour
 	}
 	// hostname is used by multiple controllers
 	// to make requests to authentication controller
@@ -68,8 +148,23 @@ func main() {
 		if len(port) == 0 {
 			panic("Environment variable ING_PORT missing")
 		}
-		s.Run(port)
+		err = s.Run(port)
 	} else {
-		s.Run()
+		err = s.Run()
+	}
+	if err != nil {
+		panic(err)
+	}
+}
+
+// splitList returns the comma separated entries of s,
+// without surrounding spaces nor empty entries.
+func splitList(s string) []string {
+	var entries []string
+	for _, e := range strings.Split(s, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			entries = append(entries, e)
+		}
 	}
+	return entries
 }
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// APIKey is a long lived credential a user creates
+// to authenticate scripts without going through OAuth2.
+//
+// Only the hash of the key is stored, the key itself
+// is shown once, when it's created.
+type APIKey struct {
+	ID     uint   `json:"id"`
+	UserID uint   `json:"userId"`
+	Name   string `json:"name"`
+	// Prefix is the beginning of the key, it helps users
+	// identify a key without exposing it
+	Prefix  string `json:"prefix"`
+	KeyHash string `json:"-" gorm:"uniqueIndex"`
+	// Scopes is a comma separated string of scopes
+	Scopes     string     `json:"scopes" example:"articles:write"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	ExpiresAt  *time.Time `json:"expiresAt"`
+	RevokedAt  *time.Time `json:"revokedAt"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// Scope limits what a request authenticated with an APIKey can do.
+type Scope string
+
+const (
+	ScopeArticlesWrite   Scope = "articles:write"
+	ScopeCategoriesAdmin Scope = "categories:admin"
+	ScopeUsersWrite      Scope = "users:write"
+)
+
+// Scopes are all the scopes that can be granted to an APIKey
+var Scopes = []Scope{
+	ScopeArticlesWrite,
+	ScopeCategoriesAdmin,
+	ScopeUsersWrite,
+}
@@ -1,18 +1,48 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type Article struct {
-	ID         uint      `json:"id"`
-	UserID     uint      `json:"userId"`
-	User       User      `json:"user"`
-	CategoryID uint      `json:"categoryId"`
-	Category   Category  `json:"category"`
-	CreatedAt  time.Time `json:"createdAt"`
-	UpdatedAt  time.Time `json:"updtedAt"`
-	Body       string    `json:"body"`
-	Title      string    `json:"title"`
-	ImageURL   string    `json:"imageUrl"`
+	ID uint `json:"id"`
+	// UserID is the ID of the user that created the article
+	UserID uint  `json:"userId"`
+	User   *User `json:"user,omitempty"`
+	// Authors are everyone credited for the article,
+	// including the user that created it
+	Authors    []ArticleAuthor `json:"authors,omitempty"`
+	CategoryID uint            `json:"categoryId"`
+	Category   *Category       `json:"category,omitempty"`
+	CreatedAt  time.Time       `json:"createdAt"`
+	UpdatedAt  time.Time       `json:"updtedAt"`
+	// Version is incremented every time the article is updated
+	Version uint `json:"version" gorm:"not null;default:1"`
+	// Body is left out of article lists unless requested
+	Body string `json:"body,omitempty"`
+	// WordCount, ReadingTime and Excerpt are computed from Body when saved
+	WordCount int `json:"wordCount"`
+	// ReadingTime is the estimated reading time in minutes
+	ReadingTime int    `json:"readingTime"`
+	Excerpt     string `json:"excerpt"`
+	Title       string `json:"title"`
+	// Slug is generated from Title and changes when the article is renamed
+	Slug     string `json:"slug" gorm:"uniqueIndex"`
+	ImageURL string `json:"imageUrl"`
 	// Tags is a comma separated string of tags
 	Tags string `json:"tags"`
+	// PublishAt is when a scheduled article is due to be published
+	PublishAt *time.Time `json:"publishAt"`
+	// PublishedAt is nil until the article is published
+	PublishedAt *time.Time `json:"publishedAt" gorm:"index"`
+	// ArchivedAt is when the article was archived, archived articles
+	// are left out of article lists but can still be read
+	ArchivedAt *time.Time     `json:"archivedAt" gorm:"index"`
+	DeletedAt  gorm.DeletedAt `json:"deletedAt" gorm:"index" swaggertype:"string"`
+	// DeletedBy is the ID of the user that moved the article to trash
+	DeletedBy *uint `json:"deletedBy"`
+	// Series is the series the article is part of, if any
+	Series *SeriesNavigation `json:"series,omitempty" gorm:"-"`
 }
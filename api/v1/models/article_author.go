@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// AuthorRole is the part an author had in writing an article.
+type AuthorRole string
+
+const (
+	AuthorRoleAuthor      AuthorRole = "author"
+	AuthorRoleContributor AuthorRole = "contributor"
+	AuthorRoleEditor      AuthorRole = "editor"
+)
+
+// AuthorRoles lists every valid AuthorRole.
+var AuthorRoles = []AuthorRole{
+	AuthorRoleAuthor,
+	AuthorRoleContributor,
+	AuthorRoleEditor,
+}
+
+// ArticleAuthor credits user with UserID as one of
+// the authors of article with ArticleID.
+type ArticleAuthor struct {
+	ArticleID uint       `json:"articleId" gorm:"primaryKey"`
+	UserID    uint       `json:"userId" gorm:"primaryKey;index"`
+	User      *User      `json:"user,omitempty"`
+	Role      AuthorRole `json:"role" example:"author"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
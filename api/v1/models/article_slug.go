@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// ArticleSlug is a slug an article had before being renamed,
+// kept so that links using it still resolve.
+type ArticleSlug struct {
+	ID        uint      `json:"id"`
+	ArticleID uint      `json:"articleId" gorm:"index"`
+	Slug      string    `json:"slug" gorm:"uniqueIndex"`
+	CreatedAt time.Time `json:"createdAt"`
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// wordsPerMinute is the reading speed used to estimate reading time
+	wordsPerMinute = 200
+	// excerptLength is the maximum number of characters in an excerpt
+	excerptLength = 200
+)
+
+var (
+	markupImage    = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	markupLink     = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	markupTag      = regexp.MustCompile(`<[^>]*>`)
+	markupFence    = regexp.MustCompile("(?m)^\\s*```.*$")
+	markupLineMark = regexp.MustCompile(`(?m)^\s*(#{1,6}|>|[-*+]|\d+\.)\s+`)
+	markupEmphasis = regexp.MustCompile("[*_~`]+")
+)
+
+// PlainText returns body without Markdown and HTML markup.
+func PlainText(body string) string {
+	text := markupImage.ReplaceAllString(body, "$1")
+	text = markupLink.ReplaceAllString(text, "$1")
+	text = markupTag.ReplaceAllString(text, " ")
+	text = markupFence.ReplaceAllString(text, "")
+	text = markupLineMark.ReplaceAllString(text, "")
+	text = markupEmphasis.ReplaceAllString(text, "")
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// Summarize sets the word count, reading time
+// and excerpt of a from its body.
+func (a *Article) Summarize() {
+	text := PlainText(a.Body)
+	a.WordCount = len(strings.Fields(text))
+	a.ReadingTime = (a.WordCount + wordsPerMinute - 1) / wordsPerMinute
+	a.Excerpt = text
+	if utf8.RuneCountInString(text) > excerptLength {
+		excerpt := string([]rune(text)[:excerptLength])
+		// Cut at the last complete word
+		if i := strings.LastIndex(excerpt, " "); i > 0 {
+			excerpt = excerpt[:i]
+		}
+		a.Excerpt = strings.TrimRight(excerpt, " ,.;:") + "…"
+	}
+}
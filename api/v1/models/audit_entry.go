@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+// AuditEntry records a privileged action, entries are never
+// updated nor deleted.
+type AuditEntry struct {
+	ID         uint        `json:"id"`
+	ActorID    uint        `json:"actorId" gorm:"index"`
+	Action     AuditAction `json:"action" example:"category.delete"`
+	TargetType string      `json:"targetType" example:"category" gorm:"index:idx_audit_target"`
+	TargetID   uint        `json:"targetId" gorm:"index:idx_audit_target"`
+	// Before and After are JSON snapshots of the target,
+	// they're null when it didn't exist
+	Before    Snapshot  `json:"before" swaggertype:"object"`
+	After     Snapshot  `json:"after" swaggertype:"object"`
+	RequestID string    `json:"requestId"`
+	CreatedAt time.Time `json:"createdAt" gorm:"index"`
+}
+
+// Snapshot is a JSON document stored as text,
+// it's marshalled as the document itself.
+type Snapshot string
+
+func (s Snapshot) MarshalJSON() ([]byte, error) {
+	if s == "" {
+		return []byte("null"), nil
+	}
+	return []byte(s), nil
+}
+
+func (s *Snapshot) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		*s = ""
+		return nil
+	}
+	*s = Snapshot(b)
+	return nil
+}
+
+type AuditAction string
+
+const (
+	AuditUserRoleUpdate      AuditAction = "user.role.update"
+	AuditRoleSave            AuditAction = "role.save"
+	AuditRoleRequestApprove  AuditAction = "role_request.approve"
+	AuditRoleRequestReject   AuditAction = "role_request.reject"
+	AuditCategoryCreate      AuditAction = "category.create"
+	AuditCategoryUpdate      AuditAction = "category.update"
+	AuditCategoryDelete      AuditAction = "category.delete"
+	AuditArticleUpdateOthers AuditAction = "article.update.others"
+	AuditArticleDeleteOthers AuditAction = "article.delete.others"
+	AuditArticlesBulk        AuditAction = "articles.bulk"
+	AuditAPIKeyRevokeOthers  AuditAction = "api_key.revoke.others"
+)
@@ -1,7 +1,27 @@
 package models
 
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
 type Category struct {
-	ID       uint   `json:"id,omitempty"`
-	Name     string `json:"name"`
-	ImageURL string `json:"imageUrl"`
+	ID uint `json:"id,omitempty"`
+	// ParentID is the ID of the category this one is a subcategory of,
+	// it is nil for top level categories
+	ParentID    *uint     `json:"parentId"`
+	Parent      *Category `json:"parent,omitempty"`
+	Name        string    `json:"name"`
+	Slug        string    `json:"slug" gorm:"uniqueIndex"`
+	Description string    `json:"description"`
+	ImageURL    string    `json:"imageUrl"`
+	// SortOrder sorts categories with the same parent, lowest first
+	SortOrder int       `json:"sortOrder"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	// Version is incremented every time the category is updated
+	Version   uint           `json:"version" gorm:"not null;default:1"`
+	DeletedAt gorm.DeletedAt `json:"deletedAt" gorm:"index" swaggertype:"string"`
+	// DeletedBy is the ID of the user that moved the category to trash
+	DeletedBy *uint `json:"deletedBy"`
 }
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// Notification is a message for a user about something
+// that happened to their account or content.
+type Notification struct {
+	ID        uint       `json:"id"`
+	UserID    uint       `json:"userId"`
+	Message   string     `json:"message"`
+	ReadAt    *time.Time `json:"readAt"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// RefreshToken is a refresh token issued by this API to a client.
+//
+// Only the hash of the token is stored. Tokens issued from the same
+// login share a Family, so that reusing a rotated token can revoke
+// every token derived from it.
+type RefreshToken struct {
+	ID        uint   `json:"id"`
+	UserID    uint   `json:"userId"`
+	Family    string `json:"-" gorm:"index"`
+	TokenHash string `json:"-" gorm:"uniqueIndex"`
+	// ProviderToken is the encrypted refresh token issued by Google
+	ProviderToken string     `json:"-"`
+	ExpiresAt     time.Time  `json:"expiresAt"`
+	RotatedAt     *time.Time `json:"rotatedAt"`
+	RevokedAt     *time.Time `json:"revokedAt"`
+	CreatedAt     time.Time  `json:"createdAt"`
+}
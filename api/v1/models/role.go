@@ -0,0 +1,94 @@
+package models
+
+import "strings"
+
+// Permission is an action a user may be allowed to perform.
+type Permission string
+
+const (
+	PermissionArticlePublish   Permission = "article.publish"
+	PermissionArticleEditAny   Permission = "article.edit.any"
+	PermissionArticleDeleteAny Permission = "article.delete.any"
+	PermissionArticleBulk      Permission = "article.bulk"
+	PermissionCategoryManage   Permission = "category.manage"
+	PermissionUserRoleAssign   Permission = "user.role.assign"
+	PermissionUserManage       Permission = "user.manage"
+	PermissionRoleManage       Permission = "role.manage"
+	PermissionAuditRead        Permission = "audit.read"
+)
+
+// Permissions are all the permissions that can be granted to a role
+var Permissions = []Permission{
+	PermissionArticlePublish,
+	PermissionArticleEditAny,
+	PermissionArticleDeleteAny,
+	PermissionArticleBulk,
+	PermissionCategoryManage,
+	PermissionUserRoleAssign,
+	PermissionUserManage,
+	PermissionRoleManage,
+	PermissionAuditRead,
+}
+
+// RoleDefinition is a named set of permissions,
+// users get the permissions of their Role.
+type RoleDefinition struct {
+	Name        Role   `json:"name" gorm:"primaryKey" example:"Editor"`
+	Description string `json:"description"`
+	// Permissions is a comma separated string of permissions
+	Permissions string `json:"permissions" example:"article.publish,article.edit.any"`
+}
+
+// HasPermission reports whether r grants p.
+func (r *RoleDefinition) HasPermission(p Permission) bool {
+	for _, rp := range strings.Split(r.Permissions, ",") {
+		if Permission(strings.TrimSpace(rp)) == p {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultRoles are the roles available out of the box.
+var DefaultRoles = []RoleDefinition{
+	{
+		Name:        RoleAdministrator,
+		Description: "Manages categories, users and roles",
+		Permissions: joinPermissions(
+			PermissionArticlePublish,
+			PermissionArticleDeleteAny,
+			PermissionArticleBulk,
+			PermissionCategoryManage,
+			PermissionUserRoleAssign,
+			PermissionUserManage,
+			PermissionRoleManage,
+			PermissionAuditRead,
+		),
+	},
+	{
+		Name:        RoleEditor,
+		Description: "Publishes articles and edits articles of any writer",
+		Permissions: joinPermissions(
+			PermissionArticlePublish,
+			PermissionArticleEditAny,
+			PermissionArticleDeleteAny,
+		),
+	},
+	{
+		Name:        RoleWriter,
+		Description: "Publishes articles",
+		Permissions: joinPermissions(PermissionArticlePublish),
+	},
+	{
+		Name:        RoleReader,
+		Description: "Reads articles",
+	},
+}
+
+func joinPermissions(ps ...Permission) string {
+	s := make([]string, len(ps))
+	for i, p := range ps {
+		s[i] = string(p)
+	}
+	return strings.Join(s, ",")
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// RoleRequest is a request from a user to be given a different Role,
+// it's reviewed by users with PermissionUserRoleAssign.
+type RoleRequest struct {
+	ID         uint              `json:"id"`
+	UserID     uint              `json:"userId"`
+	User       User              `json:"user"`
+	Role       Role              `json:"role" example:"Writer"`
+	Motivation string            `json:"motivation"`
+	Status     RoleRequestStatus `json:"status" example:"Pending"`
+	ReviewerID *uint             `json:"reviewerId"`
+	ReviewNote string            `json:"reviewNote"`
+	CreatedAt  time.Time         `json:"createdAt"`
+	UpdatedAt  time.Time         `json:"updatedAt"`
+}
+
+type RoleRequestStatus string
+
+const (
+	RoleRequestPending  RoleRequestStatus = "Pending"
+	RoleRequestApproved RoleRequestStatus = "Approved"
+	RoleRequestRejected RoleRequestStatus = "Rejected"
+)
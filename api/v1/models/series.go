@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// Series is an ordered collection of articles,
+// such as the parts of a multi-part analysis.
+type Series struct {
+	ID uint `json:"id"`
+	// UserID is the ID of the user that created the series
+	UserID      uint   `json:"userId"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	// Articles are sorted by Position
+	Articles  []SeriesArticle `json:"articles"`
+	CreatedAt time.Time       `json:"createdAt"`
+	UpdatedAt time.Time       `json:"updatedAt"`
+}
+
+// SeriesArticle is the membership of an article in a series,
+// an article can only be part of one series.
+type SeriesArticle struct {
+	SeriesID  uint     `json:"seriesId" gorm:"primaryKey"`
+	ArticleID uint     `json:"articleId" gorm:"primaryKey;uniqueIndex"`
+	Article   *Article `json:"article,omitempty"`
+	// Position is the place of the article in the series, starting at 1
+	Position int `json:"position"`
+}
+
+// SeriesNavigation places an article within its series.
+type SeriesNavigation struct {
+	ID       uint         `json:"id"`
+	Title    string       `json:"title"`
+	Position int          `json:"position"`
+	Total    int          `json:"total"`
+	Previous *ArticleLink `json:"previous"`
+	Next     *ArticleLink `json:"next"`
+}
+
+// ArticleLink identifies an article to link to.
+type ArticleLink struct {
+	ID    uint   `json:"id"`
+	Title string `json:"title"`
+	Slug  string `json:"slug"`
+}
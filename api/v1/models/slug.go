@@ -0,0 +1,30 @@
+package models
+
+import "strings"
+
+// accents maps accented letters to the letter used in slugs.
+var accents = strings.NewReplacer(
+	"á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ü", "u", "ñ", "n",
+	"à", "a", "è", "e", "ì", "i", "ò", "o", "ù", "u", "ç", "c",
+)
+
+// Slugify returns s as a URL path segment: lowercase ASCII
+// letters and digits, with anything else between them
+// replaced by a single hyphen.
+func Slugify(s string) string {
+	s = accents.Replace(strings.ToLower(s))
+	var b strings.Builder
+	hyphen := false
+	for _, r := range s {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			hyphen = false
+			continue
+		}
+		hyphen = true
+	}
+	return b.String()
+}
@@ -5,8 +5,9 @@ import (
 )
 
 type User struct {
-	ID                uint      `json:"id,omitempty"`
-	GoogleSub         string    `json:"-"`
+	ID uint `json:"id,omitempty"`
+	// GoogleSub is unique among users that logged in with Google
+	GoogleSub         string    `json:"-" gorm:"uniqueIndex:idx_users_google_sub,where:google_sub <> ''"`
 	Name              string    `json:"name"`
 	Birthdate         time.Time `json:"birthdate" example:"2006-01-02T15:04:05Z"`
 	Gender            string    `json:"gender"`
@@ -14,12 +15,16 @@ type User struct {
 	Description       string    `json:"description"`
 	ShortDescription  string    `json:"shortDescription"`
 	Role              Role      `json:"role" example:"Reader"`
+	UpdatedAt         time.Time `json:"updatedAt"`
+	// Version is incremented every time the user is updated
+	Version uint `json:"version" gorm:"not null;default:1"`
 }
 
 type Role string
 
 const (
 	RoleAdministrator Role = "Administrator"
+	RoleEditor        Role = "Editor"
 	RoleWriter        Role = "Writer"
 	RoleReader        Role = "Reader"
 )
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/JonathanGzzBen/ingenialists/api/v1/models"
+	"gorm.io/gorm"
+)
+
+type APIKeysRepository interface {
+	GetAPIKeysByUser(context.Context, uint) ([]models.APIKey, error)
+	GetAPIKey(context.Context, uint) (*models.APIKey, error)
+	GetAPIKeyByHash(context.Context, string) (*models.APIKey, error)
+	CreateAPIKey(context.Context, *models.APIKey) (*models.APIKey, error)
+	UpdateAPIKey(context.Context, *models.APIKey) (*models.APIKey, error)
+	TouchAPIKey(context.Context, uint, time.Time) error
+}
+
+type APIKeysGormRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeysGormRepository(db *gorm.DB) *APIKeysGormRepository {
+	db.AutoMigrate(&models.APIKey{})
+	return &APIKeysGormRepository{
+		db: db,
+	}
+}
+
+func (r *APIKeysGormRepository) GetAPIKeysByUser(ctx context.Context, uid uint) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	res := r.db.WithContext(ctx).Where("user_id = ?", uid).Find(&keys)
+	if res.Error != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	return keys, nil
+}
+
+func (r *APIKeysGormRepository) GetAPIKey(ctx context.Context, id uint) (*models.APIKey, error) {
+	var key *models.APIKey
+	res := r.db.WithContext(ctx).Find(&key, id)
+	if res.Error == gorm.ErrRecordNotFound || res.RowsAffected != 1 {
+		return nil, ErrNotFound
+	}
+	if res.Error != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	return key, nil
+}
+
+func (r *APIKeysGormRepository) GetAPIKeyByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	var key *models.APIKey
+	res := r.db.WithContext(ctx).Where("key_hash = ?", hash).Find(&key)
+	if res.Error != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	if res.RowsAffected != 1 {
+		return nil, ErrNotFound
+	}
+	return key, nil
+}
+
+func (r *APIKeysGormRepository) CreateAPIKey(ctx context.Context, k *models.APIKey) (*models.APIKey, error) {
+	res := r.db.WithContext(ctx).Create(k)
+	if res.Error != nil {
+		return nil, ErrCouldNotCreate
+	}
+	return k, nil
+}
+
+func (r *APIKeysGormRepository) UpdateAPIKey(ctx context.Context, k *models.APIKey) (*models.APIKey, error) {
+	res := r.db.WithContext(ctx).Save(k)
+	if res.Error != nil {
+		return nil, ErrCouldNotUpdate
+	}
+	return k, nil
+}
+
+// TouchAPIKey sets when the key with id was last used. Only that
+// column is written, so it can't undo a concurrent revocation.
+func (r *APIKeysGormRepository) TouchAPIKey(ctx context.Context, id uint, t time.Time) error {
+	res := r.db.WithContext(ctx).Model(&models.APIKey{}).
+		Where("id = ?", id).
+		UpdateColumn("last_used_at", t)
+	if res.Error != nil {
+		return ErrCouldNotUpdate
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/JonathanGzzBen/ingenialists/api/v1/models"
+	"github.com/JonathanGzzBen/ingenialists/api/v1/repository"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestTouchAPIKeyKeepsRevocation(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	r := repository.NewAPIKeysGormRepository(db)
+	ctx := context.Background()
+	k, err := r.CreateAPIKey(ctx, &models.APIKey{UserID: 1, KeyHash: "hash"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// The key is revoked after a request read it
+	revoked := *k
+	now := time.Now()
+	revoked.RevokedAt = &now
+	if _, err := r.UpdateAPIKey(ctx, &revoked); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if err := r.TouchAPIKey(ctx, k.ID, now); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	stored, err := r.GetAPIKeyByHash(ctx, "hash")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if stored.RevokedAt == nil {
+		t.Fatalf("Expected key to stay revoked")
+	}
+	if stored.LastUsedAt == nil {
+		t.Fatalf("Expected LastUsedAt to be set")
+	}
+}
@@ -1,17 +1,54 @@
 package repository
 
 import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
 	"github.com/JonathanGzzBen/ingenialists/api/v1/models"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
 type ArticlesRepository interface {
-	GetAllArticles() ([]models.Article, error)
-	GetArticle(uint) (*models.Article, error)
-	CreateArticle(*models.Article) (*models.Article, error)
-	UpdateArticle(*models.Article) (*models.Article, error)
-	DeleteArticle(uint) error
+	GetAllArticles(context.Context, ArticlesQuery) ([]models.Article, error)
+	GetArticle(context.Context, uint, Projection) (*models.Article, error)
+	GetArticleBySlug(context.Context, string, Projection) (*models.Article, error)
+	CreateArticle(context.Context, *models.Article) (*models.Article, error)
+	UpdateArticle(context.Context, *models.Article) (*models.Article, error)
+	DeleteArticle(context.Context, uint, uint) error
+	GetDeletedArticles(context.Context) ([]models.Article, error)
+	GetDeletedArticle(context.Context, uint) (*models.Article, error)
+	RestoreArticle(context.Context, uint) (*models.Article, error)
+	PurgeArticles(context.Context, time.Time) (int64, error)
+	PublishDueArticles(context.Context, time.Time) (int64, error)
+	BulkUpdateArticles(context.Context, []uint, BulkOperation) ([]BulkResult, error)
+	SaveArticleAuthor(context.Context, *models.ArticleAuthor) (*models.ArticleAuthor, error)
+	RemoveArticleAuthor(context.Context, uint, uint) error
+}
+
+// ArticlesQuery filters the articles returned by GetAllArticles,
+// zero valued fields don't filter.
+type ArticlesQuery struct {
+	// CategoryIDs restricts articles to those in any of these categories
+	CategoryIDs []uint
+	// Published restricts articles to those already published
+	Published bool
+	// Scheduled restricts articles to those waiting to be published
+	Scheduled bool
+	// UserID restricts articles to those created by user with this ID
+	UserID uint
+	// AuthorID restricts articles to those crediting user
+	// with this ID as one of their authors
+	AuthorID uint
+	// Archived restricts articles to archived ones,
+	// archived articles are left out otherwise
+	Archived bool
+	// Projection selects the fields and associations loaded,
+	// body is only loaded if listed in its fields
+	Projection
 }
 
 type ArticlesGormRepository struct {
@@ -19,24 +56,85 @@ type ArticlesGormRepository struct {
 }
 
 func NewArticlesGormRepository(db *gorm.DB) *ArticlesGormRepository {
-	db.AutoMigrate(&models.Article{})
+	// Purging articles removes them from series too
+	db.AutoMigrate(&models.Article{}, &models.ArticleSlug{}, &models.ArticleAuthor{}, &models.SeriesArticle{})
+	// Articles saved before summaries existed get theirs computed
+	var unsummarized []models.Article
+	db.Unscoped().Select("id", "body").Where("word_count = 0 AND body <> ''").Find(&unsummarized)
+	for _, a := range unsummarized {
+		a.Summarize()
+		db.Unscoped().Model(&a).Select("word_count", "reading_time", "excerpt").Updates(&a)
+	}
+	// Articles created before co-authors existed are credited to their creator
+	db.Exec("INSERT INTO article_authors (article_id, user_id, role, created_at) "+
+		"SELECT id, user_id, ?, created_at FROM articles "+
+		"WHERE id NOT IN (SELECT article_id FROM article_authors)", models.AuthorRoleAuthor)
+	// Articles created before scheduling existed were published when created
+	db.Unscoped().Model(&models.Article{}).
+		Where("published_at IS NULL AND publish_at IS NULL").
+		Update("published_at", gorm.Expr("created_at"))
+	// Articles created before slugs existed get one from their title
+	var unslugged []models.Article
+	db.Unscoped().Where("slug IS NULL OR slug = ''").Find(&unslugged)
+	for _, a := range unslugged {
+		db.Unscoped().Model(&a).Update("slug", uniqueArticleSlug(db, a.Title, a.ID))
+	}
 	return &ArticlesGormRepository{
 		db: db,
 	}
 }
 
-func (r ArticlesGormRepository) GetAllArticles() ([]models.Article, error) {
+func (r ArticlesGormRepository) GetAllArticles(ctx context.Context, q ArticlesQuery) ([]models.Article, error) {
 	var articles []models.Article
-	res := r.db.Preload(clause.Associations).Find(&articles)
+	tx := q.selectColumns(r.db.WithContext(ctx), &models.Article{}, []string{"id", "user_id", "category_id", "updated_at"}, "body")
+	tx = expandArticles(tx, q.Projection)
+	if len(q.CategoryIDs) > 0 {
+		tx = tx.Where("category_id IN ?", q.CategoryIDs)
+	}
+	if q.Published {
+		tx = tx.Where("published_at IS NOT NULL")
+	}
+	if q.Scheduled {
+		tx = tx.Where("published_at IS NULL")
+	}
+	if q.Archived {
+		tx = tx.Where("archived_at IS NOT NULL")
+	} else {
+		tx = tx.Where("archived_at IS NULL")
+	}
+	if q.UserID != 0 {
+		tx = tx.Where("user_id = ?", q.UserID)
+	}
+	if q.AuthorID != 0 {
+		tx = tx.Where("id IN (?)", r.db.WithContext(ctx).Model(&models.ArticleAuthor{}).Select("article_id").Where("user_id = ?", q.AuthorID))
+	}
+	res := tx.Find(&articles)
 	if res.Error != nil {
 		return nil, ErrCouldNotRetrieve
 	}
 	return articles, nil
 }
 
-func (r ArticlesGormRepository) GetArticle(id uint) (*models.Article, error) {
+// expandArticles makes tx load the associations of articles p expands.
+func expandArticles(tx *gorm.DB, p Projection) *gorm.DB {
+	if p.Expands("user") {
+		tx = tx.Preload("User")
+	}
+	if p.Expands("category") {
+		tx = tx.Preload("Category")
+	}
+	if p.Expands("authors") {
+		tx = tx.Preload("Authors.User")
+	}
+	return tx
+}
+
+// GetArticle returns article with matching id, loading
+// what p selects. Every field is loaded if p has no fields.
+func (r ArticlesGormRepository) GetArticle(ctx context.Context, id uint, p Projection) (*models.Article, error) {
 	var article *models.Article
-	res := r.db.Preload(clause.Associations).Find(&article, id)
+	tx := p.selectColumns(r.db.WithContext(ctx), &models.Article{}, articleColumns)
+	res := expandArticles(tx, p).Find(&article, id)
 	if res.Error == gorm.ErrRecordNotFound || res.RowsAffected != 1 {
 		return nil, ErrNotFound
 	}
@@ -46,38 +144,396 @@ func (r ArticlesGormRepository) GetArticle(id uint) (*models.Article, error) {
 	return article, nil
 }
 
-func (r ArticlesGormRepository) CreateArticle(a *models.Article) (*models.Article, error) {
-	res := r.db.Create(&a)
+// GetArticleBySlug returns article with matching slug,
+// or the article that had it before being renamed,
+// loading what p selects.
+func (r ArticlesGormRepository) GetArticleBySlug(ctx context.Context, slug string, p Projection) (*models.Article, error) {
+	var article *models.Article
+	tx := p.selectColumns(r.db.WithContext(ctx), &models.Article{}, articleColumns)
+	res := expandArticles(tx, p).Where("slug = ?", slug).Find(&article)
+	if res.Error != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	if res.RowsAffected == 1 {
+		return article, nil
+	}
+	var old models.ArticleSlug
+	res = r.db.WithContext(ctx).Where("slug = ?", slug).Find(&old)
 	if res.Error != nil {
-		return nil, ErrCouldNotCreate
+		return nil, ErrCouldNotRetrieve
+	}
+	if res.RowsAffected != 1 {
+		return nil, ErrNotFound
+	}
+	return r.GetArticle(ctx, old.ArticleID, p)
+}
+
+// maxSlugAttempts is how many slugs CreateArticle tries when
+// other articles take the ones it picks before it's saved
+const maxSlugAttempts = 5
+
+// uniqueArticleSlug returns a slug for an article titled title
+// with matching id, suffixed with a number if needed so that no other
+// article, including those in trash or renamed, has used it.
+func uniqueArticleSlug(db *gorm.DB, title string, id uint) string {
+	base := models.Slugify(title)
+	if base == "" {
+		base = "article"
 	}
-	a, err := r.GetArticle(a.ID)
+	slug := base
+	for n := 2; articleSlugTaken(db, slug, id); n++ {
+		slug = fmt.Sprintf("%s-%d", base, n)
+	}
+	return slug
+}
+
+// articleSlugTaken reports whether an article other than the one
+// with matching id, including those in trash or renamed, has used slug.
+func articleSlugTaken(db *gorm.DB, slug string, id uint) bool {
+	var articles, old int64
+	db.Unscoped().Model(&models.Article{}).Where("slug = ? AND id <> ?", slug, id).Count(&articles)
+	db.Model(&models.ArticleSlug{}).Where("slug = ? AND article_id <> ?", slug, id).Count(&old)
+	return articles > 0 || old > 0
+}
+
+// publishIfDue publishes a if it isn't scheduled for later.
+func publishIfDue(a *models.Article) {
+	now := time.Now()
+	if a.PublishedAt == nil && (a.PublishAt == nil || !a.PublishAt.After(now)) {
+		a.PublishedAt = &now
+	}
+}
+
+// CreateArticle registers a, publishing it right away
+// unless its PublishAt is in the future, and computes its summary.
+// The user creating a is credited as one of its authors.
+func (r ArticlesGormRepository) CreateArticle(ctx context.Context, a *models.Article) (*models.Article, error) {
+	credited := false
+	for _, author := range a.Authors {
+		credited = credited || author.UserID == a.UserID
+	}
+	if !credited {
+		a.Authors = append(a.Authors, models.ArticleAuthor{UserID: a.UserID, Role: models.AuthorRoleAuthor})
+	}
+	publishIfDue(a)
+	a.Summarize()
+	a.Version = 1
+	generated := a.Slug == ""
+	for attempt := 1; ; attempt++ {
+		if generated {
+			a.Slug = uniqueArticleSlug(r.db.WithContext(ctx), a.Title, 0)
+		}
+		err := r.db.WithContext(ctx).Create(&a).Error
+		if err == nil {
+			break
+		}
+		// Another article may have taken the slug after it was picked
+		if !generated || attempt == maxSlugAttempts || !articleSlugTaken(r.db.WithContext(ctx), a.Slug, 0) {
+			return nil, ErrCouldNotCreate
+		}
+	}
+	a, err := r.GetArticle(ctx, a.ID, WholeArticle)
 	if err != nil {
 		return nil, err
 	}
 	return a, nil
 }
 
-func (r ArticlesGormRepository) UpdateArticle(a *models.Article) (*models.Article, error) {
-	res := r.db.Save(&a)
-	if res.Error != nil {
+// UpdateArticle saves a, giving it a new slug if its title changed.
+// The previous slug is kept in the slug history of a.
+// Scheduled articles whose PublishAt has passed are published
+// and the summary of a is computed again.
+func (r ArticlesGormRepository) UpdateArticle(ctx context.Context, a *models.Article) (*models.Article, error) {
+	publishIfDue(a)
+	a.Summarize()
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var current models.Article
+		if err := tx.Select("title", "slug").Find(&current, a.ID).Error; err != nil {
+			return err
+		}
+		if current.Title != a.Title || a.Slug == "" {
+			a.Slug = uniqueArticleSlug(tx, a.Title, a.ID)
+		}
+		if current.Slug != "" && current.Slug != a.Slug {
+			if err := tx.Create(&models.ArticleSlug{ArticleID: a.ID, Slug: current.Slug}).Error; err != nil {
+				return err
+			}
+		}
+		// An article renamed back to a previous title gets its slug back
+		if err := tx.Where("slug = ?", a.Slug).Delete(&models.ArticleSlug{}).Error; err != nil {
+			return err
+		}
+		// Category and User are loaded along with a, saving them would
+		// set CategoryID and UserID back to the ones they were loaded with
+		return saveVersioned(tx, a, &a.Version, "Authors", "Category", "User")
+	})
+	if err == ErrVersionConflict {
+		return nil, err
+	}
+	if err != nil {
 		return nil, ErrCouldNotUpdate
 	}
-	a, err := r.GetArticle(a.ID)
+	a, err = r.GetArticle(ctx, a.ID, WholeArticle)
 	if err != nil {
 		return nil, err
 	}
 	return a, nil
 }
 
-func (r ArticlesGormRepository) DeleteArticle(id uint) error {
-	a, err := r.GetArticle(id)
+// DeleteArticle moves article with matching id to trash,
+// deletedBy is the ID of the user deleting it.
+func (r ArticlesGormRepository) DeleteArticle(ctx context.Context, id uint, deletedBy uint) error {
+	a, err := r.GetArticle(ctx, id, WholeArticle)
 	if err != nil {
 		return err
 	}
-	res := r.db.Delete(&a)
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(a).Update("deleted_by", deletedBy).Error; err != nil {
+			return err
+		}
+		return tx.Delete(a).Error
+	})
+	if err != nil {
+		return ErrCouldNotDelete
+	}
+	return nil
+}
+
+// GetDeletedArticles returns articles in trash.
+func (r ArticlesGormRepository) GetDeletedArticles(ctx context.Context) ([]models.Article, error) {
+	var articles []models.Article
+	res := r.db.WithContext(ctx).Unscoped().Preload(clause.Associations).Preload("Authors.User").Where("deleted_at IS NOT NULL").Find(&articles)
+	if res.Error != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	return articles, nil
+}
+
+func (r ArticlesGormRepository) GetDeletedArticle(ctx context.Context, id uint) (*models.Article, error) {
+	var article *models.Article
+	res := r.db.WithContext(ctx).Unscoped().Preload(clause.Associations).Preload("Authors.User").Where("deleted_at IS NOT NULL").Find(&article, id)
+	if res.Error == gorm.ErrRecordNotFound || res.RowsAffected != 1 {
+		return nil, ErrNotFound
+	}
+	if res.Error != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	return article, nil
+}
+
+// RestoreArticle takes article with matching id out of trash.
+func (r ArticlesGormRepository) RestoreArticle(ctx context.Context, id uint) (*models.Article, error) {
+	res := r.db.WithContext(ctx).Unscoped().Model(&models.Article{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Updates(map[string]interface{}{"deleted_at": nil, "deleted_by": nil})
+	if res.Error != nil {
+		return nil, ErrCouldNotUpdate
+	}
+	if res.RowsAffected != 1 {
+		return nil, ErrNotFound
+	}
+	return r.GetArticle(ctx, id, WholeArticle)
+}
+
+// PurgeArticles permanently deletes articles
+// that were moved to trash before t.
+func (r ArticlesGormRepository) PurgeArticles(ctx context.Context, t time.Time) (int64, error) {
+	var n int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", t).Delete(&models.Article{})
+		if res.Error != nil {
+			return res.Error
+		}
+		n = res.RowsAffected
+		remaining := tx.Unscoped().Model(&models.Article{}).Select("id")
+		for _, m := range []interface{}{&models.ArticleSlug{}, &models.ArticleAuthor{}, &models.SeriesArticle{}} {
+			if err := tx.Where("article_id NOT IN (?)", remaining).Delete(m).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, ErrCouldNotDelete
+	}
+	return n, nil
+}
+
+// PublishDueArticles publishes scheduled articles
+// whose PublishAt is not after t.
+func (r ArticlesGormRepository) PublishDueArticles(ctx context.Context, t time.Time) (int64, error) {
+	res := r.db.WithContext(ctx).Model(&models.Article{}).
+		Where("published_at IS NULL AND publish_at <= ?", t).
+		Updates(map[string]interface{}{
+			"published_at": gorm.Expr("publish_at"),
+			"version":      gorm.Expr("version + 1"),
+		})
+	if res.Error != nil {
+		return 0, ErrCouldNotUpdate
+	}
+	return res.RowsAffected, nil
+}
+
+// SaveArticleAuthor credits an author of an article,
+// replacing the role of the author if already credited.
+func (r ArticlesGormRepository) SaveArticleAuthor(ctx context.Context, aa *models.ArticleAuthor) (*models.ArticleAuthor, error) {
+	res := r.db.WithContext(ctx).Omit(clause.Associations).Save(aa)
+	if res.Error != nil {
+		return nil, ErrCouldNotUpdate
+	}
+	res = r.db.WithContext(ctx).Preload(clause.Associations).Where("article_id = ? AND user_id = ?", aa.ArticleID, aa.UserID).Find(aa)
+	if res.Error != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	return aa, nil
+}
+
+// RemoveArticleAuthor stops crediting user with userID
+// as an author of article with articleID.
+func (r ArticlesGormRepository) RemoveArticleAuthor(ctx context.Context, articleID uint, userID uint) error {
+	res := r.db.WithContext(ctx).Where("article_id = ? AND user_id = ?", articleID, userID).Delete(&models.ArticleAuthor{})
 	if res.Error != nil {
 		return ErrCouldNotDelete
 	}
+	if res.RowsAffected != 1 {
+		return ErrNotFound
+	}
 	return nil
 }
+
+// BulkOperation is a change made to many articles at once,
+// zero valued fields change nothing.
+type BulkOperation struct {
+	// CategoryID moves articles to category with this ID
+	CategoryID uint
+	// AddTags and RemoveTags are added to and removed from
+	// the tags of articles
+	AddTags    []string
+	RemoveTags []string
+	// Archive archives articles that aren't archived yet
+	Archive bool
+	// Delete moves articles to trash, DeletedBy is
+	// the ID of the user deleting them
+	Delete    bool
+	DeletedBy uint
+	// DryRun reports what would change without changing anything
+	DryRun bool
+}
+
+// BulkResult is what a bulk operation did to an article.
+type BulkResult struct {
+	ArticleID uint
+	// Found is false if there's no article with ArticleID
+	Found bool
+	// Changed is whether the article was changed,
+	// or would be in a dry run
+	Changed bool
+}
+
+// bulkResults returns what op does to the articles with ids, which
+// are in found, and the articles it changes, applying op to them.
+// Any result not found means nothing must be changed.
+func bulkResults(ids []uint, found map[uint]*models.Article, op BulkOperation) ([]BulkResult, []*models.Article, bool) {
+	results := make([]BulkResult, len(ids))
+	var changed []*models.Article
+	allFound := true
+	for i, id := range ids {
+		a, ok := found[id]
+		results[i] = BulkResult{ArticleID: id, Found: ok}
+		if !ok {
+			allFound = false
+			continue
+		}
+		if !op.Delete {
+			before := *a
+			op.Apply(a)
+			if reflect.DeepEqual(before, *a) {
+				continue
+			}
+		}
+		results[i].Changed = true
+		changed = append(changed, a)
+	}
+	return results, changed, allFound
+}
+
+// Apply makes the changes of op, other than deleting, to a.
+func (op BulkOperation) Apply(a *models.Article) {
+	if op.CategoryID != 0 {
+		a.CategoryID = op.CategoryID
+	}
+	if len(op.AddTags) > 0 || len(op.RemoveTags) > 0 {
+		remove := map[string]bool{}
+		for _, t := range op.RemoveTags {
+			remove[t] = true
+		}
+		var tags []string
+		seen := map[string]bool{}
+		for _, t := range append(strings.Split(a.Tags, ","), op.AddTags...) {
+			t = strings.TrimSpace(t)
+			if t != "" && !seen[t] && !remove[t] {
+				tags = append(tags, t)
+			}
+			seen[t] = true
+		}
+		a.Tags = strings.Join(tags, ",")
+	}
+	if op.Archive && a.ArchivedAt == nil {
+		now := time.Now()
+		a.ArchivedAt = &now
+	}
+}
+
+// BulkUpdateArticles makes the changes of op to articles with
+// matching ids in a single transaction, so either every article
+// is changed or none is, and returns the result of each one.
+// If any of them is not found none is changed and ErrNotFound
+// is returned along with the results.
+func (r ArticlesGormRepository) BulkUpdateArticles(ctx context.Context, ids []uint, op BulkOperation) ([]BulkResult, error) {
+	var results []BulkResult
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var articles []models.Article
+		if err := tx.Where("id IN ?", ids).Find(&articles).Error; err != nil {
+			return err
+		}
+		found := make(map[uint]*models.Article, len(articles))
+		for i := range articles {
+			found[articles[i].ID] = &articles[i]
+		}
+		var changed []*models.Article
+		var allFound bool
+		results, changed, allFound = bulkResults(ids, found, op)
+		if !allFound {
+			return ErrNotFound
+		}
+		if op.DryRun || len(changed) == 0 {
+			return nil
+		}
+		if op.Delete {
+			deleted := make([]uint, len(changed))
+			for i, a := range changed {
+				deleted[i] = a.ID
+			}
+			if err := tx.Model(&models.Article{}).Where("id IN ?", deleted).Update("deleted_by", op.DeletedBy).Error; err != nil {
+				return err
+			}
+			return tx.Delete(&models.Article{}, deleted).Error
+		}
+		for _, a := range changed {
+			if err := saveVersioned(tx, a, &a.Version); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err == ErrNotFound {
+		return results, err
+	}
+	if err == ErrVersionConflict {
+		return nil, err
+	}
+	if err != nil {
+		return nil, ErrCouldNotUpdate
+	}
+	return results, nil
+}
@@ -0,0 +1,514 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/JonathanGzzBen/ingenialists/api/v1/models"
+	"gorm.io/gorm"
+)
+
+// ArticlesMemoryRepository is an ArticlesRepository
+// that keeps articles in a MemoryStore.
+type ArticlesMemoryRepository struct {
+	s *MemoryStore
+}
+
+func NewArticlesMemoryRepository(s *MemoryStore) *ArticlesMemoryRepository {
+	return &ArticlesMemoryRepository{
+		s: s,
+	}
+}
+
+func (r *ArticlesMemoryRepository) GetAllArticles(ctx context.Context, q ArticlesQuery) ([]models.Article, error) {
+	if ctx.Err() != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	categories := map[uint]bool{}
+	for _, id := range q.CategoryIDs {
+		categories[id] = true
+	}
+	articles := []models.Article{}
+	for _, id := range r.s.articleIDs(false) {
+		a := r.s.articles[id]
+		switch {
+		case len(categories) > 0 && !categories[a.CategoryID],
+			q.Published && a.PublishedAt == nil,
+			q.Scheduled && a.PublishedAt != nil,
+			q.Archived != (a.ArchivedAt != nil),
+			q.UserID != 0 && a.UserID != q.UserID,
+			q.AuthorID != 0 && r.s.author(a.ID, q.AuthorID) == nil:
+			continue
+		}
+		if q.Expands("user") {
+			a.User = r.s.user(a.UserID)
+		}
+		if q.Expands("category") {
+			a.Category = r.s.category(a.CategoryID)
+		}
+		if q.Expands("authors") {
+			a.Authors = r.s.articleAuthors(a.ID)
+		}
+		q.clearColumns(&a, []string{"id", "user_id", "category_id", "updated_at"}, "body")
+		articles = append(articles, a)
+	}
+	return articles, nil
+}
+
+// GetArticle returns article with matching id, loading
+// what p selects. Every field is loaded if p has no fields.
+func (r *ArticlesMemoryRepository) GetArticle(ctx context.Context, id uint, p Projection) (*models.Article, error) {
+	if ctx.Err() != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	return r.s.projectedArticle(id, p)
+}
+
+// GetArticleBySlug returns article with matching slug,
+// or the article that had it before being renamed,
+// loading what p selects.
+func (r *ArticlesMemoryRepository) GetArticleBySlug(ctx context.Context, slug string, p Projection) (*models.Article, error) {
+	if ctx.Err() != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	for _, id := range r.s.articleIDs(false) {
+		if r.s.articles[id].Slug == slug {
+			return r.s.projectedArticle(id, p)
+		}
+	}
+	for _, old := range r.s.articleSlugs {
+		if old.Slug == slug {
+			return r.s.projectedArticle(old.ArticleID, p)
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// CreateArticle registers a, publishing it right away
+// unless its PublishAt is in the future, and computes its summary.
+// The user creating a is credited as one of its authors.
+func (r *ArticlesMemoryRepository) CreateArticle(ctx context.Context, a *models.Article) (*models.Article, error) {
+	if ctx.Err() != nil {
+		return nil, ErrCouldNotCreate
+	}
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	credited := false
+	for _, author := range a.Authors {
+		credited = credited || author.UserID == a.UserID
+	}
+	if !credited {
+		a.Authors = append(a.Authors, models.ArticleAuthor{UserID: a.UserID, Role: models.AuthorRoleAuthor})
+	}
+	if a.Slug == "" {
+		a.Slug = r.s.uniqueArticleSlug(a.Title, 0)
+	}
+	if _, ok := r.s.articles[a.ID]; ok || r.s.articleSlugTaken(a) {
+		return nil, ErrCouldNotCreate
+	}
+	publishIfDue(a)
+	a.Summarize()
+	a.ID = r.s.nextID("articles", a.ID)
+	a.Version = 1
+	now := time.Now()
+	if a.CreatedAt.IsZero() {
+		a.CreatedAt = now
+	}
+	if a.UpdatedAt.IsZero() {
+		a.UpdatedAt = now
+	}
+	for i := range a.Authors {
+		a.Authors[i].ArticleID = a.ID
+		if r.s.author(a.ID, a.Authors[i].UserID) != nil {
+			continue
+		}
+		if a.Authors[i].CreatedAt.IsZero() {
+			a.Authors[i].CreatedAt = now
+		}
+		aa := a.Authors[i]
+		aa.User = nil
+		r.s.authors = append(r.s.authors, aa)
+	}
+	r.s.saveArticle(*a)
+	return r.s.article(a.ID, false)
+}
+
+// UpdateArticle saves a, giving it a new slug if its title changed.
+// The previous slug is kept in the slug history of a.
+// Scheduled articles whose PublishAt has passed are published
+// and the summary of a is computed again.
+func (r *ArticlesMemoryRepository) UpdateArticle(ctx context.Context, a *models.Article) (*models.Article, error) {
+	if ctx.Err() != nil {
+		return nil, ErrCouldNotUpdate
+	}
+	publishIfDue(a)
+	a.Summarize()
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	current, ok := r.s.articles[a.ID]
+	if !ok || current.DeletedAt.Valid {
+		current = models.Article{}
+	}
+	if current.Title != a.Title || a.Slug == "" {
+		a.Slug = r.s.uniqueArticleSlug(a.Title, a.ID)
+	}
+	if current.ID == 0 || current.Version != a.Version {
+		return nil, ErrVersionConflict
+	}
+	if r.s.articleSlugTaken(a) {
+		return nil, ErrCouldNotUpdate
+	}
+	slugs := []models.ArticleSlug{}
+	for _, old := range r.s.articleSlugs {
+		if current.Slug != "" && current.Slug != a.Slug && old.Slug == current.Slug {
+			return nil, ErrCouldNotUpdate
+		}
+		// An article renamed back to a previous title gets its slug back
+		if old.Slug != a.Slug {
+			slugs = append(slugs, old)
+		}
+	}
+	if current.Slug != "" && current.Slug != a.Slug {
+		slugs = append(slugs, models.ArticleSlug{
+			ID:        r.s.nextID("article_slugs", 0),
+			ArticleID: a.ID,
+			Slug:      current.Slug,
+			CreatedAt: time.Now(),
+		})
+	}
+	r.s.articleSlugs = slugs
+	a.Version++
+	a.UpdatedAt = time.Now()
+	r.s.saveArticle(*a)
+	return r.s.article(a.ID, false)
+}
+
+// DeleteArticle moves article with matching id to trash,
+// deletedBy is the ID of the user deleting it.
+func (r *ArticlesMemoryRepository) DeleteArticle(ctx context.Context, id uint, deletedBy uint) error {
+	if ctx.Err() != nil {
+		return ErrCouldNotDelete
+	}
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	a, err := r.s.article(id, false)
+	if err != nil {
+		return err
+	}
+	a.DeletedBy = &deletedBy
+	a.DeletedAt = deletedNow()
+	a.UpdatedAt = time.Now()
+	r.s.saveArticle(*a)
+	return nil
+}
+
+// GetDeletedArticles returns articles in trash.
+func (r *ArticlesMemoryRepository) GetDeletedArticles(ctx context.Context) ([]models.Article, error) {
+	if ctx.Err() != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	articles := []models.Article{}
+	for _, id := range r.s.articleIDs(true) {
+		a, err := r.s.article(id, true)
+		if err != nil {
+			return nil, err
+		}
+		articles = append(articles, *a)
+	}
+	return articles, nil
+}
+
+func (r *ArticlesMemoryRepository) GetDeletedArticle(ctx context.Context, id uint) (*models.Article, error) {
+	if ctx.Err() != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	return r.s.article(id, true)
+}
+
+// RestoreArticle takes article with matching id out of trash.
+func (r *ArticlesMemoryRepository) RestoreArticle(ctx context.Context, id uint) (*models.Article, error) {
+	if ctx.Err() != nil {
+		return nil, ErrCouldNotUpdate
+	}
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	a, err := r.s.article(id, true)
+	if err != nil {
+		return nil, err
+	}
+	a.DeletedAt = gorm.DeletedAt{}
+	a.DeletedBy = nil
+	a.UpdatedAt = time.Now()
+	r.s.saveArticle(*a)
+	return r.s.article(id, false)
+}
+
+// PurgeArticles permanently deletes articles
+// that were moved to trash before t.
+func (r *ArticlesMemoryRepository) PurgeArticles(ctx context.Context, t time.Time) (int64, error) {
+	if ctx.Err() != nil {
+		return 0, ErrCouldNotDelete
+	}
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var n int64
+	for _, id := range r.s.articleIDs(true) {
+		if r.s.articles[id].DeletedAt.Time.Before(t) {
+			delete(r.s.articles, id)
+			n++
+		}
+	}
+	slugs := []models.ArticleSlug{}
+	for _, old := range r.s.articleSlugs {
+		if _, ok := r.s.articles[old.ArticleID]; ok {
+			slugs = append(slugs, old)
+		}
+	}
+	r.s.articleSlugs = slugs
+	authors := []models.ArticleAuthor{}
+	for _, aa := range r.s.authors {
+		if _, ok := r.s.articles[aa.ArticleID]; ok {
+			authors = append(authors, aa)
+		}
+	}
+	r.s.authors = authors
+	return n, nil
+}
+
+// PublishDueArticles publishes scheduled articles
+// whose PublishAt is not after t.
+func (r *ArticlesMemoryRepository) PublishDueArticles(ctx context.Context, t time.Time) (int64, error) {
+	if ctx.Err() != nil {
+		return 0, ErrCouldNotUpdate
+	}
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var n int64
+	for _, id := range r.s.articleIDs(false) {
+		a := r.s.articles[id]
+		if a.PublishedAt != nil || a.PublishAt == nil || a.PublishAt.After(t) {
+			continue
+		}
+		publishedAt := *a.PublishAt
+		a.PublishedAt = &publishedAt
+		a.Version++
+		a.UpdatedAt = time.Now()
+		r.s.articles[id] = a
+		n++
+	}
+	return n, nil
+}
+
+// SaveArticleAuthor credits an author of an article,
+// replacing the role of the author if already credited.
+func (r *ArticlesMemoryRepository) SaveArticleAuthor(ctx context.Context, aa *models.ArticleAuthor) (*models.ArticleAuthor, error) {
+	if ctx.Err() != nil {
+		return nil, ErrCouldNotUpdate
+	}
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	saved := *aa
+	saved.User = nil
+	if current := r.s.author(aa.ArticleID, aa.UserID); current != nil {
+		if saved.CreatedAt.IsZero() {
+			saved.CreatedAt = current.CreatedAt
+		}
+		*current = saved
+	} else {
+		if saved.CreatedAt.IsZero() {
+			saved.CreatedAt = time.Now()
+		}
+		r.s.authors = append(r.s.authors, saved)
+	}
+	*aa = saved
+	aa.User = r.s.user(aa.UserID)
+	return aa, nil
+}
+
+// RemoveArticleAuthor stops crediting user with userID
+// as an author of article with articleID.
+func (r *ArticlesMemoryRepository) RemoveArticleAuthor(ctx context.Context, articleID uint, userID uint) error {
+	if ctx.Err() != nil {
+		return ErrCouldNotDelete
+	}
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for i, aa := range r.s.authors {
+		if aa.ArticleID == articleID && aa.UserID == userID {
+			r.s.authors = append(r.s.authors[:i:i], r.s.authors[i+1:]...)
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+// BulkUpdateArticles makes the changes of op to articles with
+// matching ids at once, so either every article is changed
+// or none is, and returns the result of each one.
+func (r *ArticlesMemoryRepository) BulkUpdateArticles(ctx context.Context, ids []uint, op BulkOperation) ([]BulkResult, error) {
+	if ctx.Err() != nil {
+		return nil, ErrCouldNotUpdate
+	}
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	found := map[uint]*models.Article{}
+	for _, id := range ids {
+		if a, ok := r.s.articles[id]; ok && !a.DeletedAt.Valid {
+			found[id] = &a
+		}
+	}
+	results, changed, allFound := bulkResults(ids, found, op)
+	if !allFound {
+		return results, ErrNotFound
+	}
+	if op.DryRun {
+		return results, nil
+	}
+	for _, a := range changed {
+		if op.Delete {
+			deletedBy := op.DeletedBy
+			a.DeletedBy = &deletedBy
+			a.DeletedAt = deletedNow()
+		} else {
+			a.Version++
+		}
+		a.UpdatedAt = time.Now()
+		r.s.articles[a.ID] = *a
+	}
+	return results, nil
+}
+
+// articleIDs returns the IDs of articles in ascending order,
+// those in trash if deleted and the rest otherwise.
+func (s *MemoryStore) articleIDs(deleted bool) []uint {
+	ids := []uint{}
+	for id, a := range s.articles {
+		if a.DeletedAt.Valid == deleted {
+			ids = append(ids, id)
+		}
+	}
+	return sortIDs(ids)
+}
+
+// article returns article with matching id along with its
+// associations, it must be in trash if deleted and not otherwise.
+func (s *MemoryStore) article(id uint, deleted bool) (*models.Article, error) {
+	a, ok := s.articles[id]
+	if !ok || a.DeletedAt.Valid != deleted {
+		return nil, ErrNotFound
+	}
+	a.User = s.user(a.UserID)
+	a.Category = s.category(a.CategoryID)
+	a.Authors = s.articleAuthors(a.ID)
+	return &a, nil
+}
+
+// projectedArticle returns the article with matching id
+// with only what p selects.
+func (s *MemoryStore) projectedArticle(id uint, p Projection) (*models.Article, error) {
+	a, err := s.article(id, false)
+	if err != nil {
+		return nil, err
+	}
+	if !p.Expands("user") {
+		a.User = nil
+	}
+	if !p.Expands("category") {
+		a.Category = nil
+	}
+	if !p.Expands("authors") {
+		a.Authors = nil
+	}
+	p.clearColumns(a, articleColumns)
+	return a, nil
+}
+
+// saveArticle stores a without its associations.
+func (s *MemoryStore) saveArticle(a models.Article) {
+	a.User = nil
+	a.Category = nil
+	a.Authors = nil
+	a.Series = nil
+	s.articles[a.ID] = a
+}
+
+// user returns user with matching id, nil if it doesn't exist.
+func (s *MemoryStore) user(id uint) *models.User {
+	u, ok := s.users[id]
+	if !ok {
+		return nil
+	}
+	return &u
+}
+
+// author returns the credit of user with userID
+// as an author of article with articleID, if any.
+func (s *MemoryStore) author(articleID uint, userID uint) *models.ArticleAuthor {
+	for i := range s.authors {
+		if s.authors[i].ArticleID == articleID && s.authors[i].UserID == userID {
+			return &s.authors[i]
+		}
+	}
+	return nil
+}
+
+// articleAuthors returns the authors of article
+// with matching id along with their users.
+func (s *MemoryStore) articleAuthors(id uint) []models.ArticleAuthor {
+	authors := []models.ArticleAuthor{}
+	for _, aa := range s.authors {
+		if aa.ArticleID == id {
+			aa.User = s.user(aa.UserID)
+			authors = append(authors, aa)
+		}
+	}
+	return authors
+}
+
+// uniqueArticleSlug returns a slug for an article titled title
+// with matching id, suffixed with a number if needed so that no other
+// article, including those in trash or renamed, has used it.
+func (s *MemoryStore) uniqueArticleSlug(title string, id uint) string {
+	base := models.Slugify(title)
+	if base == "" {
+		base = "article"
+	}
+	slug := base
+	for n := 2; s.articleSlugTaken(&models.Article{ID: id, Slug: slug}) || s.articleSlugUsed(slug, id); n++ {
+		slug = fmt.Sprintf("%s-%d", base, n)
+	}
+	return slug
+}
+
+// articleSlugTaken reports whether an article other than a,
+// including those in trash, has the slug of a.
+func (s *MemoryStore) articleSlugTaken(a *models.Article) bool {
+	for id, other := range s.articles {
+		if id != a.ID && other.Slug == a.Slug {
+			return true
+		}
+	}
+	return false
+}
+
+// articleSlugUsed reports whether an article other than
+// the one with matching id had slug before being renamed.
+func (s *MemoryStore) articleSlugUsed(slug string, id uint) bool {
+	for _, old := range s.articleSlugs {
+		if old.Slug == slug && old.ArticleID != id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+package repository_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/JonathanGzzBen/ingenialists/api/v1/models"
+	"github.com/JonathanGzzBen/ingenialists/api/v1/repository"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestCreateArticleRetriesTakenSlug(t *testing.T) {
+	// The slug is taken from another connection, which
+	// would get a database of its own if it were in memory
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	r := repository.NewArticlesGormRepository(db)
+	// Another article takes the slug after it's picked,
+	// as a concurrent request with the same title would
+	taken := false
+	err = db.Callback().Create().Before("gorm:create").Register("test:take_slug", func(tx *gorm.DB) {
+		if a, ok := tx.Statement.Dest.(**models.Article); ok && !taken {
+			taken = true
+			db.Exec("INSERT INTO articles (title, slug) VALUES (?, ?)", (*a).Title, (*a).Slug)
+		}
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	a, err := r.CreateArticle(context.Background(), &models.Article{UserID: 1, CategoryID: 1, Title: "First article"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !taken {
+		t.Fatalf("Expected slug to be taken while creating the article")
+	}
+	if a.Slug != "first-article-2" {
+		t.Fatalf("Expected %v, got %v", "first-article-2", a.Slug)
+	}
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/JonathanGzzBen/ingenialists/api/v1/models"
+	"gorm.io/gorm"
+)
+
+// AuditRepository is append only, entries
+// can't be updated nor deleted.
+type AuditRepository interface {
+	GetAuditEntries(context.Context, AuditFilter) ([]models.AuditEntry, error)
+	CreateAuditEntry(context.Context, *models.AuditEntry) (*models.AuditEntry, error)
+}
+
+// AuditFilter narrows the entries returned by GetAuditEntries,
+// zero value fields don't filter.
+type AuditFilter struct {
+	ActorID    uint
+	TargetType string
+	TargetID   uint
+	From       time.Time
+	To         time.Time
+}
+
+type AuditGormRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditGormRepository(db *gorm.DB) *AuditGormRepository {
+	db.AutoMigrate(&models.AuditEntry{})
+	return &AuditGormRepository{
+		db: db,
+	}
+}
+
+// GetAuditEntries returns matching entries, newest first.
+func (r *AuditGormRepository) GetAuditEntries(ctx context.Context, f AuditFilter) ([]models.AuditEntry, error) {
+	var entries []models.AuditEntry
+	q := r.db.WithContext(ctx)
+	if f.ActorID != 0 {
+		q = q.Where("actor_id = ?", f.ActorID)
+	}
+	if f.TargetType != "" {
+		q = q.Where("target_type = ?", f.TargetType)
+	}
+	if f.TargetID != 0 {
+		q = q.Where("target_id = ?", f.TargetID)
+	}
+	if !f.From.IsZero() {
+		q = q.Where("created_at >= ?", f.From)
+	}
+	if !f.To.IsZero() {
+		q = q.Where("created_at <= ?", f.To)
+	}
+	res := q.Order("created_at desc").Find(&entries)
+	if res.Error != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	return entries, nil
+}
+
+func (r *AuditGormRepository) CreateAuditEntry(ctx context.Context, e *models.AuditEntry) (*models.AuditEntry, error) {
+	res := r.db.WithContext(ctx).Create(e)
+	if res.Error != nil {
+		return nil, ErrCouldNotCreate
+	}
+	return e, nil
+}
@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultCacheTTL is used when CacheConfig doesn't specify TTL
+const defaultCacheTTL = time.Minute
+
+// defaultCacheSize is used when CacheConfig doesn't specify Size
+const defaultCacheSize = 1000
+
+// CacheConfig configures a Cache.
+type CacheConfig struct {
+	// TTL is how long entries are kept, defaultCacheTTL if zero
+	TTL time.Duration
+	// Size is the maximum number of entries kept, the least
+	// recently used are evicted first. defaultCacheSize if zero
+	Size int
+	// SingleFlight makes concurrent misses of the same entry wait
+	// for a single query to finish instead of each making its own
+	SingleFlight bool
+}
+
+// Cache keeps the results of repository queries in memory
+// for the caching decorators of repositories.
+//
+// Decorators sharing a cache invalidate each other's entries,
+// so records loaded along with others are never staler than TTL.
+// It's safe for concurrent use.
+type Cache struct {
+	ttl          time.Duration
+	size         int
+	singleFlight bool
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	// lru has the most recently used entries in front
+	lru *list.List
+	// generation is incremented on every invalidation, so that
+	// queries started before it aren't cached after it
+	generation uint64
+	calls      map[string]*cacheCall
+}
+
+type cacheEntry struct {
+	key     string
+	value   interface{}
+	expires time.Time
+}
+
+// cacheCall is a query of an entry other callers wait for.
+type cacheCall struct {
+	done  chan struct{}
+	value interface{}
+	err   error
+}
+
+func NewCache(cfg CacheConfig) *Cache {
+	c := &Cache{
+		ttl:          cfg.TTL,
+		size:         cfg.Size,
+		singleFlight: cfg.SingleFlight,
+		entries:      map[string]*list.Element{},
+		lru:          list.New(),
+		calls:        map[string]*cacheCall{},
+	}
+	if c.ttl <= 0 {
+		c.ttl = defaultCacheTTL
+	}
+	if c.size <= 0 {
+		c.size = defaultCacheSize
+	}
+	return c
+}
+
+// load returns the value cached under key, calling query to get it
+// and caching it if it isn't cached or expired. Errors aren't cached.
+//
+// With single flight the query is shared by every caller waiting
+// for it, so it's made with a context that isn't canceled with ctx,
+// and each caller stops waiting when its own ctx is done.
+func (c *Cache) load(ctx context.Context, key string, query func(context.Context) (interface{}, error)) (interface{}, error) {
+	c.mu.Lock()
+	if e, ok := c.entries[key]; ok {
+		entry := e.Value.(*cacheEntry)
+		if time.Now().Before(entry.expires) {
+			c.lru.MoveToFront(e)
+			c.mu.Unlock()
+			return entry.value, nil
+		}
+		c.remove(e)
+	}
+	generation := c.generation
+	if !c.singleFlight {
+		c.mu.Unlock()
+		value, err := query(ctx)
+		c.mu.Lock()
+		c.store(key, generation, value, err)
+		c.mu.Unlock()
+		return value, err
+	}
+	call, ok := c.calls[key]
+	if !ok {
+		call = &cacheCall{done: make(chan struct{})}
+		c.calls[key] = call
+		go func() {
+			value, err := query(detachedContext{ctx})
+			c.mu.Lock()
+			call.value, call.err = value, err
+			delete(c.calls, key)
+			close(call.done)
+			c.store(key, generation, value, err)
+			c.mu.Unlock()
+		}()
+	}
+	c.mu.Unlock()
+	select {
+	case <-call.done:
+		return call.value, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// store caches value under key if it was queried without error
+// since the last invalidation. c.mu must be held.
+func (c *Cache) store(key string, generation uint64, value interface{}, err error) {
+	if err == nil && generation == c.generation {
+		c.add(key, value)
+	}
+}
+
+// add caches value under key, evicting the least
+// recently used entry if the cache is full.
+func (c *Cache) add(key string, value interface{}) {
+	if e, ok := c.entries[key]; ok {
+		c.remove(e)
+	}
+	c.entries[key] = c.lru.PushFront(&cacheEntry{
+		key:     key,
+		value:   value,
+		expires: time.Now().Add(c.ttl),
+	})
+	for c.lru.Len() > c.size {
+		c.remove(c.lru.Back())
+	}
+}
+
+func (c *Cache) remove(e *list.Element) {
+	c.lru.Remove(e)
+	delete(c.entries, e.Value.(*cacheEntry).key)
+}
+
+// invalidate removes the entries whose keys
+// start with any of prefixes.
+func (c *Cache) invalidate(prefixes ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	for key, e := range c.entries {
+		for _, p := range prefixes {
+			if strings.HasPrefix(key, p) {
+				c.remove(e)
+				break
+			}
+		}
+	}
+}
+
+// Clear removes every entry.
+func (c *Cache) Clear() {
+	c.invalidate("")
+}
+
+// Len returns the number of entries cached, including expired ones
+// that haven't been removed yet.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// detachedContext has the values of the context it's made from,
+// but isn't canceled with it nor has its deadline.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+func (d detachedContext) Value(key interface{}) interface{} {
+	return d.parent.Value(key)
+}
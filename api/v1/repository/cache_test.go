@@ -0,0 +1,264 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/JonathanGzzBen/ingenialists/api/v1/models"
+	"github.com/JonathanGzzBen/ingenialists/api/v1/repository"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// countingCategories counts the queries of all categories
+// made to the repository it wraps, which take delay to finish.
+type countingCategories struct {
+	repository.CategoriesRepository
+	delay time.Duration
+	calls int32
+}
+
+func (r *countingCategories) GetAllCategories(ctx context.Context, p repository.Projection) ([]models.Category, error) {
+	atomic.AddInt32(&r.calls, 1)
+	time.Sleep(r.delay)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return r.CategoriesRepository.GetAllCategories(ctx, p)
+}
+
+func TestCachedArticlesRepositoryInvalidatesOnWrites(t *testing.T) {
+	s := repository.NewMemoryStore()
+	articles := repository.NewArticlesMemoryRepository(s)
+	cached := repository.NewCachedArticlesRepository(articles, repository.NewCache(repository.CacheConfig{}))
+
+	a, err := cached.CreateArticle(context.Background(), &models.Article{UserID: 1, CategoryID: 1, Title: "First article"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	got, err := cached.GetArticle(context.Background(), a.ID, repository.WholeArticle)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// Changes to returned articles don't reach the cache
+	got.Title = "Changed"
+
+	// Writes made around the cache aren't seen until it's invalidated
+	a.Body = "Hello world"
+	if _, err := articles.UpdateArticle(context.Background(), a); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	got, err = cached.GetArticle(context.Background(), a.ID, repository.WholeArticle)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got.Title != "First article" || got.Body != "" {
+		t.Fatalf("Expected cached article, got %+v", got)
+	}
+
+	if _, err := cached.BulkUpdateArticles(context.Background(), []uint{a.ID}, repository.BulkOperation{AddTags: []string{"go"}}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	got, err = cached.GetArticle(context.Background(), a.ID, repository.WholeArticle)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got.Body != "Hello world" || got.Tags != "go" {
+		t.Fatalf("Expected updated article, got %+v", got)
+	}
+}
+
+func TestCachedCategoriesRepositoryInvalidatesArticles(t *testing.T) {
+	s := repository.NewMemoryStore()
+	cache := repository.NewCache(repository.CacheConfig{})
+	categories := repository.NewCachedCategoriesRepository(repository.NewCategoriesMemoryRepository(s), cache)
+	articles := repository.NewCachedArticlesRepository(repository.NewArticlesMemoryRepository(s), cache)
+
+	c, err := categories.CreateCategory(context.Background(), &models.Category{Name: "Databases", Slug: "databases"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	a, err := articles.CreateArticle(context.Background(), &models.Article{UserID: 1, CategoryID: c.ID, Title: "First article"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := articles.GetArticle(context.Background(), a.ID, repository.WholeArticle); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	c.Name = "Data"
+	if _, err := categories.UpdateCategory(context.Background(), c); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	got, err := articles.GetArticle(context.Background(), a.ID, repository.WholeArticle)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got.Category == nil || got.Category.Name != "Data" {
+		t.Fatalf("Expected %v, got %v", "Data", got.Category)
+	}
+}
+
+func TestCacheEntriesExpire(t *testing.T) {
+	s := repository.NewMemoryStore()
+	counting := &countingCategories{CategoriesRepository: repository.NewCategoriesMemoryRepository(s)}
+	cached := repository.NewCachedCategoriesRepository(counting, repository.NewCache(repository.CacheConfig{TTL: 20 * time.Millisecond}))
+
+	for i := 0; i < 2; i++ {
+		if _, err := cached.GetAllCategories(context.Background(), repository.Projection{}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	if counting.calls != 1 {
+		t.Fatalf("Expected %v, got %v", 1, counting.calls)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if _, err := cached.GetAllCategories(context.Background(), repository.Projection{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if counting.calls != 2 {
+		t.Fatalf("Expected %v, got %v", 2, counting.calls)
+	}
+}
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	s := repository.NewMemoryStore()
+	counting := &countingCategories{CategoriesRepository: repository.NewCategoriesMemoryRepository(s)}
+	cache := repository.NewCache(repository.CacheConfig{Size: 2})
+	cached := repository.NewCachedCategoriesRepository(counting, cache)
+
+	projections := []repository.Projection{
+		{},
+		{Fields: []string{"name"}},
+		{},
+		{Fields: []string{"slug"}},
+		{},
+	}
+	for _, p := range projections {
+		if _, err := cached.GetAllCategories(context.Background(), p); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	if cache.Len() != 2 {
+		t.Fatalf("Expected %v, got %v", 2, cache.Len())
+	}
+	// The default projection was used last before "slug" was
+	// cached, so "name" was evicted instead
+	if counting.calls != 3 {
+		t.Fatalf("Expected %v, got %v", 3, counting.calls)
+	}
+}
+
+func TestCacheSingleFlight(t *testing.T) {
+	s := repository.NewMemoryStore()
+	counting := &countingCategories{CategoriesRepository: repository.NewCategoriesMemoryRepository(s), delay: 20 * time.Millisecond}
+	cached := repository.NewCachedCategoriesRepository(counting, repository.NewCache(repository.CacheConfig{SingleFlight: true}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := cached.GetAllCategories(context.Background(), repository.Projection{}); err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if counting.calls != 1 {
+		t.Fatalf("Expected %v, got %v", 1, counting.calls)
+	}
+}
+
+func TestCacheSingleFlightWaitersStopWithTheirContext(t *testing.T) {
+	s := repository.NewMemoryStore()
+	counting := &countingCategories{CategoriesRepository: repository.NewCategoriesMemoryRepository(s), delay: 50 * time.Millisecond}
+	cached := repository.NewCachedCategoriesRepository(counting, repository.NewCache(repository.CacheConfig{SingleFlight: true}))
+
+	// The first caller gives up before the query it started finishes,
+	// the second one still gets its result
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		if _, err := cached.GetAllCategories(ctx, repository.Projection{}); err != context.DeadlineExceeded {
+			t.Errorf("Expected %v, got %v", context.DeadlineExceeded, err)
+		}
+		if waited := time.Since(start); waited >= 50*time.Millisecond {
+			t.Errorf("Expected to stop waiting when canceled, waited %v", waited)
+		}
+	}()
+	time.Sleep(5 * time.Millisecond)
+	if _, err := cached.GetAllCategories(context.Background(), repository.Projection{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	wg.Wait()
+	if counting.calls != 1 {
+		t.Fatalf("Expected %v, got %v", 1, counting.calls)
+	}
+}
+
+func TestGormUnitOfWorkWithCachedRepositories(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	defer sqlDB.Close()
+	cache := repository.NewCache(repository.CacheConfig{})
+	repos := repository.Repositories{
+		Users:      repository.NewCachedUsersRepository(repository.NewUsersGormRepository(db), cache),
+		Articles:   repository.NewCachedArticlesRepository(repository.NewArticlesGormRepository(db), cache),
+		Categories: repository.NewCachedCategoriesRepository(repository.NewCategoriesGormRepository(db), cache),
+	}
+	if _, err := repos.Categories.GetAllCategories(context.Background(), repository.Projection{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	failed := errors.New("failed")
+	err = repository.NewGormUnitOfWork(db).Do(context.Background(), repos, func(r repository.Repositories) error {
+		if _, err := r.Categories.CreateCategory(context.Background(), &models.Category{Name: "Databases", Slug: "databases"}); err != nil {
+			return err
+		}
+		return failed
+	})
+	if err != failed {
+		t.Fatalf("Expected %v, got %v", failed, err)
+	}
+	// Cached entries are kept when nothing was committed
+	if cache.Len() != 1 {
+		t.Fatalf("Expected %v, got %v", 1, cache.Len())
+	}
+	categories, err := repos.Categories.GetAllCategories(context.Background(), repository.Projection{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(categories) != 0 {
+		t.Fatalf("Expected category to be rolled back, got %+v", categories)
+	}
+
+	err = repository.NewGormUnitOfWork(db).Do(context.Background(), repos, func(r repository.Repositories) error {
+		_, err := r.Categories.CreateCategory(context.Background(), &models.Category{Name: "Databases", Slug: "databases"})
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	categories, err = repos.Categories.GetAllCategories(context.Background(), repository.Projection{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(categories) != 1 {
+		t.Fatalf("Expected %v, got %v", 1, len(categories))
+	}
+}
@@ -0,0 +1,239 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/JonathanGzzBen/ingenialists/api/v1/models"
+)
+
+// Prefixes of the keys of entries cached by each decorator.
+// Articles are cached along with their users and categories,
+// so changes to those invalidate articles too.
+const (
+	usersCacheKey      = "users/"
+	articlesCacheKey   = "articles/"
+	categoriesCacheKey = "categories/"
+)
+
+// CachedUsersRepository is a UsersRepository that
+// caches users returned by GetUser in a Cache.
+// Other queries are made to the repository it wraps.
+type CachedUsersRepository struct {
+	UsersRepository
+	cache *Cache
+}
+
+func NewCachedUsersRepository(r UsersRepository, c *Cache) *CachedUsersRepository {
+	return &CachedUsersRepository{
+		UsersRepository: r,
+		cache:           c,
+	}
+}
+
+func (r *CachedUsersRepository) GetUser(ctx context.Context, id uint, p Projection) (*models.User, error) {
+	v, err := r.cache.load(ctx, fmt.Sprintf("%sid/%d/%s", usersCacheKey, id, p.key()), func(ctx context.Context) (interface{}, error) {
+		return r.UsersRepository.GetUser(ctx, id, p)
+	})
+	if err != nil {
+		return nil, err
+	}
+	u := *v.(*models.User)
+	return &u, nil
+}
+
+func (r *CachedUsersRepository) CreateUser(ctx context.Context, u *models.User) (*models.User, error) {
+	defer r.cache.invalidate(usersCacheKey, articlesCacheKey)
+	return r.UsersRepository.CreateUser(ctx, u)
+}
+
+func (r *CachedUsersRepository) UpdateUser(ctx context.Context, u *models.User) (*models.User, error) {
+	defer r.cache.invalidate(usersCacheKey, articlesCacheKey)
+	return r.UsersRepository.UpdateUser(ctx, u)
+}
+
+// CachedCategoriesRepository is a CategoriesRepository that caches
+// categories returned by GetAllCategories, GetCategory and
+// GetCategoryBySlug in a Cache. Other queries are made
+// to the repository it wraps.
+type CachedCategoriesRepository struct {
+	CategoriesRepository
+	cache *Cache
+}
+
+func NewCachedCategoriesRepository(r CategoriesRepository, c *Cache) *CachedCategoriesRepository {
+	return &CachedCategoriesRepository{
+		CategoriesRepository: r,
+		cache:                c,
+	}
+}
+
+func (r *CachedCategoriesRepository) GetAllCategories(ctx context.Context, p Projection) ([]models.Category, error) {
+	v, err := r.cache.load(ctx, fmt.Sprintf("%sall/%s", categoriesCacheKey, p.key()), func(ctx context.Context) (interface{}, error) {
+		return r.CategoriesRepository.GetAllCategories(ctx, p)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append([]models.Category{}, v.([]models.Category)...), nil
+}
+
+func (r *CachedCategoriesRepository) GetCategory(ctx context.Context, id uint, p Projection) (*models.Category, error) {
+	return r.loadCategory(ctx, fmt.Sprintf("%sid/%d/%s", categoriesCacheKey, id, p.key()), func(ctx context.Context) (interface{}, error) {
+		return r.CategoriesRepository.GetCategory(ctx, id, p)
+	})
+}
+
+func (r *CachedCategoriesRepository) GetCategoryBySlug(ctx context.Context, slug string, p Projection) (*models.Category, error) {
+	return r.loadCategory(ctx, fmt.Sprintf("%sslug/%s/%s", categoriesCacheKey, slug, p.key()), func(ctx context.Context) (interface{}, error) {
+		return r.CategoriesRepository.GetCategoryBySlug(ctx, slug, p)
+	})
+}
+
+func (r *CachedCategoriesRepository) loadCategory(ctx context.Context, key string, query func(context.Context) (interface{}, error)) (*models.Category, error) {
+	v, err := r.cache.load(ctx, key, query)
+	if err != nil {
+		return nil, err
+	}
+	c := *v.(*models.Category)
+	return &c, nil
+}
+
+func (r *CachedCategoriesRepository) CreateCategory(ctx context.Context, c *models.Category) (*models.Category, error) {
+	defer r.cache.invalidate(categoriesCacheKey)
+	return r.CategoriesRepository.CreateCategory(ctx, c)
+}
+
+func (r *CachedCategoriesRepository) UpdateCategory(ctx context.Context, c *models.Category) (*models.Category, error) {
+	defer r.cache.invalidate(categoriesCacheKey, articlesCacheKey)
+	return r.CategoriesRepository.UpdateCategory(ctx, c)
+}
+
+func (r *CachedCategoriesRepository) DeleteCategory(ctx context.Context, id uint, deletedBy uint, d CategoryDeletion) ([]uint, error) {
+	defer r.cache.invalidate(categoriesCacheKey, articlesCacheKey)
+	return r.CategoriesRepository.DeleteCategory(ctx, id, deletedBy, d)
+}
+
+func (r *CachedCategoriesRepository) RestoreCategory(ctx context.Context, id uint) (*models.Category, error) {
+	defer r.cache.invalidate(categoriesCacheKey, articlesCacheKey)
+	return r.CategoriesRepository.RestoreCategory(ctx, id)
+}
+
+func (r *CachedCategoriesRepository) PurgeCategories(ctx context.Context, t time.Time) (int64, error) {
+	defer r.cache.invalidate(categoriesCacheKey, articlesCacheKey)
+	return r.CategoriesRepository.PurgeCategories(ctx, t)
+}
+
+// CachedArticlesRepository is an ArticlesRepository that
+// caches articles returned by GetArticle and GetArticleBySlug
+// in a Cache. Other queries are made to the repository it wraps.
+type CachedArticlesRepository struct {
+	ArticlesRepository
+	cache *Cache
+}
+
+func NewCachedArticlesRepository(r ArticlesRepository, c *Cache) *CachedArticlesRepository {
+	return &CachedArticlesRepository{
+		ArticlesRepository: r,
+		cache:              c,
+	}
+}
+
+func (r *CachedArticlesRepository) GetArticle(ctx context.Context, id uint, p Projection) (*models.Article, error) {
+	return r.loadArticle(ctx, fmt.Sprintf("%sid/%d/%s", articlesCacheKey, id, p.key()), func(ctx context.Context) (interface{}, error) {
+		return r.ArticlesRepository.GetArticle(ctx, id, p)
+	})
+}
+
+func (r *CachedArticlesRepository) GetArticleBySlug(ctx context.Context, slug string, p Projection) (*models.Article, error) {
+	return r.loadArticle(ctx, fmt.Sprintf("%sslug/%s/%s", articlesCacheKey, slug, p.key()), func(ctx context.Context) (interface{}, error) {
+		return r.ArticlesRepository.GetArticleBySlug(ctx, slug, p)
+	})
+}
+
+// loadArticle returns a copy of the cached article,
+// so that callers can change it without changing the cache.
+func (r *CachedArticlesRepository) loadArticle(ctx context.Context, key string, query func(context.Context) (interface{}, error)) (*models.Article, error) {
+	v, err := r.cache.load(ctx, key, query)
+	if err != nil {
+		return nil, err
+	}
+	a := *v.(*models.Article)
+	a.Authors = append([]models.ArticleAuthor(nil), a.Authors...)
+	return &a, nil
+}
+
+func (r *CachedArticlesRepository) CreateArticle(ctx context.Context, a *models.Article) (*models.Article, error) {
+	defer r.cache.invalidate(articlesCacheKey)
+	return r.ArticlesRepository.CreateArticle(ctx, a)
+}
+
+func (r *CachedArticlesRepository) UpdateArticle(ctx context.Context, a *models.Article) (*models.Article, error) {
+	defer r.cache.invalidate(articlesCacheKey)
+	return r.ArticlesRepository.UpdateArticle(ctx, a)
+}
+
+func (r *CachedArticlesRepository) DeleteArticle(ctx context.Context, id uint, deletedBy uint) error {
+	defer r.cache.invalidate(articlesCacheKey)
+	return r.ArticlesRepository.DeleteArticle(ctx, id, deletedBy)
+}
+
+func (r *CachedArticlesRepository) RestoreArticle(ctx context.Context, id uint) (*models.Article, error) {
+	defer r.cache.invalidate(articlesCacheKey)
+	return r.ArticlesRepository.RestoreArticle(ctx, id)
+}
+
+func (r *CachedArticlesRepository) PurgeArticles(ctx context.Context, t time.Time) (int64, error) {
+	defer r.cache.invalidate(articlesCacheKey)
+	return r.ArticlesRepository.PurgeArticles(ctx, t)
+}
+
+// PublishDueArticles only invalidates articles if any was published,
+// since it's called periodically whether or not they're due.
+func (r *CachedArticlesRepository) PublishDueArticles(ctx context.Context, t time.Time) (int64, error) {
+	n, err := r.ArticlesRepository.PublishDueArticles(ctx, t)
+	if n > 0 {
+		r.cache.invalidate(articlesCacheKey)
+	}
+	return n, err
+}
+
+func (r *CachedArticlesRepository) BulkUpdateArticles(ctx context.Context, ids []uint, op BulkOperation) ([]BulkResult, error) {
+	defer r.cache.invalidate(articlesCacheKey)
+	return r.ArticlesRepository.BulkUpdateArticles(ctx, ids, op)
+}
+
+func (r *CachedArticlesRepository) SaveArticleAuthor(ctx context.Context, aa *models.ArticleAuthor) (*models.ArticleAuthor, error) {
+	defer r.cache.invalidate(articlesCacheKey)
+	return r.ArticlesRepository.SaveArticleAuthor(ctx, aa)
+}
+
+func (r *CachedArticlesRepository) RemoveArticleAuthor(ctx context.Context, articleID uint, userID uint) error {
+	defer r.cache.invalidate(articlesCacheKey)
+	return r.ArticlesRepository.RemoveArticleAuthor(ctx, articleID, userID)
+}
+
+// uncached returns repos with caching decorators replaced
+// by the repositories they wrap, along with a function that
+// invalidates what writes through those decorators would.
+func (repos Repositories) uncached() (Repositories, func()) {
+	var invalidations []func()
+	if r, ok := repos.Users.(*CachedUsersRepository); ok {
+		repos.Users = r.UsersRepository
+		invalidations = append(invalidations, func() { r.cache.invalidate(usersCacheKey, articlesCacheKey) })
+	}
+	if r, ok := repos.Articles.(*CachedArticlesRepository); ok {
+		repos.Articles = r.ArticlesRepository
+		invalidations = append(invalidations, func() { r.cache.invalidate(articlesCacheKey) })
+	}
+	if r, ok := repos.Categories.(*CachedCategoriesRepository); ok {
+		repos.Categories = r.CategoriesRepository
+		invalidations = append(invalidations, func() { r.cache.invalidate(categoriesCacheKey, articlesCacheKey) })
+	}
+	return repos, func() {
+		for _, invalidate := range invalidations {
+			invalidate()
+		}
+	}
+}
@@ -1,19 +1,37 @@
 package repository
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/JonathanGzzBen/ingenialists/api/v1/models"
 	"gorm.io/gorm"
 )
 
 type CategoriesRepository interface {
-	GetAllCategories() ([]models.Category, error)
-	GetCategory(uint) (*models.Category, error)
-	CreateCategory(*models.Category) (*models.Category, error)
-	UpdateCategory(*models.Category) (*models.Category, error)
-	DeleteCategory(uint) error
+	GetAllCategories(context.Context, Projection) ([]models.Category, error)
+	GetCategory(context.Context, uint, Projection) (*models.Category, error)
+	GetCategoryBySlug(context.Context, string, Projection) (*models.Category, error)
+	CreateCategory(context.Context, *models.Category) (*models.Category, error)
+	UpdateCategory(context.Context, *models.Category) (*models.Category, error)
+	DeleteCategory(context.Context, uint, uint, CategoryDeletion) ([]uint, error)
+	GetDeletedCategories(context.Context) ([]models.Category, error)
+	RestoreCategory(context.Context, uint) (*models.Category, error)
+	PurgeCategories(context.Context, time.Time) (int64, error)
+}
+
+// CategoryDeletion tells DeleteCategory what to do
+// with the articles in the category being deleted.
+//
+// The zero value refuses to delete categories with articles.
+type CategoryDeletion struct {
+	// ReassignTo is the ID of the category articles are moved to
+	ReassignTo uint
+	// Cascade moves articles to trash along with the category
+	Cascade bool
 }
 
 type CategoriesGormRepository struct {
@@ -26,27 +44,53 @@ var (
 	ErrCouldNotCreate   = errors.New("could not insert record")
 	ErrCouldNotUpdate   = errors.New("could not update record")
 	ErrCouldNotDelete   = errors.New("could not delete record")
+	ErrCategoryInUse    = errors.New("category has articles")
+	ErrSlugTaken        = errors.New("slug is already in use")
+	ErrVersionConflict  = errors.New("record was modified since it was read")
+	ErrTokenUsed        = errors.New("token was already rotated or revoked")
+	ErrAlreadyReviewed  = errors.New("role request was already reviewed")
 )
 
 func NewCategoriesGormRepository(db *gorm.DB) *CategoriesGormRepository {
 	db.AutoMigrate(&models.Category{})
+	// Categories created before slugs existed get one from their name
+	var unslugged []models.Category
+	db.Unscoped().Where("slug IS NULL OR slug = ''").Find(&unslugged)
+	for _, c := range unslugged {
+		slug := models.Slugify(c.Name)
+		var taken int64
+		db.Unscoped().Model(&models.Category{}).Where("slug = ?", slug).Count(&taken)
+		if slug == "" || taken > 0 {
+			slug = strings.Trim(fmt.Sprintf("%s-%d", slug, c.ID), "-")
+		}
+		db.Unscoped().Model(&c).Update("slug", slug)
+	}
+	// Categories created before UpdatedAt existed are considered
+	// updated when the column is added
+	db.Unscoped().Model(&models.Category{}).Where("updated_at IS NULL").UpdateColumn("updated_at", time.Now())
 	return &CategoriesGormRepository{
 		db: db,
 	}
 }
 
-func (r CategoriesGormRepository) GetAllCategories() ([]models.Category, error) {
+func (r CategoriesGormRepository) GetAllCategories(ctx context.Context, p Projection) ([]models.Category, error) {
 	var categories []models.Category
-	res := r.db.Find(&categories)
+	tx := p.selectColumns(r.db.WithContext(ctx), &models.Category{}, []string{"id", "parent_id", "updated_at"})
+	if p.Expands("parent") {
+		tx = tx.Preload("Parent")
+	}
+	res := tx.Order("sort_order, name").Find(&categories)
 	if res.Error != nil {
 		return nil, fmt.Errorf("could not retrieve categories: %s", res.Error.Error())
 	}
 	return categories, nil
 }
 
-func (r *CategoriesGormRepository) GetCategory(id uint) (*models.Category, error) {
+// GetCategory returns category with matching id, loading
+// what p selects. Every field is loaded if p has no fields.
+func (r *CategoriesGormRepository) GetCategory(ctx context.Context, id uint, p Projection) (*models.Category, error) {
 	var category *models.Category
-	res := r.db.Find(&category, id)
+	res := r.selectCategory(ctx, p).Find(&category, id)
 	if res.Error == gorm.ErrRecordNotFound || res.RowsAffected != 1 {
 		return nil, ErrNotFound
 	}
@@ -56,30 +100,146 @@ func (r *CategoriesGormRepository) GetCategory(id uint) (*models.Category, error
 	return category, nil
 }
 
-func (r *CategoriesGormRepository) CreateCategory(c *models.Category) (*models.Category, error) {
-	res := r.db.Create(c)
+// GetCategoryBySlug returns category with matching slug,
+// loading what p selects.
+func (r *CategoriesGormRepository) GetCategoryBySlug(ctx context.Context, slug string, p Projection) (*models.Category, error) {
+	var category *models.Category
+	res := r.selectCategory(ctx, p).Where("slug = ?", slug).Find(&category)
+	if res.Error == gorm.ErrRecordNotFound || res.RowsAffected != 1 {
+		return nil, ErrNotFound
+	}
+	if res.Error != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	return category, nil
+}
+
+// selectCategory returns a query loading what p selects of a category.
+func (r *CategoriesGormRepository) selectCategory(ctx context.Context, p Projection) *gorm.DB {
+	tx := p.selectColumns(r.db.WithContext(ctx), &models.Category{}, categoryColumns)
+	if p.Expands("parent") {
+		tx = tx.Preload("Parent")
+	}
+	return tx
+}
+
+// slugTaken reports whether a category other than c,
+// including those in trash, has the slug of c.
+func (r *CategoriesGormRepository) slugTaken(ctx context.Context, c *models.Category) bool {
+	var count int64
+	r.db.WithContext(ctx).Unscoped().Model(&models.Category{}).Where("slug = ? AND id <> ?", c.Slug, c.ID).Count(&count)
+	return count > 0
+}
+
+func (r *CategoriesGormRepository) CreateCategory(ctx context.Context, c *models.Category) (*models.Category, error) {
+	if r.slugTaken(ctx, c) {
+		return nil, ErrSlugTaken
+	}
+	c.Version = 1
+	res := r.db.WithContext(ctx).Create(c)
 	if res.Error != nil {
 		return nil, ErrCouldNotCreate
 	}
 	return c, nil
 }
 
-func (r *CategoriesGormRepository) UpdateCategory(c *models.Category) (*models.Category, error) {
-	res := r.db.Save(c)
-	if res.Error != nil {
-		return nil, ErrCouldNotUpdate
+func (r *CategoriesGormRepository) UpdateCategory(ctx context.Context, c *models.Category) (*models.Category, error) {
+	if r.slugTaken(ctx, c) {
+		return nil, ErrSlugTaken
+	}
+	if err := saveVersioned(r.db.WithContext(ctx), c, &c.Version); err != nil {
+		return nil, err
 	}
 	return c, nil
 }
 
-func (r *CategoriesGormRepository) DeleteCategory(id uint) error {
-	c, err := r.GetCategory(id)
+// DeleteCategory moves category with matching id to trash,
+// deletedBy is the ID of the user deleting it.
+//
+// Articles in the category are handled as specified by d,
+// it returns the IDs of the articles that were affected.
+// If the category has articles and d doesn't say what to do
+// with them, ErrCategoryInUse is returned and nothing changes.
+func (r *CategoriesGormRepository) DeleteCategory(ctx context.Context, id uint, deletedBy uint, d CategoryDeletion) ([]uint, error) {
+	c, err := r.GetCategory(ctx, id, Projection{})
 	if err != nil {
-		return err
+		return nil, err
+	}
+	affected := []uint{}
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		articles := tx.Model(&models.Article{}).Where("category_id = ?", id)
+		if err := articles.Pluck("id", &affected).Error; err != nil {
+			return ErrCouldNotRetrieve
+		}
+		if len(affected) > 0 {
+			switch {
+			case d.ReassignTo != 0:
+				var target models.Category
+				res := tx.Where("id <> ?", id).Find(&target, d.ReassignTo)
+				if res.Error != nil || res.RowsAffected != 1 {
+					return ErrNotFound
+				}
+				if err := tx.Model(&models.Article{}).Where("id IN ?", affected).Updates(map[string]interface{}{
+					"category_id": d.ReassignTo,
+					"version":     gorm.Expr("version + 1"),
+				}).Error; err != nil {
+					return ErrCouldNotUpdate
+				}
+			case d.Cascade:
+				if err := tx.Model(&models.Article{}).Where("id IN ?", affected).Update("deleted_by", deletedBy).Error; err != nil {
+					return ErrCouldNotDelete
+				}
+				if err := tx.Where("id IN ?", affected).Delete(&models.Article{}).Error; err != nil {
+					return ErrCouldNotDelete
+				}
+			default:
+				return ErrCategoryInUse
+			}
+		}
+		if err := tx.Model(c).Update("deleted_by", deletedBy).Error; err != nil {
+			return ErrCouldNotDelete
+		}
+		if err := tx.Delete(c).Error; err != nil {
+			return ErrCouldNotDelete
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return affected, nil
+}
+
+// GetDeletedCategories returns categories in trash.
+func (r *CategoriesGormRepository) GetDeletedCategories(ctx context.Context) ([]models.Category, error) {
+	var categories []models.Category
+	res := r.db.WithContext(ctx).Unscoped().Where("deleted_at IS NOT NULL").Find(&categories)
+	if res.Error != nil {
+		return nil, ErrCouldNotRetrieve
 	}
-	res := r.db.Delete(c)
+	return categories, nil
+}
+
+// RestoreCategory takes category with matching id out of trash.
+func (r *CategoriesGormRepository) RestoreCategory(ctx context.Context, id uint) (*models.Category, error) {
+	res := r.db.WithContext(ctx).Unscoped().Model(&models.Category{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Updates(map[string]interface{}{"deleted_at": nil, "deleted_by": nil})
+	if res.Error != nil {
+		return nil, ErrCouldNotUpdate
+	}
+	if res.RowsAffected != 1 {
+		return nil, ErrNotFound
+	}
+	return r.GetCategory(ctx, id, Projection{})
+}
+
+// PurgeCategories permanently deletes categories
+// that were moved to trash before t.
+func (r *CategoriesGormRepository) PurgeCategories(ctx context.Context, t time.Time) (int64, error) {
+	res := r.db.WithContext(ctx).Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", t).Delete(&models.Category{})
 	if res.Error != nil {
-		return ErrCouldNotDelete
+		return 0, ErrCouldNotDelete
 	}
-	return nil
+	return res.RowsAffected, nil
 }
@@ -0,0 +1,267 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/JonathanGzzBen/ingenialists/api/v1/models"
+	"gorm.io/gorm"
+)
+
+// CategoriesMemoryRepository is a CategoriesRepository
+// that keeps categories in a MemoryStore.
+type CategoriesMemoryRepository struct {
+	s *MemoryStore
+}
+
+func NewCategoriesMemoryRepository(s *MemoryStore) *CategoriesMemoryRepository {
+	return &CategoriesMemoryRepository{
+		s: s,
+	}
+}
+
+func (r *CategoriesMemoryRepository) GetAllCategories(ctx context.Context, p Projection) ([]models.Category, error) {
+	if ctx.Err() != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	categories := []models.Category{}
+	for _, id := range r.s.categoryIDs(false) {
+		c := r.s.categories[id]
+		if p.Expands("parent") && c.ParentID != nil {
+			c.Parent = r.s.category(*c.ParentID)
+		}
+		p.clearColumns(&c, []string{"id", "parent_id", "updated_at"})
+		categories = append(categories, c)
+	}
+	sort.SliceStable(categories, func(i, j int) bool {
+		if categories[i].SortOrder != categories[j].SortOrder {
+			return categories[i].SortOrder < categories[j].SortOrder
+		}
+		return categories[i].Name < categories[j].Name
+	})
+	return categories, nil
+}
+
+func (r *CategoriesMemoryRepository) GetCategory(ctx context.Context, id uint, p Projection) (*models.Category, error) {
+	if ctx.Err() != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	c := r.s.category(id)
+	if c == nil {
+		return nil, ErrNotFound
+	}
+	return r.s.projectCategory(c, p), nil
+}
+
+// GetCategoryBySlug returns category with matching slug.
+func (r *CategoriesMemoryRepository) GetCategoryBySlug(ctx context.Context, slug string, p Projection) (*models.Category, error) {
+	if ctx.Err() != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	for _, id := range r.s.categoryIDs(false) {
+		if c := r.s.categories[id]; c.Slug == slug {
+			return r.s.projectCategory(&c, p), nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *CategoriesMemoryRepository) CreateCategory(ctx context.Context, c *models.Category) (*models.Category, error) {
+	if ctx.Err() != nil {
+		return nil, ErrCouldNotCreate
+	}
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if r.s.categorySlugTaken(c) {
+		return nil, ErrSlugTaken
+	}
+	if _, ok := r.s.categories[c.ID]; ok {
+		return nil, ErrCouldNotCreate
+	}
+	c.ID = r.s.nextID("categories", c.ID)
+	c.Version = 1
+	if c.UpdatedAt.IsZero() {
+		c.UpdatedAt = time.Now()
+	}
+	r.s.saveCategory(*c)
+	return c, nil
+}
+
+func (r *CategoriesMemoryRepository) UpdateCategory(ctx context.Context, c *models.Category) (*models.Category, error) {
+	if ctx.Err() != nil {
+		return nil, ErrCouldNotUpdate
+	}
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if r.s.categorySlugTaken(c) {
+		return nil, ErrSlugTaken
+	}
+	if current := r.s.category(c.ID); current == nil || current.Version != c.Version {
+		return nil, ErrVersionConflict
+	}
+	c.Version++
+	c.UpdatedAt = time.Now()
+	r.s.saveCategory(*c)
+	return c, nil
+}
+
+// DeleteCategory moves category with matching id to trash,
+// deletedBy is the ID of the user deleting it.
+//
+// Articles in the category are handled as specified by d,
+// it returns the IDs of the articles that were affected.
+// If the category has articles and d doesn't say what to do
+// with them, ErrCategoryInUse is returned and nothing changes.
+func (r *CategoriesMemoryRepository) DeleteCategory(ctx context.Context, id uint, deletedBy uint, d CategoryDeletion) ([]uint, error) {
+	if ctx.Err() != nil {
+		return nil, ErrCouldNotDelete
+	}
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	c := r.s.category(id)
+	if c == nil {
+		return nil, ErrNotFound
+	}
+	affected := []uint{}
+	for _, aid := range r.s.articleIDs(false) {
+		if r.s.articles[aid].CategoryID == id {
+			affected = append(affected, aid)
+		}
+	}
+	if len(affected) > 0 {
+		switch {
+		case d.ReassignTo != 0:
+			if d.ReassignTo == id || r.s.category(d.ReassignTo) == nil {
+				return nil, ErrNotFound
+			}
+			for _, aid := range affected {
+				a := r.s.articles[aid]
+				a.CategoryID = d.ReassignTo
+				a.Version++
+				a.UpdatedAt = time.Now()
+				r.s.articles[aid] = a
+			}
+		case d.Cascade:
+			for _, aid := range affected {
+				a := r.s.articles[aid]
+				a.DeletedBy = &deletedBy
+				a.DeletedAt = deletedNow()
+				a.UpdatedAt = time.Now()
+				r.s.articles[aid] = a
+			}
+		default:
+			return nil, ErrCategoryInUse
+		}
+	}
+	c.DeletedBy = &deletedBy
+	c.DeletedAt = deletedNow()
+	c.UpdatedAt = time.Now()
+	r.s.saveCategory(*c)
+	return affected, nil
+}
+
+// GetDeletedCategories returns categories in trash.
+func (r *CategoriesMemoryRepository) GetDeletedCategories(ctx context.Context) ([]models.Category, error) {
+	if ctx.Err() != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	categories := []models.Category{}
+	for _, id := range r.s.categoryIDs(true) {
+		categories = append(categories, r.s.categories[id])
+	}
+	return categories, nil
+}
+
+// RestoreCategory takes category with matching id out of trash.
+func (r *CategoriesMemoryRepository) RestoreCategory(ctx context.Context, id uint) (*models.Category, error) {
+	if ctx.Err() != nil {
+		return nil, ErrCouldNotUpdate
+	}
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	c, ok := r.s.categories[id]
+	if !ok || !c.DeletedAt.Valid {
+		return nil, ErrNotFound
+	}
+	c.DeletedAt = gorm.DeletedAt{}
+	c.DeletedBy = nil
+	c.UpdatedAt = time.Now()
+	r.s.saveCategory(c)
+	return &c, nil
+}
+
+// PurgeCategories permanently deletes categories
+// that were moved to trash before t.
+func (r *CategoriesMemoryRepository) PurgeCategories(ctx context.Context, t time.Time) (int64, error) {
+	if ctx.Err() != nil {
+		return 0, ErrCouldNotDelete
+	}
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var n int64
+	for _, id := range r.s.categoryIDs(true) {
+		if r.s.categories[id].DeletedAt.Time.Before(t) {
+			delete(r.s.categories, id)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// categoryIDs returns the IDs of categories in ascending order,
+// those in trash if deleted and the rest otherwise.
+func (s *MemoryStore) categoryIDs(deleted bool) []uint {
+	ids := []uint{}
+	for id, c := range s.categories {
+		if c.DeletedAt.Valid == deleted {
+			ids = append(ids, id)
+		}
+	}
+	return sortIDs(ids)
+}
+
+// category returns category with matching id
+// unless it doesn't exist or is in trash.
+func (s *MemoryStore) category(id uint) *models.Category {
+	c, ok := s.categories[id]
+	if !ok || c.DeletedAt.Valid {
+		return nil
+	}
+	return &c
+}
+
+// projectCategory loads the parent of c if p expands it
+// and clears the fields p doesn't select.
+func (s *MemoryStore) projectCategory(c *models.Category, p Projection) *models.Category {
+	if p.Expands("parent") && c.ParentID != nil {
+		c.Parent = s.category(*c.ParentID)
+	}
+	p.clearColumns(c, categoryColumns)
+	return c
+}
+
+// saveCategory stores c without its associations.
+func (s *MemoryStore) saveCategory(c models.Category) {
+	c.Parent = nil
+	s.categories[c.ID] = c
+}
+
+// categorySlugTaken reports whether a category other than c,
+// including those in trash, has the slug of c.
+func (s *MemoryStore) categorySlugTaken(c *models.Category) bool {
+	for id, other := range s.categories {
+		if id != c.ID && other.Slug == c.Slug {
+			return true
+		}
+	}
+	return false
+}
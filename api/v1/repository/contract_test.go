@@ -0,0 +1,775 @@
+package repository_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/JonathanGzzBen/ingenialists/api/v1/models"
+	"github.com/JonathanGzzBen/ingenialists/api/v1/repository"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// contract lists the behavior every implementation of
+// the users, articles and categories repositories must have.
+// Each test is given empty repositories of its own.
+var contract = []struct {
+	name string
+	test func(*testing.T, repository.Repositories)
+}{
+	{"CreateUserStartsAtVersionOne", testCreateUserStartsAtVersionOne},
+	{"GetUserNotFound", testGetUserNotFound},
+	{"GetUserByGoogleSub", testGetUserByGoogleSub},
+	{"CreateUserWithTakenGoogleSubFails", testCreateUserWithTakenGoogleSubFails},
+	{"UpdateUserWithStaleVersionConflicts", testUpdateUserWithStaleVersionConflicts},
+	{"GetAllUsersLoadsProjectedFields", testGetAllUsersLoadsProjectedFields},
+	{"CreateCategoryWithTakenSlugFails", testCreateCategoryWithTakenSlugFails},
+	{"GetAllCategoriesSortsAndExpandsParent", testGetAllCategoriesSortsAndExpandsParent},
+	{"UpdateCategoryWithStaleVersionConflicts", testUpdateCategoryWithStaleVersionConflicts},
+	{"DeleteCategoryInUseFails", testDeleteCategoryInUseFails},
+	{"DeleteCategoryReassignsArticles", testDeleteCategoryReassignsArticles},
+	{"DeleteCategoryCascadesToArticles", testDeleteCategoryCascadesToArticles},
+	{"RestoreAndPurgeCategories", testRestoreAndPurgeCategories},
+	{"CreateArticleCreditsCreator", testCreateArticleCreditsCreator},
+	{"CreateArticleScheduledForLaterIsNotPublished", testCreateArticleScheduledForLaterIsNotPublished},
+	{"GetAllArticlesFilters", testGetAllArticlesFilters},
+	{"GetAllArticlesLeavesBodyOut", testGetAllArticlesLeavesBodyOut},
+	{"GetArticleLoadsProjection", testGetArticleLoadsProjection},
+	{"GetCategoryExpandsParent", testGetCategoryExpandsParent},
+	{"UpdateArticleKeepsPreviousSlug", testUpdateArticleKeepsPreviousSlug},
+	{"UpdateArticleWithStaleVersionConflicts", testUpdateArticleWithStaleVersionConflicts},
+	{"DeleteRestoreAndPurgeArticles", testDeleteRestoreAndPurgeArticles},
+	{"PublishDueArticles", testPublishDueArticles},
+	{"SaveAndRemoveArticleAuthor", testSaveAndRemoveArticleAuthor},
+	{"BulkUpdateArticlesIsAllOrNothing", testBulkUpdateArticlesIsAllOrNothing},
+	{"CanceledContextFails", testCanceledContextFails},
+}
+
+func TestGormRepositories(t *testing.T) {
+	for _, c := range contract {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			// Every connection would get a database of its own
+			sqlDB, err := db.DB()
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			sqlDB.SetMaxOpenConns(1)
+			defer sqlDB.Close()
+			c.test(t, repository.Repositories{
+				Users:      repository.NewUsersGormRepository(db),
+				Articles:   repository.NewArticlesGormRepository(db),
+				Categories: repository.NewCategoriesGormRepository(db),
+			})
+		})
+	}
+}
+
+func TestMemoryRepositories(t *testing.T) {
+	for _, c := range contract {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			s := repository.NewMemoryStore()
+			c.test(t, repository.Repositories{
+				Users:      repository.NewUsersMemoryRepository(s),
+				Articles:   repository.NewArticlesMemoryRepository(s),
+				Categories: repository.NewCategoriesMemoryRepository(s),
+			})
+		})
+	}
+}
+
+func createUser(t *testing.T, r repository.Repositories, name string) *models.User {
+	u, err := r.Users.CreateUser(context.Background(), &models.User{Name: name, Role: models.RoleWriter})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return u
+}
+
+func createCategory(t *testing.T, r repository.Repositories, name string, slug string) *models.Category {
+	c, err := r.Categories.CreateCategory(context.Background(), &models.Category{Name: name, Slug: slug})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return c
+}
+
+func createArticle(t *testing.T, r repository.Repositories, a *models.Article) *models.Article {
+	a, err := r.Articles.CreateArticle(context.Background(), a)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return a
+}
+
+// articleIDs returns the IDs of articles in q.
+func articleIDs(t *testing.T, r repository.Repositories, q repository.ArticlesQuery) []uint {
+	articles, err := r.Articles.GetAllArticles(context.Background(), q)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	ids := []uint{}
+	for _, a := range articles {
+		ids = append(ids, a.ID)
+	}
+	return ids
+}
+
+func equalIDs(a []uint, b []uint) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func testCreateUserStartsAtVersionOne(t *testing.T, r repository.Repositories) {
+	u := createUser(t, r, "Jonathan")
+	if u.ID == 0 {
+		t.Fatalf("Expected ID to be set")
+	}
+	got, err := r.Users.GetUser(context.Background(), u.ID, repository.Projection{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got.Name != "Jonathan" || got.Version != 1 {
+		t.Fatalf("Expected %v at version %v, got %v at version %v", "Jonathan", 1, got.Name, got.Version)
+	}
+}
+
+func testGetUserNotFound(t *testing.T, r repository.Repositories) {
+	if _, err := r.Users.GetUser(context.Background(), 42, repository.Projection{}); err != repository.ErrNotFound {
+		t.Fatalf("Expected %v, got %v", repository.ErrNotFound, err)
+	}
+}
+
+func testGetUserByGoogleSub(t *testing.T, r repository.Repositories) {
+	createUser(t, r, "Reader")
+	u, err := r.Users.CreateUser(context.Background(), &models.User{Name: "Jonathan", GoogleSub: "123"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	got, err := r.Users.GetUserByGoogleSub(context.Background(), "123")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got.ID != u.ID {
+		t.Fatalf("Expected %v, got %v", u.ID, got.ID)
+	}
+	if _, err := r.Users.GetUserByGoogleSub(context.Background(), "456"); err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+}
+
+func testCreateUserWithTakenGoogleSubFails(t *testing.T, r repository.Repositories) {
+	// Users that didn't log in with Google can share an empty GoogleSub
+	createUser(t, r, "First")
+	createUser(t, r, "Second")
+	if _, err := r.Users.CreateUser(context.Background(), &models.User{Name: "Third", GoogleSub: "123"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := r.Users.CreateUser(context.Background(), &models.User{Name: "Fourth", GoogleSub: "123"}); err != repository.ErrCouldNotCreate {
+		t.Fatalf("Expected %v, got %v", repository.ErrCouldNotCreate, err)
+	}
+}
+
+func testUpdateUserWithStaleVersionConflicts(t *testing.T, r repository.Repositories) {
+	u := createUser(t, r, "Jonathan")
+	stale := *u
+	u.Name = "Jonathan Gonzalez"
+	u, err := r.Users.UpdateUser(context.Background(), u)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if u.Version != 2 {
+		t.Fatalf("Expected %v, got %v", 2, u.Version)
+	}
+	stale.Name = "Ben"
+	if _, err := r.Users.UpdateUser(context.Background(), &stale); err != repository.ErrVersionConflict {
+		t.Fatalf("Expected %v, got %v", repository.ErrVersionConflict, err)
+	}
+	if stale.Version != 1 {
+		t.Fatalf("Expected version to be left as %v, got %v", 1, stale.Version)
+	}
+	got, err := r.Users.GetUser(context.Background(), u.ID, repository.Projection{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got.Name != "Jonathan Gonzalez" {
+		t.Fatalf("Expected %v, got %v", "Jonathan Gonzalez", got.Name)
+	}
+}
+
+func testGetAllUsersLoadsProjectedFields(t *testing.T, r repository.Repositories) {
+	_, err := r.Users.CreateUser(context.Background(), &models.User{Name: "Jonathan", Description: "Writes about databases"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	users, err := r.Users.GetAllUsers(context.Background(), repository.Projection{Fields: []string{"name"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(users) != 1 {
+		t.Fatalf("Expected %v, got %v", 1, len(users))
+	}
+	if users[0].ID == 0 || users[0].Name != "Jonathan" || users[0].Description != "" {
+		t.Fatalf("Expected only id and name to be loaded, got %+v", users[0])
+	}
+}
+
+func testCreateCategoryWithTakenSlugFails(t *testing.T, r repository.Repositories) {
+	c := createCategory(t, r, "Databases", "databases")
+	if _, err := r.Categories.DeleteCategory(context.Background(), c.ID, 1, repository.CategoryDeletion{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// Slugs of categories in trash stay taken
+	if _, err := r.Categories.CreateCategory(context.Background(), &models.Category{Name: "Databases", Slug: "databases"}); err != repository.ErrSlugTaken {
+		t.Fatalf("Expected %v, got %v", repository.ErrSlugTaken, err)
+	}
+}
+
+func testGetAllCategoriesSortsAndExpandsParent(t *testing.T, r repository.Repositories) {
+	engineering := createCategory(t, r, "Engineering", "engineering")
+	software, err := r.Categories.CreateCategory(context.Background(), &models.Category{Name: "Software", Slug: "software", ParentID: &engineering.ID})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	art, err := r.Categories.CreateCategory(context.Background(), &models.Category{Name: "Art", Slug: "art", SortOrder: 1})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	categories, err := r.Categories.GetAllCategories(context.Background(), repository.Projection{Expand: []string{"parent"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	ids := []uint{}
+	for _, c := range categories {
+		ids = append(ids, c.ID)
+	}
+	if expected := []uint{engineering.ID, software.ID, art.ID}; !equalIDs(ids, expected) {
+		t.Fatalf("Expected %v, got %v", expected, ids)
+	}
+	if categories[0].Parent != nil {
+		t.Fatalf("Expected no parent, got %v", categories[0].Parent)
+	}
+	if categories[1].Parent == nil || categories[1].Parent.ID != engineering.ID {
+		t.Fatalf("Expected parent %v, got %v", engineering.ID, categories[1].Parent)
+	}
+}
+
+func testUpdateCategoryWithStaleVersionConflicts(t *testing.T, r repository.Repositories) {
+	c := createCategory(t, r, "Databases", "databases")
+	stale := *c
+	c.Description = "Storing data"
+	if _, err := r.Categories.UpdateCategory(context.Background(), c); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := r.Categories.UpdateCategory(context.Background(), &stale); err != repository.ErrVersionConflict {
+		t.Fatalf("Expected %v, got %v", repository.ErrVersionConflict, err)
+	}
+}
+
+func testDeleteCategoryInUseFails(t *testing.T, r repository.Repositories) {
+	c := createCategory(t, r, "Databases", "databases")
+	a := createArticle(t, r, &models.Article{UserID: 1, CategoryID: c.ID, Title: "First article"})
+	if _, err := r.Categories.DeleteCategory(context.Background(), c.ID, 1, repository.CategoryDeletion{}); err != repository.ErrCategoryInUse {
+		t.Fatalf("Expected %v, got %v", repository.ErrCategoryInUse, err)
+	}
+	if _, err := r.Categories.GetCategory(context.Background(), c.ID, repository.Projection{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := r.Articles.GetArticle(context.Background(), a.ID, repository.WholeArticle); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
+
+func testDeleteCategoryReassignsArticles(t *testing.T, r repository.Repositories) {
+	c := createCategory(t, r, "Databases", "databases")
+	target := createCategory(t, r, "Software", "software")
+	a := createArticle(t, r, &models.Article{UserID: 1, CategoryID: c.ID, Title: "First article"})
+	if _, err := r.Categories.DeleteCategory(context.Background(), c.ID, 1, repository.CategoryDeletion{ReassignTo: c.ID}); err != repository.ErrNotFound {
+		t.Fatalf("Expected %v, got %v", repository.ErrNotFound, err)
+	}
+	affected, err := r.Categories.DeleteCategory(context.Background(), c.ID, 1, repository.CategoryDeletion{ReassignTo: target.ID})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !equalIDs(affected, []uint{a.ID}) {
+		t.Fatalf("Expected %v, got %v", []uint{a.ID}, affected)
+	}
+	got, err := r.Articles.GetArticle(context.Background(), a.ID, repository.WholeArticle)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got.CategoryID != target.ID || got.Version != 2 {
+		t.Fatalf("Expected category %v at version %v, got %v at version %v", target.ID, 2, got.CategoryID, got.Version)
+	}
+}
+
+func testDeleteCategoryCascadesToArticles(t *testing.T, r repository.Repositories) {
+	c := createCategory(t, r, "Databases", "databases")
+	a := createArticle(t, r, &models.Article{UserID: 1, CategoryID: c.ID, Title: "First article"})
+	if _, err := r.Categories.DeleteCategory(context.Background(), c.ID, 2, repository.CategoryDeletion{Cascade: true}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	deleted, err := r.Articles.GetDeletedArticle(context.Background(), a.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if deleted.DeletedBy == nil || *deleted.DeletedBy != 2 {
+		t.Fatalf("Expected article to be deleted by user %v, got %v", 2, deleted.DeletedBy)
+	}
+	categories, err := r.Categories.GetDeletedCategories(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(categories) != 1 || categories[0].DeletedBy == nil || *categories[0].DeletedBy != 2 {
+		t.Fatalf("Expected category to be deleted by user %v, got %+v", 2, categories)
+	}
+}
+
+func testRestoreAndPurgeCategories(t *testing.T, r repository.Repositories) {
+	c := createCategory(t, r, "Databases", "databases")
+	if _, err := r.Categories.RestoreCategory(context.Background(), c.ID); err != repository.ErrNotFound {
+		t.Fatalf("Expected %v, got %v", repository.ErrNotFound, err)
+	}
+	if _, err := r.Categories.DeleteCategory(context.Background(), c.ID, 1, repository.CategoryDeletion{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := r.Categories.GetCategoryBySlug(context.Background(), "databases", repository.Projection{}); err != repository.ErrNotFound {
+		t.Fatalf("Expected %v, got %v", repository.ErrNotFound, err)
+	}
+	restored, err := r.Categories.RestoreCategory(context.Background(), c.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if restored.DeletedBy != nil {
+		t.Fatalf("Expected no deleter, got %v", *restored.DeletedBy)
+	}
+	if _, err := r.Categories.DeleteCategory(context.Background(), c.ID, 1, repository.CategoryDeletion{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	n, err := r.Categories.PurgeCategories(context.Background(), time.Now().Add(-time.Hour))
+	if err != nil || n != 0 {
+		t.Fatalf("Expected %v purged, got %v, %v", 0, n, err)
+	}
+	n, err = r.Categories.PurgeCategories(context.Background(), time.Now().Add(time.Second))
+	if err != nil || n != 1 {
+		t.Fatalf("Expected %v purged, got %v, %v", 1, n, err)
+	}
+	if _, err := r.Categories.RestoreCategory(context.Background(), c.ID); err != repository.ErrNotFound {
+		t.Fatalf("Expected %v, got %v", repository.ErrNotFound, err)
+	}
+}
+
+func testCreateArticleCreditsCreator(t *testing.T, r repository.Repositories) {
+	u := createUser(t, r, "Jonathan")
+	c := createCategory(t, r, "Databases", "databases")
+	a := createArticle(t, r, &models.Article{UserID: u.ID, CategoryID: c.ID, Title: "First article", Body: "Hello world"})
+	if a.Slug != "first-article" || a.Version != 1 || a.PublishedAt == nil {
+		t.Fatalf("Expected published first-article at version 1, got %+v", a)
+	}
+	if a.WordCount != 2 {
+		t.Fatalf("Expected %v, got %v", 2, a.WordCount)
+	}
+	if a.User == nil || a.User.ID != u.ID || a.Category == nil || a.Category.ID != c.ID {
+		t.Fatalf("Expected user and category to be loaded, got %v and %v", a.User, a.Category)
+	}
+	if len(a.Authors) != 1 || a.Authors[0].UserID != u.ID || a.Authors[0].Role != models.AuthorRoleAuthor {
+		t.Fatalf("Expected creator to be credited, got %+v", a.Authors)
+	}
+	if a.Authors[0].User == nil || a.Authors[0].User.Name != "Jonathan" {
+		t.Fatalf("Expected author's user to be loaded, got %v", a.Authors[0].User)
+	}
+	// Titles already used get numbered slugs
+	second := createArticle(t, r, &models.Article{UserID: u.ID, CategoryID: c.ID, Title: "First article"})
+	if second.Slug != "first-article-2" {
+		t.Fatalf("Expected %v, got %v", "first-article-2", second.Slug)
+	}
+	if _, err := r.Articles.CreateArticle(context.Background(), &models.Article{UserID: u.ID, CategoryID: c.ID, Title: "Other", Slug: "first-article"}); err != repository.ErrCouldNotCreate {
+		t.Fatalf("Expected %v, got %v", repository.ErrCouldNotCreate, err)
+	}
+}
+
+func testCreateArticleScheduledForLaterIsNotPublished(t *testing.T, r repository.Repositories) {
+	later := time.Now().Add(time.Hour)
+	a := createArticle(t, r, &models.Article{UserID: 1, CategoryID: 1, Title: "Scheduled", PublishAt: &later})
+	if a.PublishedAt != nil {
+		t.Fatalf("Expected article not to be published, got %v", a.PublishedAt)
+	}
+}
+
+func testGetAllArticlesFilters(t *testing.T, r repository.Repositories) {
+	databases := createCategory(t, r, "Databases", "databases")
+	software := createCategory(t, r, "Software", "software")
+	later := time.Now().Add(time.Hour)
+	first := createArticle(t, r, &models.Article{UserID: 1, CategoryID: databases.ID, Title: "First"})
+	second := createArticle(t, r, &models.Article{UserID: 2, CategoryID: software.ID, Title: "Second"})
+	scheduled := createArticle(t, r, &models.Article{UserID: 1, CategoryID: software.ID, Title: "Scheduled", PublishAt: &later})
+	archived := createArticle(t, r, &models.Article{UserID: 2, CategoryID: databases.ID, Title: "Archived"})
+	if _, err := r.Articles.BulkUpdateArticles(context.Background(), []uint{archived.ID}, repository.BulkOperation{Archive: true}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := r.Articles.SaveArticleAuthor(context.Background(), &models.ArticleAuthor{ArticleID: second.ID, UserID: 3, Role: models.AuthorRoleEditor}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	trashed := createArticle(t, r, &models.Article{UserID: 1, CategoryID: databases.ID, Title: "Trashed"})
+	if err := r.Articles.DeleteArticle(context.Background(), trashed.ID, 1); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for _, c := range []struct {
+		name     string
+		query    repository.ArticlesQuery
+		expected []uint
+	}{
+		{"All", repository.ArticlesQuery{}, []uint{first.ID, second.ID, scheduled.ID}},
+		{"Category", repository.ArticlesQuery{CategoryIDs: []uint{databases.ID}}, []uint{first.ID}},
+		{"Published", repository.ArticlesQuery{Published: true}, []uint{first.ID, second.ID}},
+		{"Scheduled", repository.ArticlesQuery{Scheduled: true}, []uint{scheduled.ID}},
+		{"Archived", repository.ArticlesQuery{Archived: true}, []uint{archived.ID}},
+		{"User", repository.ArticlesQuery{UserID: 1}, []uint{first.ID, scheduled.ID}},
+		{"Author", repository.ArticlesQuery{AuthorID: 3}, []uint{second.ID}},
+	} {
+		if ids := articleIDs(t, r, c.query); !equalIDs(ids, c.expected) {
+			t.Fatalf("%s: expected %v, got %v", c.name, c.expected, ids)
+		}
+	}
+}
+
+func testGetAllArticlesLeavesBodyOut(t *testing.T, r repository.Repositories) {
+	c := createCategory(t, r, "Databases", "databases")
+	createArticle(t, r, &models.Article{UserID: 1, CategoryID: c.ID, Title: "First article", Body: "Hello world"})
+
+	articles, err := r.Articles.GetAllArticles(context.Background(), repository.ArticlesQuery{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(articles) != 1 || articles[0].Body != "" || articles[0].Title != "First article" {
+		t.Fatalf("Expected article without body, got %+v", articles)
+	}
+	if articles[0].Category != nil || articles[0].Authors != nil {
+		t.Fatalf("Expected no associations to be loaded, got %+v", articles[0])
+	}
+
+	articles, err = r.Articles.GetAllArticles(context.Background(), repository.ArticlesQuery{
+		Projection: repository.Projection{Fields: []string{"body"}, Expand: []string{"category"}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(articles) != 1 || articles[0].Body != "Hello world" || articles[0].Title != "" {
+		t.Fatalf("Expected article with body only, got %+v", articles)
+	}
+	if articles[0].Category == nil || articles[0].Category.ID != c.ID {
+		t.Fatalf("Expected category %v, got %v", c.ID, articles[0].Category)
+	}
+}
+
+func testGetArticleLoadsProjection(t *testing.T, r repository.Repositories) {
+	c := createCategory(t, r, "Databases", "databases")
+	a := createArticle(t, r, &models.Article{UserID: 1, CategoryID: c.ID, Title: "First article", Body: "Hello world"})
+
+	got, err := r.Articles.GetArticle(context.Background(), a.ID, repository.Projection{Fields: []string{"title"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got.Title != "First article" || got.Body != "" || got.Version != a.Version {
+		t.Fatalf("Expected only title and required fields to be loaded, got %+v", got)
+	}
+	if got.User != nil || got.Category != nil || got.Authors != nil {
+		t.Fatalf("Expected no associations to be loaded, got %+v", got)
+	}
+
+	got, err = r.Articles.GetArticleBySlug(context.Background(), a.Slug, repository.Projection{Expand: []string{"category"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got.Body != "Hello world" || got.Authors != nil {
+		t.Fatalf("Expected every field and no authors to be loaded, got %+v", got)
+	}
+	if got.Category == nil || got.Category.ID != c.ID {
+		t.Fatalf("Expected category %v, got %v", c.ID, got.Category)
+	}
+}
+
+func testGetCategoryExpandsParent(t *testing.T, r repository.Repositories) {
+	engineering := createCategory(t, r, "Engineering", "engineering")
+	software, err := r.Categories.CreateCategory(context.Background(), &models.Category{Name: "Software", Slug: "software", ParentID: &engineering.ID})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	got, err := r.Categories.GetCategory(context.Background(), software.ID, repository.Projection{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got.Parent != nil {
+		t.Fatalf("Expected parent not to be loaded, got %+v", got.Parent)
+	}
+	got, err = r.Categories.GetCategoryBySlug(context.Background(), "software", repository.Projection{Fields: []string{"name"}, Expand: []string{"parent"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got.Name != "Software" || got.Slug != "" {
+		t.Fatalf("Expected only name and required fields to be loaded, got %+v", got)
+	}
+	if got.Parent == nil || got.Parent.ID != engineering.ID {
+		t.Fatalf("Expected parent %v, got %v", engineering.ID, got.Parent)
+	}
+}
+
+func testUpdateArticleKeepsPreviousSlug(t *testing.T, r repository.Repositories) {
+	a := createArticle(t, r, &models.Article{UserID: 1, CategoryID: 1, Title: "First article"})
+	a.Title = "Renamed article"
+	a, err := r.Articles.UpdateArticle(context.Background(), a)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if a.Slug != "renamed-article" || a.Version != 2 {
+		t.Fatalf("Expected renamed-article at version 2, got %v at version %v", a.Slug, a.Version)
+	}
+	got, err := r.Articles.GetArticleBySlug(context.Background(), "first-article", repository.WholeArticle)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got.ID != a.ID {
+		t.Fatalf("Expected %v, got %v", a.ID, got.ID)
+	}
+	// The previous slug isn't given to other articles
+	other := createArticle(t, r, &models.Article{UserID: 1, CategoryID: 1, Title: "First article"})
+	if other.Slug != "first-article-2" {
+		t.Fatalf("Expected %v, got %v", "first-article-2", other.Slug)
+	}
+	// but comes back when renamed to its previous title
+	a.Title = "First article"
+	a, err = r.Articles.UpdateArticle(context.Background(), a)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if a.Slug != "first-article" {
+		t.Fatalf("Expected %v, got %v", "first-article", a.Slug)
+	}
+	got, err = r.Articles.GetArticleBySlug(context.Background(), "renamed-article", repository.WholeArticle)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got.ID != a.ID {
+		t.Fatalf("Expected %v, got %v", a.ID, got.ID)
+	}
+	if _, err := r.Articles.GetArticleBySlug(context.Background(), "unknown", repository.WholeArticle); err != repository.ErrNotFound {
+		t.Fatalf("Expected %v, got %v", repository.ErrNotFound, err)
+	}
+}
+
+func testUpdateArticleWithStaleVersionConflicts(t *testing.T, r repository.Repositories) {
+	a := createArticle(t, r, &models.Article{UserID: 1, CategoryID: 1, Title: "First article"})
+	stale := *a
+	a.Body = "Hello world"
+	if _, err := r.Articles.UpdateArticle(context.Background(), a); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	stale.Body = "Goodbye"
+	if _, err := r.Articles.UpdateArticle(context.Background(), &stale); err != repository.ErrVersionConflict {
+		t.Fatalf("Expected %v, got %v", repository.ErrVersionConflict, err)
+	}
+	got, err := r.Articles.GetArticle(context.Background(), a.ID, repository.WholeArticle)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got.Body != "Hello world" {
+		t.Fatalf("Expected %v, got %v", "Hello world", got.Body)
+	}
+}
+
+func testDeleteRestoreAndPurgeArticles(t *testing.T, r repository.Repositories) {
+	a := createArticle(t, r, &models.Article{UserID: 1, CategoryID: 1, Title: "First article"})
+	if err := r.Articles.DeleteArticle(context.Background(), a.ID, 1); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := r.Articles.DeleteArticle(context.Background(), a.ID, 1); err != repository.ErrNotFound {
+		t.Fatalf("Expected %v, got %v", repository.ErrNotFound, err)
+	}
+	if _, err := r.Articles.GetArticle(context.Background(), a.ID, repository.WholeArticle); err != repository.ErrNotFound {
+		t.Fatalf("Expected %v, got %v", repository.ErrNotFound, err)
+	}
+	if _, err := r.Articles.GetArticleBySlug(context.Background(), a.Slug, repository.WholeArticle); err != repository.ErrNotFound {
+		t.Fatalf("Expected %v, got %v", repository.ErrNotFound, err)
+	}
+	restored, err := r.Articles.RestoreArticle(context.Background(), a.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if restored.DeletedBy != nil {
+		t.Fatalf("Expected no deleter, got %v", *restored.DeletedBy)
+	}
+	if err := r.Articles.DeleteArticle(context.Background(), a.ID, 1); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	deleted, err := r.Articles.GetDeletedArticles(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(deleted) != 1 || len(deleted[0].Authors) != 1 {
+		t.Fatalf("Expected article in trash with its authors, got %+v", deleted)
+	}
+	n, err := r.Articles.PurgeArticles(context.Background(), time.Now().Add(-time.Hour))
+	if err != nil || n != 0 {
+		t.Fatalf("Expected %v purged, got %v, %v", 0, n, err)
+	}
+	n, err = r.Articles.PurgeArticles(context.Background(), time.Now().Add(time.Second))
+	if err != nil || n != 1 {
+		t.Fatalf("Expected %v purged, got %v, %v", 1, n, err)
+	}
+	if _, err := r.Articles.GetDeletedArticle(context.Background(), a.ID); err != repository.ErrNotFound {
+		t.Fatalf("Expected %v, got %v", repository.ErrNotFound, err)
+	}
+	// An article reusing the ID isn't credited to the authors of the purged one
+	reused := createArticle(t, r, &models.Article{ID: a.ID, UserID: 2, CategoryID: 1, Title: "Second article"})
+	if len(reused.Authors) != 1 || reused.Authors[0].UserID != 2 {
+		t.Fatalf("Expected article to be credited only to user %v, got %+v", 2, reused.Authors)
+	}
+}
+
+func testPublishDueArticles(t *testing.T, r repository.Repositories) {
+	soon := time.Now().Add(time.Minute)
+	later := time.Now().Add(time.Hour)
+	due := createArticle(t, r, &models.Article{UserID: 1, CategoryID: 1, Title: "Due", PublishAt: &soon})
+	createArticle(t, r, &models.Article{UserID: 1, CategoryID: 1, Title: "Later", PublishAt: &later})
+
+	n, err := r.Articles.PublishDueArticles(context.Background(), time.Now().Add(2*time.Minute))
+	if err != nil || n != 1 {
+		t.Fatalf("Expected %v published, got %v, %v", 1, n, err)
+	}
+	got, err := r.Articles.GetArticle(context.Background(), due.ID, repository.WholeArticle)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got.PublishedAt == nil || !got.PublishedAt.Equal(soon) || got.Version != 2 {
+		t.Fatalf("Expected published at %v at version 2, got %v at version %v", soon, got.PublishedAt, got.Version)
+	}
+}
+
+func testSaveAndRemoveArticleAuthor(t *testing.T, r repository.Repositories) {
+	creator := createUser(t, r, "Ben")
+	u := createUser(t, r, "Jonathan")
+	a := createArticle(t, r, &models.Article{UserID: creator.ID, CategoryID: 1, Title: "First article"})
+	aa, err := r.Articles.SaveArticleAuthor(context.Background(), &models.ArticleAuthor{ArticleID: a.ID, UserID: u.ID, Role: models.AuthorRoleContributor})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if aa.User == nil || aa.User.Name != "Jonathan" {
+		t.Fatalf("Expected author's user to be loaded, got %v", aa.User)
+	}
+	if _, err := r.Articles.SaveArticleAuthor(context.Background(), &models.ArticleAuthor{ArticleID: a.ID, UserID: u.ID, Role: models.AuthorRoleEditor}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	got, err := r.Articles.GetArticle(context.Background(), a.ID, repository.WholeArticle)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(got.Authors) != 2 || got.Authors[1].Role != models.AuthorRoleEditor {
+		t.Fatalf("Expected role to be replaced, got %+v", got.Authors)
+	}
+	if err := r.Articles.RemoveArticleAuthor(context.Background(), a.ID, u.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := r.Articles.RemoveArticleAuthor(context.Background(), a.ID, u.ID); err != repository.ErrNotFound {
+		t.Fatalf("Expected %v, got %v", repository.ErrNotFound, err)
+	}
+}
+
+func testBulkUpdateArticlesIsAllOrNothing(t *testing.T, r repository.Repositories) {
+	first := createArticle(t, r, &models.Article{UserID: 1, CategoryID: 1, Title: "First", Tags: "go"})
+	second := createArticle(t, r, &models.Article{UserID: 1, CategoryID: 1, Title: "Second", Tags: "databases"})
+	op := repository.BulkOperation{CategoryID: 2, AddTags: []string{"databases"}}
+	results, err := r.Articles.BulkUpdateArticles(context.Background(), []uint{first.ID, 42}, op)
+	if err != repository.ErrNotFound {
+		t.Fatalf("Expected %v, got %v", repository.ErrNotFound, err)
+	}
+	expected := []repository.BulkResult{{ArticleID: first.ID, Found: true, Changed: true}, {ArticleID: 42}}
+	if !reflect.DeepEqual(results, expected) {
+		t.Fatalf("Expected %v, got %v", expected, results)
+	}
+	got, err := r.Articles.GetArticle(context.Background(), first.ID, repository.WholeArticle)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got.CategoryID != 1 || got.Tags != "go" || got.Version != 1 {
+		t.Fatalf("Expected article to be unchanged, got %+v", got)
+	}
+
+	op.DryRun = true
+	if _, err := r.Articles.BulkUpdateArticles(context.Background(), []uint{first.ID}, op); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	got, err = r.Articles.GetArticle(context.Background(), first.ID, repository.WholeArticle)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got.CategoryID != 1 || got.Version != 1 {
+		t.Fatalf("Expected dry run to leave article unchanged, got %+v", got)
+	}
+
+	op = repository.BulkOperation{AddTags: []string{"databases"}}
+	results, err = r.Articles.BulkUpdateArticles(context.Background(), []uint{first.ID, second.ID}, op)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected = []repository.BulkResult{{ArticleID: first.ID, Found: true, Changed: true}, {ArticleID: second.ID, Found: true}}
+	if !reflect.DeepEqual(results, expected) {
+		t.Fatalf("Expected %v, got %v", expected, results)
+	}
+	got, err = r.Articles.GetArticle(context.Background(), first.ID, repository.WholeArticle)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got.Tags != "go,databases" || got.Version != 2 {
+		t.Fatalf("Expected article to be changed, got %+v", got)
+	}
+	got, err = r.Articles.GetArticle(context.Background(), second.ID, repository.WholeArticle)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got.Version != 1 {
+		t.Fatalf("Expected unchanged article to keep version %v, got %v", 1, got.Version)
+	}
+
+	if _, err := r.Articles.BulkUpdateArticles(context.Background(), []uint{second.ID}, repository.BulkOperation{Delete: true, DeletedBy: 3}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	deleted, err := r.Articles.GetDeletedArticle(context.Background(), second.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if deleted.DeletedBy == nil || *deleted.DeletedBy != 3 {
+		t.Fatalf("Expected article to be deleted by user %v, got %v", 3, deleted.DeletedBy)
+	}
+}
+
+func testCanceledContextFails(t *testing.T, r repository.Repositories) {
+	createArticle(t, r, &models.Article{UserID: 1, CategoryID: 1, Title: "First article"})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := r.Articles.GetAllArticles(ctx, repository.ArticlesQuery{}); err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+	if _, err := r.Users.CreateUser(ctx, &models.User{Name: "Jonathan"}); err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+	if _, err := r.Categories.GetAllCategories(ctx, repository.Projection{}); err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/JonathanGzzBen/ingenialists/api/v1/models"
+	"gorm.io/gorm"
+)
+
+// MemoryStore keeps the records of in-memory repositories.
+// Repositories created from the same store share its records,
+// so articles can load their users and categories.
+//
+// It's safe for concurrent use, records are lost when
+// the process exits.
+type MemoryStore struct {
+	mu           sync.RWMutex
+	users        map[uint]models.User
+	categories   map[uint]models.Category
+	articles     map[uint]models.Article
+	authors      []models.ArticleAuthor
+	articleSlugs []models.ArticleSlug
+	lastIDs      map[string]uint
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		users:      map[uint]models.User{},
+		categories: map[uint]models.Category{},
+		articles:   map[uint]models.Article{},
+		lastIDs:    map[string]uint{},
+	}
+}
+
+// nextID returns the ID of a new record of table,
+// which is id unless it's zero.
+func (s *MemoryStore) nextID(table string, id uint) uint {
+	if id > s.lastIDs[table] {
+		s.lastIDs[table] = id
+	}
+	if id != 0 {
+		return id
+	}
+	s.lastIDs[table]++
+	return s.lastIDs[table]
+}
+
+// sortIDs sorts ids in ascending order, the order
+// records are returned in when no other is specified.
+func sortIDs(ids []uint) []uint {
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// deletedNow returns the DeletedAt of a record moved to trash now.
+func deletedNow() gorm.DeletedAt {
+	return gorm.DeletedAt{Time: time.Now(), Valid: true}
+}
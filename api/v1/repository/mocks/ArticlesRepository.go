@@ -3,8 +3,11 @@
 package mocks
 
 import (
+	context "context"
 	models "github.com/JonathanGzzBen/ingenialists/api/v1/models"
+	repository "github.com/JonathanGzzBen/ingenialists/api/v1/repository"
 	mock "github.com/stretchr/testify/mock"
+	time "time"
 )
 
 // ArticlesRepository is an autogenerated mock type for the ArticlesRepository type
@@ -12,13 +15,36 @@ type ArticlesRepository struct {
 	mock.Mock
 }
 
-// CreateArticle provides a mock function with given fields: _a0
-func (_m *ArticlesRepository) CreateArticle(_a0 *models.Article) (*models.Article, error) {
-	ret := _m.Called(_a0)
+// BulkUpdateArticles provides a mock function with given fields: _a0, _a1, _a2
+func (_m *ArticlesRepository) BulkUpdateArticles(_a0 context.Context, _a1 []uint, _a2 repository.BulkOperation) ([]repository.BulkResult, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 []repository.BulkResult
+	if rf, ok := ret.Get(0).(func(context.Context, []uint, repository.BulkOperation) []repository.BulkResult); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]repository.BulkResult)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, []uint, repository.BulkOperation) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateArticle provides a mock function with given fields: _a0, _a1
+func (_m *ArticlesRepository) CreateArticle(_a0 context.Context, _a1 *models.Article) (*models.Article, error) {
+	ret := _m.Called(_a0, _a1)
 
 	var r0 *models.Article
-	if rf, ok := ret.Get(0).(func(*models.Article) *models.Article); ok {
-		r0 = rf(_a0)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Article) *models.Article); ok {
+		r0 = rf(_a0, _a1)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.Article)
@@ -26,8 +52,8 @@ func (_m *ArticlesRepository) CreateArticle(_a0 *models.Article) (*models.Articl
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(*models.Article) error); ok {
-		r1 = rf(_a0)
+	if rf, ok := ret.Get(1).(func(context.Context, *models.Article) error); ok {
+		r1 = rf(_a0, _a1)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -35,13 +61,13 @@ func (_m *ArticlesRepository) CreateArticle(_a0 *models.Article) (*models.Articl
 	return r0, r1
 }
 
-// DeleteArticle provides a mock function with given fields: _a0
-func (_m *ArticlesRepository) DeleteArticle(_a0 uint) error {
-	ret := _m.Called(_a0)
+// DeleteArticle provides a mock function with given fields: _a0, _a1, _a2
+func (_m *ArticlesRepository) DeleteArticle(_a0 context.Context, _a1 uint, _a2 uint) error {
+	ret := _m.Called(_a0, _a1, _a2)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(uint) error); ok {
-		r0 = rf(_a0)
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint) error); ok {
+		r0 = rf(_a0, _a1, _a2)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -49,13 +75,13 @@ func (_m *ArticlesRepository) DeleteArticle(_a0 uint) error {
 	return r0
 }
 
-// GetAllArticles provides a mock function with given fields:
-func (_m *ArticlesRepository) GetAllArticles() ([]models.Article, error) {
-	ret := _m.Called()
+// GetAllArticles provides a mock function with given fields: _a0, _a1
+func (_m *ArticlesRepository) GetAllArticles(_a0 context.Context, _a1 repository.ArticlesQuery) ([]models.Article, error) {
+	ret := _m.Called(_a0, _a1)
 
 	var r0 []models.Article
-	if rf, ok := ret.Get(0).(func() []models.Article); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(context.Context, repository.ArticlesQuery) []models.Article); ok {
+		r0 = rf(_a0, _a1)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]models.Article)
@@ -63,8 +89,8 @@ func (_m *ArticlesRepository) GetAllArticles() ([]models.Article, error) {
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func() error); ok {
-		r1 = rf()
+	if rf, ok := ret.Get(1).(func(context.Context, repository.ArticlesQuery) error); ok {
+		r1 = rf(_a0, _a1)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -72,13 +98,13 @@ func (_m *ArticlesRepository) GetAllArticles() ([]models.Article, error) {
 	return r0, r1
 }
 
-// GetArticle provides a mock function with given fields: _a0
-func (_m *ArticlesRepository) GetArticle(_a0 uint) (*models.Article, error) {
-	ret := _m.Called(_a0)
+// GetArticle provides a mock function with given fields: _a0, _a1, _a2
+func (_m *ArticlesRepository) GetArticle(_a0 context.Context, _a1 uint, _a2 repository.Projection) (*models.Article, error) {
+	ret := _m.Called(_a0, _a1, _a2)
 
 	var r0 *models.Article
-	if rf, ok := ret.Get(0).(func(uint) *models.Article); ok {
-		r0 = rf(_a0)
+	if rf, ok := ret.Get(0).(func(context.Context, uint, repository.Projection) *models.Article); ok {
+		r0 = rf(_a0, _a1, _a2)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.Article)
@@ -86,8 +112,8 @@ func (_m *ArticlesRepository) GetArticle(_a0 uint) (*models.Article, error) {
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(uint) error); ok {
-		r1 = rf(_a0)
+	if rf, ok := ret.Get(1).(func(context.Context, uint, repository.Projection) error); ok {
+		r1 = rf(_a0, _a1, _a2)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -95,13 +121,13 @@ func (_m *ArticlesRepository) GetArticle(_a0 uint) (*models.Article, error) {
 	return r0, r1
 }
 
-// UpdateArticle provides a mock function with given fields: _a0
-func (_m *ArticlesRepository) UpdateArticle(_a0 *models.Article) (*models.Article, error) {
-	ret := _m.Called(_a0)
+// GetArticleBySlug provides a mock function with given fields: _a0, _a1, _a2
+func (_m *ArticlesRepository) GetArticleBySlug(_a0 context.Context, _a1 string, _a2 repository.Projection) (*models.Article, error) {
+	ret := _m.Called(_a0, _a1, _a2)
 
 	var r0 *models.Article
-	if rf, ok := ret.Get(0).(func(*models.Article) *models.Article); ok {
-		r0 = rf(_a0)
+	if rf, ok := ret.Get(0).(func(context.Context, string, repository.Projection) *models.Article); ok {
+		r0 = rf(_a0, _a1, _a2)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.Article)
@@ -109,7 +135,53 @@ func (_m *ArticlesRepository) UpdateArticle(_a0 *models.Article) (*models.Articl
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(*models.Article) error); ok {
+	if rf, ok := ret.Get(1).(func(context.Context, string, repository.Projection) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetDeletedArticle provides a mock function with given fields: _a0, _a1
+func (_m *ArticlesRepository) GetDeletedArticle(_a0 context.Context, _a1 uint) (*models.Article, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *models.Article
+	if rf, ok := ret.Get(0).(func(context.Context, uint) *models.Article); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Article)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetDeletedArticles provides a mock function with given fields: _a0
+func (_m *ArticlesRepository) GetDeletedArticles(_a0 context.Context) ([]models.Article, error) {
+	ret := _m.Called(_a0)
+
+	var r0 []models.Article
+	if rf, ok := ret.Get(0).(func(context.Context) []models.Article); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Article)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
 		r1 = rf(_a0)
 	} else {
 		r1 = ret.Error(1)
@@ -117,3 +189,128 @@ func (_m *ArticlesRepository) UpdateArticle(_a0 *models.Article) (*models.Articl
 
 	return r0, r1
 }
+
+// PublishDueArticles provides a mock function with given fields: _a0, _a1
+func (_m *ArticlesRepository) PublishDueArticles(_a0 context.Context, _a1 time.Time) (int64, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int64); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PurgeArticles provides a mock function with given fields: _a0, _a1
+func (_m *ArticlesRepository) PurgeArticles(_a0 context.Context, _a1 time.Time) (int64, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int64); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RemoveArticleAuthor provides a mock function with given fields: _a0, _a1, _a2
+func (_m *ArticlesRepository) RemoveArticleAuthor(_a0 context.Context, _a1 uint, _a2 uint) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RestoreArticle provides a mock function with given fields: _a0, _a1
+func (_m *ArticlesRepository) RestoreArticle(_a0 context.Context, _a1 uint) (*models.Article, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *models.Article
+	if rf, ok := ret.Get(0).(func(context.Context, uint) *models.Article); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Article)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveArticleAuthor provides a mock function with given fields: _a0, _a1
+func (_m *ArticlesRepository) SaveArticleAuthor(_a0 context.Context, _a1 *models.ArticleAuthor) (*models.ArticleAuthor, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *models.ArticleAuthor
+	if rf, ok := ret.Get(0).(func(context.Context, *models.ArticleAuthor) *models.ArticleAuthor); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.ArticleAuthor)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *models.ArticleAuthor) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateArticle provides a mock function with given fields: _a0, _a1
+func (_m *ArticlesRepository) UpdateArticle(_a0 context.Context, _a1 *models.Article) (*models.Article, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *models.Article
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Article) *models.Article); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Article)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *models.Article) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
@@ -3,8 +3,11 @@
 package mocks
 
 import (
+	context "context"
 	models "github.com/JonathanGzzBen/ingenialists/api/v1/models"
+	repository "github.com/JonathanGzzBen/ingenialists/api/v1/repository"
 	mock "github.com/stretchr/testify/mock"
+	time "time"
 )
 
 // CategoriesRepository is an autogenerated mock type for the CategoriesRepository type
@@ -12,13 +15,13 @@ type CategoriesRepository struct {
 	mock.Mock
 }
 
-// CreateCategory provides a mock function with given fields: _a0
-func (_m *CategoriesRepository) CreateCategory(_a0 *models.Category) (*models.Category, error) {
-	ret := _m.Called(_a0)
+// CreateCategory provides a mock function with given fields: _a0, _a1
+func (_m *CategoriesRepository) CreateCategory(_a0 context.Context, _a1 *models.Category) (*models.Category, error) {
+	ret := _m.Called(_a0, _a1)
 
 	var r0 *models.Category
-	if rf, ok := ret.Get(0).(func(*models.Category) *models.Category); ok {
-		r0 = rf(_a0)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Category) *models.Category); ok {
+		r0 = rf(_a0, _a1)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.Category)
@@ -26,8 +29,8 @@ func (_m *CategoriesRepository) CreateCategory(_a0 *models.Category) (*models.Ca
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(*models.Category) error); ok {
-		r1 = rf(_a0)
+	if rf, ok := ret.Get(1).(func(context.Context, *models.Category) error); ok {
+		r1 = rf(_a0, _a1)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -35,27 +38,36 @@ func (_m *CategoriesRepository) CreateCategory(_a0 *models.Category) (*models.Ca
 	return r0, r1
 }
 
-// DeleteCategory provides a mock function with given fields: _a0
-func (_m *CategoriesRepository) DeleteCategory(_a0 uint) error {
-	ret := _m.Called(_a0)
+// DeleteCategory provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *CategoriesRepository) DeleteCategory(_a0 context.Context, _a1 uint, _a2 uint, _a3 repository.CategoryDeletion) ([]uint, error) {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(uint) error); ok {
-		r0 = rf(_a0)
+	var r0 []uint
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint, repository.CategoryDeletion) []uint); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]uint)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint, uint, repository.CategoryDeletion) error); ok {
+		r1 = rf(_a0, _a1, _a2, _a3)
 	} else {
-		r0 = ret.Error(0)
+		r1 = ret.Error(1)
 	}
 
-	return r0
+	return r0, r1
 }
 
-// GetAllCategories provides a mock function with given fields:
-func (_m *CategoriesRepository) GetAllCategories() ([]models.Category, error) {
-	ret := _m.Called()
+// GetAllCategories provides a mock function with given fields: _a0, _a1
+func (_m *CategoriesRepository) GetAllCategories(_a0 context.Context, _a1 repository.Projection) ([]models.Category, error) {
+	ret := _m.Called(_a0, _a1)
 
 	var r0 []models.Category
-	if rf, ok := ret.Get(0).(func() []models.Category); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(context.Context, repository.Projection) []models.Category); ok {
+		r0 = rf(_a0, _a1)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]models.Category)
@@ -63,8 +75,8 @@ func (_m *CategoriesRepository) GetAllCategories() ([]models.Category, error) {
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func() error); ok {
-		r1 = rf()
+	if rf, ok := ret.Get(1).(func(context.Context, repository.Projection) error); ok {
+		r1 = rf(_a0, _a1)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -72,13 +84,13 @@ func (_m *CategoriesRepository) GetAllCategories() ([]models.Category, error) {
 	return r0, r1
 }
 
-// GetCategory provides a mock function with given fields: _a0
-func (_m *CategoriesRepository) GetCategory(_a0 uint) (*models.Category, error) {
-	ret := _m.Called(_a0)
+// GetCategory provides a mock function with given fields: _a0, _a1, _a2
+func (_m *CategoriesRepository) GetCategory(_a0 context.Context, _a1 uint, _a2 repository.Projection) (*models.Category, error) {
+	ret := _m.Called(_a0, _a1, _a2)
 
 	var r0 *models.Category
-	if rf, ok := ret.Get(0).(func(uint) *models.Category); ok {
-		r0 = rf(_a0)
+	if rf, ok := ret.Get(0).(func(context.Context, uint, repository.Projection) *models.Category); ok {
+		r0 = rf(_a0, _a1, _a2)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.Category)
@@ -86,8 +98,8 @@ func (_m *CategoriesRepository) GetCategory(_a0 uint) (*models.Category, error)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(uint) error); ok {
-		r1 = rf(_a0)
+	if rf, ok := ret.Get(1).(func(context.Context, uint, repository.Projection) error); ok {
+		r1 = rf(_a0, _a1, _a2)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -95,13 +107,13 @@ func (_m *CategoriesRepository) GetCategory(_a0 uint) (*models.Category, error)
 	return r0, r1
 }
 
-// UpdateCategory provides a mock function with given fields: _a0
-func (_m *CategoriesRepository) UpdateCategory(_a0 *models.Category) (*models.Category, error) {
-	ret := _m.Called(_a0)
+// GetCategoryBySlug provides a mock function with given fields: _a0, _a1, _a2
+func (_m *CategoriesRepository) GetCategoryBySlug(_a0 context.Context, _a1 string, _a2 repository.Projection) (*models.Category, error) {
+	ret := _m.Called(_a0, _a1, _a2)
 
 	var r0 *models.Category
-	if rf, ok := ret.Get(0).(func(*models.Category) *models.Category); ok {
-		r0 = rf(_a0)
+	if rf, ok := ret.Get(0).(func(context.Context, string, repository.Projection) *models.Category); ok {
+		r0 = rf(_a0, _a1, _a2)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.Category)
@@ -109,7 +121,30 @@ func (_m *CategoriesRepository) UpdateCategory(_a0 *models.Category) (*models.Ca
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(*models.Category) error); ok {
+	if rf, ok := ret.Get(1).(func(context.Context, string, repository.Projection) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetDeletedCategories provides a mock function with given fields: _a0
+func (_m *CategoriesRepository) GetDeletedCategories(_a0 context.Context) ([]models.Category, error) {
+	ret := _m.Called(_a0)
+
+	var r0 []models.Category
+	if rf, ok := ret.Get(0).(func(context.Context) []models.Category); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Category)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
 		r1 = rf(_a0)
 	} else {
 		r1 = ret.Error(1)
@@ -117,3 +152,70 @@ func (_m *CategoriesRepository) UpdateCategory(_a0 *models.Category) (*models.Ca
 
 	return r0, r1
 }
+
+// PurgeCategories provides a mock function with given fields: _a0, _a1
+func (_m *CategoriesRepository) PurgeCategories(_a0 context.Context, _a1 time.Time) (int64, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int64); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RestoreCategory provides a mock function with given fields: _a0, _a1
+func (_m *CategoriesRepository) RestoreCategory(_a0 context.Context, _a1 uint) (*models.Category, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *models.Category
+	if rf, ok := ret.Get(0).(func(context.Context, uint) *models.Category); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Category)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateCategory provides a mock function with given fields: _a0, _a1
+func (_m *CategoriesRepository) UpdateCategory(_a0 context.Context, _a1 *models.Category) (*models.Category, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *models.Category
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Category) *models.Category); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Category)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *models.Category) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
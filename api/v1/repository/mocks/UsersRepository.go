@@ -3,7 +3,9 @@
 package mocks
 
 import (
+	context "context"
 	models "github.com/JonathanGzzBen/ingenialists/api/v1/models"
+	repository "github.com/JonathanGzzBen/ingenialists/api/v1/repository"
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -12,13 +14,13 @@ type UsersRepository struct {
 	mock.Mock
 }
 
-// CreateUser provides a mock function with given fields: _a0
-func (_m *UsersRepository) CreateUser(_a0 *models.User) (*models.User, error) {
-	ret := _m.Called(_a0)
+// CreateUser provides a mock function with given fields: _a0, _a1
+func (_m *UsersRepository) CreateUser(_a0 context.Context, _a1 *models.User) (*models.User, error) {
+	ret := _m.Called(_a0, _a1)
 
 	var r0 *models.User
-	if rf, ok := ret.Get(0).(func(*models.User) *models.User); ok {
-		r0 = rf(_a0)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.User) *models.User); ok {
+		r0 = rf(_a0, _a1)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.User)
@@ -26,8 +28,8 @@ func (_m *UsersRepository) CreateUser(_a0 *models.User) (*models.User, error) {
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(*models.User) error); ok {
-		r1 = rf(_a0)
+	if rf, ok := ret.Get(1).(func(context.Context, *models.User) error); ok {
+		r1 = rf(_a0, _a1)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -35,13 +37,13 @@ func (_m *UsersRepository) CreateUser(_a0 *models.User) (*models.User, error) {
 	return r0, r1
 }
 
-// GetAllUsers provides a mock function with given fields:
-func (_m *UsersRepository) GetAllUsers() ([]models.User, error) {
-	ret := _m.Called()
+// GetAllUsers provides a mock function with given fields: _a0, _a1
+func (_m *UsersRepository) GetAllUsers(_a0 context.Context, _a1 repository.Projection) ([]models.User, error) {
+	ret := _m.Called(_a0, _a1)
 
 	var r0 []models.User
-	if rf, ok := ret.Get(0).(func() []models.User); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(context.Context, repository.Projection) []models.User); ok {
+		r0 = rf(_a0, _a1)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]models.User)
@@ -49,8 +51,8 @@ func (_m *UsersRepository) GetAllUsers() ([]models.User, error) {
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func() error); ok {
-		r1 = rf()
+	if rf, ok := ret.Get(1).(func(context.Context, repository.Projection) error); ok {
+		r1 = rf(_a0, _a1)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -58,13 +60,13 @@ func (_m *UsersRepository) GetAllUsers() ([]models.User, error) {
 	return r0, r1
 }
 
-// GetUser provides a mock function with given fields: _a0
-func (_m *UsersRepository) GetUser(_a0 uint) (*models.User, error) {
-	ret := _m.Called(_a0)
+// GetUser provides a mock function with given fields: _a0, _a1, _a2
+func (_m *UsersRepository) GetUser(_a0 context.Context, _a1 uint, _a2 repository.Projection) (*models.User, error) {
+	ret := _m.Called(_a0, _a1, _a2)
 
 	var r0 *models.User
-	if rf, ok := ret.Get(0).(func(uint) *models.User); ok {
-		r0 = rf(_a0)
+	if rf, ok := ret.Get(0).(func(context.Context, uint, repository.Projection) *models.User); ok {
+		r0 = rf(_a0, _a1, _a2)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.User)
@@ -72,8 +74,8 @@ func (_m *UsersRepository) GetUser(_a0 uint) (*models.User, error) {
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(uint) error); ok {
-		r1 = rf(_a0)
+	if rf, ok := ret.Get(1).(func(context.Context, uint, repository.Projection) error); ok {
+		r1 = rf(_a0, _a1, _a2)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -81,13 +83,13 @@ func (_m *UsersRepository) GetUser(_a0 uint) (*models.User, error) {
 	return r0, r1
 }
 
-// GetUserByGoogleSub provides a mock function with given fields: _a0
-func (_m *UsersRepository) GetUserByGoogleSub(_a0 string) (*models.User, error) {
-	ret := _m.Called(_a0)
+// GetUserByGoogleSub provides a mock function with given fields: _a0, _a1
+func (_m *UsersRepository) GetUserByGoogleSub(_a0 context.Context, _a1 string) (*models.User, error) {
+	ret := _m.Called(_a0, _a1)
 
 	var r0 *models.User
-	if rf, ok := ret.Get(0).(func(string) *models.User); ok {
-		r0 = rf(_a0)
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.User); ok {
+		r0 = rf(_a0, _a1)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.User)
@@ -95,8 +97,8 @@ func (_m *UsersRepository) GetUserByGoogleSub(_a0 string) (*models.User, error)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(_a0)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(_a0, _a1)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -104,13 +106,13 @@ func (_m *UsersRepository) GetUserByGoogleSub(_a0 string) (*models.User, error)
 	return r0, r1
 }
 
-// UpdateUser provides a mock function with given fields: _a0
-func (_m *UsersRepository) UpdateUser(_a0 *models.User) (*models.User, error) {
-	ret := _m.Called(_a0)
+// UpdateUser provides a mock function with given fields: _a0, _a1
+func (_m *UsersRepository) UpdateUser(_a0 context.Context, _a1 *models.User) (*models.User, error) {
+	ret := _m.Called(_a0, _a1)
 
 	var r0 *models.User
-	if rf, ok := ret.Get(0).(func(*models.User) *models.User); ok {
-		r0 = rf(_a0)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.User) *models.User); ok {
+		r0 = rf(_a0, _a1)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.User)
@@ -118,8 +120,8 @@ func (_m *UsersRepository) UpdateUser(_a0 *models.User) (*models.User, error) {
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(*models.User) error); ok {
-		r1 = rf(_a0)
+	if rf, ok := ret.Get(1).(func(context.Context, *models.User) error); ok {
+		r1 = rf(_a0, _a1)
 	} else {
 		r1 = ret.Error(1)
 	}
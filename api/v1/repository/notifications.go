@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/JonathanGzzBen/ingenialists/api/v1/models"
+	"gorm.io/gorm"
+)
+
+type NotificationsRepository interface {
+	GetNotificationsByUser(context.Context, uint) ([]models.Notification, error)
+	GetNotification(context.Context, uint) (*models.Notification, error)
+	CreateNotification(context.Context, *models.Notification) (*models.Notification, error)
+	UpdateNotification(context.Context, *models.Notification) (*models.Notification, error)
+}
+
+type NotificationsGormRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationsGormRepository(db *gorm.DB) *NotificationsGormRepository {
+	db.AutoMigrate(&models.Notification{})
+	return &NotificationsGormRepository{
+		db: db,
+	}
+}
+
+// GetNotificationsByUser returns notifications of user
+// with matching id, newest first.
+func (r *NotificationsGormRepository) GetNotificationsByUser(ctx context.Context, uid uint) ([]models.Notification, error) {
+	var ns []models.Notification
+	res := r.db.WithContext(ctx).Where("user_id = ?", uid).Order("created_at desc").Find(&ns)
+	if res.Error != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	return ns, nil
+}
+
+func (r *NotificationsGormRepository) GetNotification(ctx context.Context, id uint) (*models.Notification, error) {
+	var n *models.Notification
+	res := r.db.WithContext(ctx).Find(&n, id)
+	if res.Error == gorm.ErrRecordNotFound || res.RowsAffected != 1 {
+		return nil, ErrNotFound
+	}
+	if res.Error != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	return n, nil
+}
+
+func (r *NotificationsGormRepository) CreateNotification(ctx context.Context, n *models.Notification) (*models.Notification, error) {
+	res := r.db.WithContext(ctx).Create(n)
+	if res.Error != nil {
+		return nil, ErrCouldNotCreate
+	}
+	return n, nil
+}
+
+func (r *NotificationsGormRepository) UpdateNotification(ctx context.Context, n *models.Notification) (*models.Notification, error) {
+	res := r.db.WithContext(ctx).Save(n)
+	if res.Error != nil {
+		return nil, ErrCouldNotUpdate
+	}
+	return n, nil
+}
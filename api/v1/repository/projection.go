@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Projection selects what is loaded of each record.
+// The zero value loads the default fields of records
+// and none of their associations.
+type Projection struct {
+	// Fields are the JSON names of the fields to load,
+	// default fields are loaded if empty
+	Fields []string
+	// Expand are the JSON names of the associations to load
+	Expand []string
+}
+
+// WholeArticle loads every field and association of an article,
+// like articles that are going to be changed need.
+var WholeArticle = Projection{Expand: []string{"user", "category", "authors"}}
+
+// Required columns of single records, they're needed to identify
+// them, load their associations and tell clients their version.
+var (
+	articleColumns  = []string{"id", "user_id", "category_id", "slug", "published_at", "version", "updated_at"}
+	categoryColumns = []string{"id", "parent_id", "version", "updated_at"}
+	userColumns     = []string{"id", "version", "updated_at"}
+)
+
+// seriesArticleColumns are the columns of the articles of series,
+// enough to link to them and summarize them.
+var seriesArticleColumns = []string{
+	"id", "user_id", "category_id", "title", "slug", "excerpt", "image_url", "tags",
+	"word_count", "reading_time", "published_at", "archived_at", "updated_at", "version",
+}
+
+// key identifies p in cache keys.
+func (p Projection) key() string {
+	return strings.Join(p.Fields, ",") + "/" + strings.Join(p.Expand, ",")
+}
+
+// Expands reports whether p loads association.
+func (p Projection) Expands(association string) bool {
+	for _, e := range p.Expand {
+		if e == association {
+			return true
+		}
+	}
+	return false
+}
+
+var schemas = &sync.Map{}
+
+// selectColumns makes tx load the columns of model for fields in p,
+// along with required columns that are needed to identify
+// records and load their associations. If p has no fields,
+// every column but omitted ones is loaded.
+//
+// Fields without a column, like computed ones, are ignored.
+func (p Projection) selectColumns(tx *gorm.DB, model interface{}, required []string, omitted ...string) *gorm.DB {
+	if len(p.Fields) == 0 {
+		if len(omitted) == 0 {
+			return tx
+		}
+		return tx.Omit(omitted...)
+	}
+	s, err := schema.Parse(model, schemas, tx.NamingStrategy)
+	if err != nil {
+		return tx
+	}
+	return tx.Select(p.columns(s, required))
+}
+
+// columns returns the columns of s for fields in p, along with required ones.
+func (p Projection) columns(s *schema.Schema, required []string) []string {
+	columns := append([]string{}, required...)
+	for _, f := range p.Fields {
+		for _, field := range s.Fields {
+			if field.DBName != "" && strings.Split(field.Tag.Get("json"), ",")[0] == f {
+				columns = append(columns, field.DBName)
+			}
+		}
+	}
+	return columns
+}
+
+// clearColumns zeroes the fields of record, a pointer to a model,
+// whose columns selectColumns wouldn't load for p. It's used by
+// repositories that don't load records from a database.
+func (p Projection) clearColumns(record interface{}, required []string, omitted ...string) {
+	s, err := schema.Parse(record, schemas, schema.NamingStrategy{})
+	if err != nil {
+		return
+	}
+	load := map[string]bool{}
+	if len(p.Fields) == 0 {
+		for _, field := range s.Fields {
+			load[field.DBName] = true
+		}
+		for _, c := range omitted {
+			load[c] = false
+		}
+	} else {
+		for _, c := range p.columns(s, required) {
+			load[c] = true
+		}
+	}
+	v := reflect.ValueOf(record).Elem()
+	for _, field := range s.Fields {
+		if field.DBName != "" && !load[field.DBName] {
+			f := v.FieldByIndex(field.StructField.Index)
+			f.Set(reflect.Zero(f.Type()))
+		}
+	}
+}
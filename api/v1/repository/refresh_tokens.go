@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/JonathanGzzBen/ingenialists/api/v1/models"
+	"gorm.io/gorm"
+)
+
+type RefreshTokensRepository interface {
+	GetRefreshTokenByHash(context.Context, string) (*models.RefreshToken, error)
+	CreateRefreshToken(context.Context, *models.RefreshToken) (*models.RefreshToken, error)
+	RotateRefreshToken(context.Context, uint) error
+	RevokeRefreshTokenFamily(context.Context, string) error
+}
+
+type RefreshTokensGormRepository struct {
+	db *gorm.DB
+}
+
+func NewRefreshTokensGormRepository(db *gorm.DB) *RefreshTokensGormRepository {
+	db.AutoMigrate(&models.RefreshToken{})
+	return &RefreshTokensGormRepository{
+		db: db,
+	}
+}
+
+func (r *RefreshTokensGormRepository) GetRefreshTokenByHash(ctx context.Context, hash string) (*models.RefreshToken, error) {
+	var rt *models.RefreshToken
+	res := r.db.WithContext(ctx).Where("token_hash = ?", hash).Find(&rt)
+	if res.Error != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	if res.RowsAffected != 1 {
+		return nil, ErrNotFound
+	}
+	return rt, nil
+}
+
+func (r *RefreshTokensGormRepository) CreateRefreshToken(ctx context.Context, rt *models.RefreshToken) (*models.RefreshToken, error) {
+	res := r.db.WithContext(ctx).Create(rt)
+	if res.Error != nil {
+		return nil, ErrCouldNotCreate
+	}
+	return rt, nil
+}
+
+// RotateRefreshToken marks the token with id as rotated, unless it
+// was already rotated or revoked, in which case it returns ErrTokenUsed.
+// The check is part of the update, so only one of concurrent
+// rotations of the same token succeeds.
+func (r *RefreshTokensGormRepository) RotateRefreshToken(ctx context.Context, id uint) error {
+	res := r.db.WithContext(ctx).Model(&models.RefreshToken{}).
+		Where("id = ? AND rotated_at IS NULL AND revoked_at IS NULL", id).
+		Update("rotated_at", time.Now())
+	if res.Error != nil {
+		return ErrCouldNotUpdate
+	}
+	if res.RowsAffected != 1 {
+		return ErrTokenUsed
+	}
+	return nil
+}
+
+// RevokeRefreshTokenFamily revokes every token of the family
+// that has not been revoked yet.
+func (r *RefreshTokensGormRepository) RevokeRefreshTokenFamily(ctx context.Context, family string) error {
+	res := r.db.WithContext(ctx).Model(&models.RefreshToken{}).
+		Where("family = ? AND revoked_at IS NULL", family).
+		Update("revoked_at", time.Now())
+	if res.Error != nil {
+		return ErrCouldNotUpdate
+	}
+	return nil
+}
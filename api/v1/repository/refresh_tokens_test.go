@@ -0,0 +1,50 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/JonathanGzzBen/ingenialists/api/v1/models"
+	"github.com/JonathanGzzBen/ingenialists/api/v1/repository"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestRotateRefreshTokenOnlyOnce(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	r := repository.NewRefreshTokensGormRepository(db)
+	ctx := context.Background()
+	rt, err := r.CreateRefreshToken(ctx, &models.RefreshToken{
+		UserID:    1,
+		Family:    "family",
+		TokenHash: "hash",
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if err := r.RotateRefreshToken(ctx, rt.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// A request that read the token before it was rotated
+	// must not be able to rotate it again
+	if err := r.RotateRefreshToken(ctx, rt.ID); err != repository.ErrTokenUsed {
+		t.Fatalf("Expected %v, got %v", repository.ErrTokenUsed, err)
+	}
+
+	if err := r.RevokeRefreshTokenFamily(ctx, "family"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	stored, err := r.GetRefreshTokenByHash(ctx, "hash")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if stored.RotatedAt == nil || stored.RevokedAt == nil {
+		t.Fatalf("Expected token to be rotated and revoked")
+	}
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/JonathanGzzBen/ingenialists/api/v1/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RoleRequestsRepository interface {
+	GetAllRoleRequests(context.Context, models.RoleRequestStatus) ([]models.RoleRequest, error)
+	GetRoleRequestsByUser(context.Context, uint) ([]models.RoleRequest, error)
+	GetRoleRequest(context.Context, uint) (*models.RoleRequest, error)
+	CreateRoleRequest(context.Context, *models.RoleRequest) (*models.RoleRequest, error)
+	ReviewRoleRequest(context.Context, *models.RoleRequest) (*models.RoleRequest, error)
+}
+
+type RoleRequestsGormRepository struct {
+	db *gorm.DB
+}
+
+func NewRoleRequestsGormRepository(db *gorm.DB) *RoleRequestsGormRepository {
+	db.AutoMigrate(&models.RoleRequest{})
+	return &RoleRequestsGormRepository{
+		db: db,
+	}
+}
+
+// GetAllRoleRequests returns role requests with matching status,
+// or all of them if status is empty.
+func (r *RoleRequestsGormRepository) GetAllRoleRequests(ctx context.Context, status models.RoleRequestStatus) ([]models.RoleRequest, error) {
+	var rrs []models.RoleRequest
+	q := r.db.WithContext(ctx).Preload(clause.Associations)
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	res := q.Find(&rrs)
+	if res.Error != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	return rrs, nil
+}
+
+func (r *RoleRequestsGormRepository) GetRoleRequestsByUser(ctx context.Context, uid uint) ([]models.RoleRequest, error) {
+	var rrs []models.RoleRequest
+	res := r.db.WithContext(ctx).Preload(clause.Associations).Where("user_id = ?", uid).Find(&rrs)
+	if res.Error != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	return rrs, nil
+}
+
+func (r *RoleRequestsGormRepository) GetRoleRequest(ctx context.Context, id uint) (*models.RoleRequest, error) {
+	var rr *models.RoleRequest
+	res := r.db.WithContext(ctx).Preload(clause.Associations).Find(&rr, id)
+	if res.Error == gorm.ErrRecordNotFound || res.RowsAffected != 1 {
+		return nil, ErrNotFound
+	}
+	if res.Error != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	return rr, nil
+}
+
+func (r *RoleRequestsGormRepository) CreateRoleRequest(ctx context.Context, rr *models.RoleRequest) (*models.RoleRequest, error) {
+	res := r.db.WithContext(ctx).Omit(clause.Associations).Create(rr)
+	if res.Error != nil {
+		return nil, ErrCouldNotCreate
+	}
+	return r.GetRoleRequest(ctx, rr.ID)
+}
+
+// ReviewRoleRequest saves the status, reviewer and review note
+// of rr if it's still pending, and returns ErrAlreadyReviewed otherwise,
+// so that a role request is only reviewed once.
+func (r *RoleRequestsGormRepository) ReviewRoleRequest(ctx context.Context, rr *models.RoleRequest) (*models.RoleRequest, error) {
+	res := r.db.WithContext(ctx).Model(&models.RoleRequest{}).
+		Where("id = ? AND status = ?", rr.ID, models.RoleRequestPending).
+		Updates(map[string]interface{}{
+			"status":      rr.Status,
+			"reviewer_id": rr.ReviewerID,
+			"review_note": rr.ReviewNote,
+		})
+	if res.Error != nil {
+		return nil, ErrCouldNotUpdate
+	}
+	if res.RowsAffected != 1 {
+		return nil, ErrAlreadyReviewed
+	}
+	return r.GetRoleRequest(ctx, rr.ID)
+}
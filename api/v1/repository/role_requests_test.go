@@ -0,0 +1,43 @@
+package repository_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/JonathanGzzBen/ingenialists/api/v1/models"
+	"github.com/JonathanGzzBen/ingenialists/api/v1/repository"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestReviewRoleRequestOnlyOnce(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	r := repository.NewRoleRequestsGormRepository(db)
+	rr, err := r.CreateRoleRequest(context.Background(), &models.RoleRequest{UserID: 2, Role: models.RoleWriter, Status: models.RoleRequestPending})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Both reviews read the request while it was pending
+	approval, rejection := *rr, *rr
+	approval.Status = models.RoleRequestApproved
+	rejection.Status = models.RoleRequestRejected
+	if _, err := r.ReviewRoleRequest(context.Background(), &approval); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := r.ReviewRoleRequest(context.Background(), &rejection); err != repository.ErrAlreadyReviewed {
+		t.Fatalf("Expected %v, got %v", repository.ErrAlreadyReviewed, err)
+	}
+
+	rr, err = r.GetRoleRequest(context.Background(), rr.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if rr.Status != models.RoleRequestApproved {
+		t.Fatalf("Expected %v, got %v", models.RoleRequestApproved, rr.Status)
+	}
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/JonathanGzzBen/ingenialists/api/v1/models"
+	"gorm.io/gorm"
+)
+
+type RolesRepository interface {
+	GetAllRoles(context.Context) ([]models.RoleDefinition, error)
+	GetRole(context.Context, models.Role) (*models.RoleDefinition, error)
+	SaveRole(context.Context, *models.RoleDefinition) (*models.RoleDefinition, error)
+}
+
+type RolesGormRepository struct {
+	db *gorm.DB
+}
+
+// NewRolesGormRepository creates the default roles that are missing.
+//
+// Administrator can't be modified through the API,
+// its permissions are restored to the default ones every time.
+func NewRolesGormRepository(db *gorm.DB) *RolesGormRepository {
+	db.AutoMigrate(&models.RoleDefinition{})
+	for _, r := range models.DefaultRoles {
+		r := r
+		if r.Name == models.RoleAdministrator {
+			db.Save(&r)
+			continue
+		}
+		db.FirstOrCreate(&r, "name = ?", r.Name)
+	}
+	return &RolesGormRepository{
+		db: db,
+	}
+}
+
+func (r *RolesGormRepository) GetAllRoles(ctx context.Context) ([]models.RoleDefinition, error) {
+	var roles []models.RoleDefinition
+	res := r.db.WithContext(ctx).Find(&roles)
+	if res.Error != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	return roles, nil
+}
+
+func (r *RolesGormRepository) GetRole(ctx context.Context, name models.Role) (*models.RoleDefinition, error) {
+	var role *models.RoleDefinition
+	res := r.db.WithContext(ctx).Where("name = ?", name).Find(&role)
+	if res.Error != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	if res.RowsAffected != 1 {
+		return nil, ErrNotFound
+	}
+	return role, nil
+}
+
+// SaveRole creates the role if it doesn't exist, otherwise it updates it.
+func (r *RolesGormRepository) SaveRole(ctx context.Context, role *models.RoleDefinition) (*models.RoleDefinition, error) {
+	res := r.db.WithContext(ctx).Save(role)
+	if res.Error != nil {
+		return nil, ErrCouldNotUpdate
+	}
+	return role, nil
+}
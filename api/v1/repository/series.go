@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/JonathanGzzBen/ingenialists/api/v1/models"
+	"gorm.io/gorm"
+)
+
+var ErrArticleInOtherSeries = errors.New("article is part of another series")
+
+type SeriesRepository interface {
+	GetAllSeries(context.Context) ([]models.Series, error)
+	GetSeries(context.Context, uint) (*models.Series, error)
+	GetSeriesByArticles(context.Context, []uint) ([]models.Series, error)
+	CreateSeries(context.Context, *models.Series) (*models.Series, error)
+	UpdateSeries(context.Context, *models.Series) (*models.Series, error)
+	DeleteSeries(context.Context, uint) error
+	SetSeriesArticles(context.Context, uint, []uint) (*models.Series, error)
+}
+
+type SeriesGormRepository struct {
+	db *gorm.DB
+}
+
+func NewSeriesGormRepository(db *gorm.DB) *SeriesGormRepository {
+	db.AutoMigrate(&models.Series{}, &models.SeriesArticle{})
+	return &SeriesGormRepository{
+		db: db,
+	}
+}
+
+// withArticles preloads the articles of series sorted by position,
+// with what's needed to link to them and summarize them,
+// and their authors to tell who can see them.
+func (r *SeriesGormRepository) withArticles(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).Preload("Articles", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position")
+	}).Preload("Articles.Article", func(db *gorm.DB) *gorm.DB {
+		return db.Select(seriesArticleColumns)
+	}).Preload("Articles.Article.Authors")
+}
+
+func (r *SeriesGormRepository) GetAllSeries(ctx context.Context) ([]models.Series, error) {
+	var series []models.Series
+	res := r.withArticles(ctx).Find(&series)
+	if res.Error != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	return series, nil
+}
+
+func (r *SeriesGormRepository) GetSeries(ctx context.Context, id uint) (*models.Series, error) {
+	var series *models.Series
+	res := r.withArticles(ctx).Find(&series, id)
+	if res.Error == gorm.ErrRecordNotFound || res.RowsAffected != 1 {
+		return nil, ErrNotFound
+	}
+	if res.Error != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	return series, nil
+}
+
+// GetSeriesByArticles returns the series that
+// any of the articles with matching ids are part of.
+func (r *SeriesGormRepository) GetSeriesByArticles(ctx context.Context, ids []uint) ([]models.Series, error) {
+	var series []models.Series
+	if len(ids) == 0 {
+		return series, nil
+	}
+	res := r.withArticles(ctx).
+		Where("id IN (?)", r.db.WithContext(ctx).Model(&models.SeriesArticle{}).Select("series_id").Where("article_id IN ?", ids)).
+		Find(&series)
+	if res.Error != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	return series, nil
+}
+
+func (r *SeriesGormRepository) CreateSeries(ctx context.Context, s *models.Series) (*models.Series, error) {
+	res := r.db.WithContext(ctx).Omit("Articles").Create(s)
+	if res.Error != nil {
+		return nil, ErrCouldNotCreate
+	}
+	return r.GetSeries(ctx, s.ID)
+}
+
+// UpdateSeries saves title and description of s,
+// its articles are changed with SetSeriesArticles.
+func (r *SeriesGormRepository) UpdateSeries(ctx context.Context, s *models.Series) (*models.Series, error) {
+	res := r.db.WithContext(ctx).Omit("Articles").Save(s)
+	if res.Error != nil {
+		return nil, ErrCouldNotUpdate
+	}
+	return r.GetSeries(ctx, s.ID)
+}
+
+// DeleteSeries deletes series with matching id,
+// its articles are kept.
+func (r *SeriesGormRepository) DeleteSeries(ctx context.Context, id uint) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("series_id = ?", id).Delete(&models.SeriesArticle{}).Error; err != nil {
+			return err
+		}
+		res := tx.Delete(&models.Series{}, id)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected != 1 {
+			return ErrNotFound
+		}
+		return nil
+	})
+	if err == ErrNotFound {
+		return err
+	}
+	if err != nil {
+		return ErrCouldNotDelete
+	}
+	return nil
+}
+
+// SetSeriesArticles replaces the articles of series with matching id
+// by articles with articleIDs, in that order.
+func (r *SeriesGormRepository) SetSeriesArticles(ctx context.Context, id uint, articleIDs []uint) (*models.Series, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(articleIDs) > 0 {
+			var elsewhere int64
+			res := tx.Model(&models.SeriesArticle{}).Where("article_id IN ? AND series_id <> ?", articleIDs, id).Count(&elsewhere)
+			if res.Error != nil {
+				return ErrCouldNotRetrieve
+			}
+			if elsewhere > 0 {
+				return ErrArticleInOtherSeries
+			}
+		}
+		if err := tx.Where("series_id = ?", id).Delete(&models.SeriesArticle{}).Error; err != nil {
+			return ErrCouldNotUpdate
+		}
+		for i, aid := range articleIDs {
+			if err := tx.Create(&models.SeriesArticle{SeriesID: id, ArticleID: aid, Position: i + 1}).Error; err != nil {
+				return ErrCouldNotUpdate
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetSeries(ctx, id)
+}
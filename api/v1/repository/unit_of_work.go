@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// Repositories are the repositories a unit of work is done with.
+type Repositories struct {
+	Users        UsersRepository
+	Articles     ArticlesRepository
+	Categories   CategoriesRepository
+	RoleRequests RoleRequestsRepository
+}
+
+// UnitOfWork makes several repository calls atomically.
+type UnitOfWork interface {
+	// Do calls fn with repos bound to a new unit of work done
+	// in ctx, whose changes are kept if fn returns nil and discarded
+	// otherwise. The error returned by fn is returned.
+	Do(context.Context, Repositories, func(Repositories) error) error
+}
+
+// GormUnitOfWork runs units of work in gorm transactions.
+//
+// Only gorm repositories take part in the transaction,
+// other repositories, like mocks, are passed to fn as they are.
+// Caching decorators are left out of it, so that uncommitted
+// changes aren't cached, and their entries are invalidated
+// once it's committed.
+type GormUnitOfWork struct {
+	db *gorm.DB
+}
+
+func NewGormUnitOfWork(db *gorm.DB) *GormUnitOfWork {
+	return &GormUnitOfWork{
+		db: db,
+	}
+}
+
+func (u *GormUnitOfWork) Do(ctx context.Context, repos Repositories, fn func(Repositories) error) error {
+	repos, invalidate := repos.uncached()
+	err := u.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, ok := repos.Users.(*UsersGormRepository); ok {
+			repos.Users = &UsersGormRepository{db: tx}
+		}
+		if _, ok := repos.Articles.(*ArticlesGormRepository); ok {
+			repos.Articles = &ArticlesGormRepository{db: tx}
+		}
+		if _, ok := repos.Categories.(*CategoriesGormRepository); ok {
+			repos.Categories = &CategoriesGormRepository{db: tx}
+		}
+		if _, ok := repos.RoleRequests.(*RoleRequestsGormRepository); ok {
+			repos.RoleRequests = &RoleRequestsGormRepository{db: tx}
+		}
+		return fn(repos)
+	})
+	// Nothing changed if it was rolled back
+	if err == nil {
+		invalidate()
+	}
+	return err
+}
+
+// NonTransactionalUnitOfWork calls fn with the repositories
+// it's given, for repositories that don't support transactions.
+// Changes made before fn fails are kept.
+type NonTransactionalUnitOfWork struct{}
+
+func (NonTransactionalUnitOfWork) Do(ctx context.Context, repos Repositories, fn func(Repositories) error) error {
+	return fn(repos)
+}
@@ -1,16 +1,19 @@
 package repository
 
 import (
+	"context"
+	"time"
+
 	"github.com/JonathanGzzBen/ingenialists/api/v1/models"
 	"gorm.io/gorm"
 )
 
 type UsersRepository interface {
-	GetAllUsers() ([]models.User, error)
-	GetUser(uint) (*models.User, error)
-	GetUserByGoogleSub(string) (*models.User, error)
-	CreateUser(*models.User) (*models.User, error)
-	UpdateUser(*models.User) (*models.User, error)
+	GetAllUsers(context.Context, Projection) ([]models.User, error)
+	GetUser(context.Context, uint, Projection) (*models.User, error)
+	GetUserByGoogleSub(context.Context, string) (*models.User, error)
+	CreateUser(context.Context, *models.User) (*models.User, error)
+	UpdateUser(context.Context, *models.User) (*models.User, error)
 }
 
 type UsersGormRepository struct {
@@ -19,23 +22,28 @@ type UsersGormRepository struct {
 
 func NewUsersGormRepository(db *gorm.DB) *UsersGormRepository {
 	db.AutoMigrate(&models.User{})
+	// Users created before UpdatedAt existed are considered
+	// updated when the column is added
+	db.Model(&models.User{}).Where("updated_at IS NULL").UpdateColumn("updated_at", time.Now())
 	return &UsersGormRepository{
 		db: db,
 	}
 }
 
-func (r *UsersGormRepository) GetAllUsers() ([]models.User, error) {
+func (r *UsersGormRepository) GetAllUsers(ctx context.Context, p Projection) ([]models.User, error) {
 	var users []models.User
-	res := r.db.Find(&users)
+	res := p.selectColumns(r.db.WithContext(ctx), &models.User{}, []string{"id", "updated_at"}).Find(&users)
 	if res.Error != nil {
 		return nil, ErrCouldNotRetrieve
 	}
 	return users, nil
 }
 
-func (r *UsersGormRepository) GetUser(id uint) (*models.User, error) {
+// GetUser returns user with matching id, loading the fields p
+// selects. Every field is loaded if p has no fields.
+func (r *UsersGormRepository) GetUser(ctx context.Context, id uint, p Projection) (*models.User, error) {
 	var user *models.User
-	res := r.db.Find(&user, id)
+	res := p.selectColumns(r.db.WithContext(ctx), &models.User{}, userColumns).Find(&user, id)
 	if res.Error == gorm.ErrRecordNotFound || res.RowsAffected != 1 {
 		return nil, ErrNotFound
 	}
@@ -45,9 +53,9 @@ func (r *UsersGormRepository) GetUser(id uint) (*models.User, error) {
 	return user, nil
 }
 
-func (r *UsersGormRepository) GetUserByGoogleSub(sub string) (*models.User, error) {
+func (r *UsersGormRepository) GetUserByGoogleSub(ctx context.Context, sub string) (*models.User, error) {
 	var user *models.User
-	res := r.db.Where("google_sub = ?", sub).First(&user)
+	res := r.db.WithContext(ctx).Where("google_sub = ?", sub).First(&user)
 	if res.Error == gorm.ErrRecordNotFound {
 		return nil, ErrCouldNotRetrieve
 	}
@@ -57,18 +65,18 @@ func (r *UsersGormRepository) GetUserByGoogleSub(sub string) (*models.User, erro
 	return user, nil
 }
 
-func (r *UsersGormRepository) CreateUser(u *models.User) (*models.User, error) {
-	res := r.db.Create(u)
+func (r *UsersGormRepository) CreateUser(ctx context.Context, u *models.User) (*models.User, error) {
+	u.Version = 1
+	res := r.db.WithContext(ctx).Create(u)
 	if res.Error != nil {
 		return nil, ErrCouldNotCreate
 	}
 	return u, nil
 }
 
-func (r *UsersGormRepository) UpdateUser(u *models.User) (*models.User, error) {
-	res := r.db.Save(u)
-	if res.Error != nil {
-		return nil, ErrCouldNotUpdate
+func (r *UsersGormRepository) UpdateUser(ctx context.Context, u *models.User) (*models.User, error) {
+	if err := saveVersioned(r.db.WithContext(ctx), u, &u.Version); err != nil {
+		return nil, err
 	}
 	return u, nil
 }
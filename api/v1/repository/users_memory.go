@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/JonathanGzzBen/ingenialists/api/v1/models"
+)
+
+// UsersMemoryRepository is a UsersRepository
+// that keeps users in a MemoryStore.
+type UsersMemoryRepository struct {
+	s *MemoryStore
+}
+
+func NewUsersMemoryRepository(s *MemoryStore) *UsersMemoryRepository {
+	return &UsersMemoryRepository{
+		s: s,
+	}
+}
+
+func (r *UsersMemoryRepository) GetAllUsers(ctx context.Context, p Projection) ([]models.User, error) {
+	if ctx.Err() != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	users := []models.User{}
+	for _, id := range r.s.userIDs() {
+		u := r.s.users[id]
+		p.clearColumns(&u, []string{"id", "updated_at"})
+		users = append(users, u)
+	}
+	return users, nil
+}
+
+func (r *UsersMemoryRepository) GetUser(ctx context.Context, id uint, p Projection) (*models.User, error) {
+	if ctx.Err() != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	u, ok := r.s.users[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	p.clearColumns(&u, userColumns)
+	return &u, nil
+}
+
+func (r *UsersMemoryRepository) GetUserByGoogleSub(ctx context.Context, sub string) (*models.User, error) {
+	if ctx.Err() != nil {
+		return nil, ErrCouldNotRetrieve
+	}
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	for _, id := range r.s.userIDs() {
+		if u := r.s.users[id]; u.GoogleSub == sub {
+			return &u, nil
+		}
+	}
+	return nil, ErrCouldNotRetrieve
+}
+
+func (r *UsersMemoryRepository) CreateUser(ctx context.Context, u *models.User) (*models.User, error) {
+	if ctx.Err() != nil {
+		return nil, ErrCouldNotCreate
+	}
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.users[u.ID]; ok || r.s.googleSubTaken(u) {
+		return nil, ErrCouldNotCreate
+	}
+	u.ID = r.s.nextID("users", u.ID)
+	u.Version = 1
+	if u.UpdatedAt.IsZero() {
+		u.UpdatedAt = time.Now()
+	}
+	r.s.users[u.ID] = *u
+	return u, nil
+}
+
+func (r *UsersMemoryRepository) UpdateUser(ctx context.Context, u *models.User) (*models.User, error) {
+	if ctx.Err() != nil {
+		return nil, ErrCouldNotUpdate
+	}
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if current, ok := r.s.users[u.ID]; !ok || current.Version != u.Version {
+		return nil, ErrVersionConflict
+	}
+	if r.s.googleSubTaken(u) {
+		return nil, ErrCouldNotUpdate
+	}
+	u.Version++
+	u.UpdatedAt = time.Now()
+	r.s.users[u.ID] = *u
+	return u, nil
+}
+
+// userIDs returns the IDs of users in ascending order.
+func (s *MemoryStore) userIDs() []uint {
+	ids := make([]uint, 0, len(s.users))
+	for id := range s.users {
+		ids = append(ids, id)
+	}
+	return sortIDs(ids)
+}
+
+// googleSubTaken reports whether a user other than u
+// has the GoogleSub of u, which must be unique if set.
+func (s *MemoryStore) googleSubTaken(u *models.User) bool {
+	if u.GoogleSub == "" {
+		return false
+	}
+	for id, other := range s.users {
+		if id != u.ID && other.GoogleSub == u.GoogleSub {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,24 @@
+package repository
+
+import "gorm.io/gorm"
+
+// saveVersioned saves record, whose Version field is pointed to by
+// version, only if its version in the database is still *version.
+// The version is incremented when saved, otherwise it's left as is
+// and ErrVersionConflict is returned.
+//
+// Associations in omit are not saved.
+func saveVersioned(tx *gorm.DB, record interface{}, version *uint, omit ...string) error {
+	current := *version
+	*version = current + 1
+	res := tx.Where("version = ?", current).Select("*").Omit(omit...).Save(record)
+	if res.Error != nil {
+		*version = current
+		return ErrCouldNotUpdate
+	}
+	if res.RowsAffected != 1 {
+		*version = current
+		return ErrVersionConflict
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JonathanGzzBen/ingenialists/api/v1/models"
+	"github.com/JonathanGzzBen/ingenialists/api/v1/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// apiKeyDisplayLength is how many characters of a key
+// are kept in APIKey.Prefix
+const apiKeyDisplayLength = 12
+
+type CreateAPIKeyDTO struct {
+	Name      string         `json:"name" binding:"required"`
+	Scopes    []models.Scope `json:"scopes" example:"articles:write"`
+	ExpiresAt *time.Time     `json:"expiresAt" example:"2006-01-02T15:04:05Z"`
+}
+
+// CreatedAPIKeyDTO is returned only once, when the key is created,
+// it's the only time Key can be read.
+type CreatedAPIKeyDTO struct {
+	models.APIKey
+	Key string `json:"key"`
+}
+
+// GetAPIKeys is the handler for GET requests to /users/:id/api-keys
+// 	@ID GetAPIKeys
+// 	@Summary Get API keys
+// 	@Description Get API keys of user with matching ID.
+// 	@Tags users
+// 	@Security AccessToken
+// 	@Param id path int true "User ID"
+// 	@Success 200 {array} models.APIKey
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 403 {object} models.APIError
+// 	@Failure 500 {object} models.APIError
+// 	@Router /users/{id}/api-keys [get]
+func (s *Server) GetAPIKeys(c *gin.Context) {
+	id, ok := s.apiKeyOwner(c)
+	if !ok {
+		return
+	}
+	keys, err := s.APIKeysRepo.GetAPIKeysByUser(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: "could not get api keys"})
+		return
+	}
+	c.JSON(http.StatusOK, keys)
+}
+
+// CreateAPIKey is the handler for POST requests to /users/:id/api-keys
+// 	@ID CreateAPIKey
+// 	@Summary Create API key
+// 	@Description Create an API key for user with matching ID.
+// 	@Description The key is only included in this response.
+// 	@Tags users
+// 	@Security AccessToken
+// 	@Param id path int true "User ID"
+// 	@Param apiKey body CreateAPIKeyDTO true "API key"
+// 	@Success 200 {object} CreatedAPIKeyDTO
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 403 {object} models.APIError
+// 	@Failure 500 {object} models.APIError
+// 	@Router /users/{id}/api-keys [post]
+func (s *Server) CreateAPIKey(c *gin.Context) {
+	id, ok := s.apiKeyOwner(c)
+	if !ok {
+		return
+	}
+	var ck CreateAPIKeyDTO
+	if err := c.ShouldBindJSON(&ck); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "invalid api key: " + err.Error()})
+		return
+	}
+	scopes := make([]string, 0, len(ck.Scopes))
+	for _, sc := range ck.Scopes {
+		if !validScope(sc) {
+			c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "unknown scope " + string(sc)})
+			return
+		}
+		scopes = append(scopes, string(sc))
+	}
+	if ck.ExpiresAt != nil && ck.ExpiresAt.Before(time.Now()) {
+		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "expiresAt must be in the future"})
+		return
+	}
+
+	secret, err := newRandomToken(32)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: "could not generate api key"})
+		return
+	}
+	key := APIKeyPrefix + secret
+	k, err := s.APIKeysRepo.CreateAPIKey(c.Request.Context(), &models.APIKey{
+		UserID:    id,
+		Name:      ck.Name,
+		Prefix:    key[:apiKeyDisplayLength],
+		KeyHash:   hashToken(key),
+		Scopes:    strings.Join(scopes, ","),
+		ExpiresAt: ck.ExpiresAt,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: "could not create api key: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, CreatedAPIKeyDTO{APIKey: *k, Key: key})
+}
+
+// RevokeAPIKey is the handler for DELETE requests to /users/:id/api-keys/:keyId
+// 	@ID RevokeAPIKey
+// 	@Summary Revoke API key
+// 	@Description Revoke API key with matching ID, it can't be used anymore.
+// 	@Tags users
+// 	@Security AccessToken
+// 	@Param id path int true "User ID"
+// 	@Param keyId path int true "API key ID"
+// 	@Success 204 {object} string
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 403 {object} models.APIError
+// 	@Failure 404 {object} models.APIError
+// 	@Failure 500 {object} models.APIError
+// 	@Router /users/{id}/api-keys/{keyId} [delete]
+func (s *Server) RevokeAPIKey(c *gin.Context) {
+	id, ok := s.apiKeyOwner(c)
+	if !ok {
+		return
+	}
+	keyID, err := strconv.Atoi(c.Param("keyId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "invalid key id: " + err.Error()})
+		return
+	}
+	k, err := s.APIKeysRepo.GetAPIKey(c.Request.Context(), uint(keyID))
+	if err == repository.ErrNotFound || (err == nil && k.UserID != id) {
+		c.JSON(http.StatusNotFound, models.APIError{Code: http.StatusNotFound, Message: "api key not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+	if k.RevokedAt == nil {
+		before := *k
+		now := time.Now()
+		k.RevokedAt = &now
+		if _, err := s.APIKeysRepo.UpdateAPIKey(c.Request.Context(), k); err != nil {
+			c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: "could not revoke api key: " + err.Error()})
+			return
+		}
+		if au := currentUser(c); au != nil && au.ID != k.UserID {
+			s.audit(c, au, models.AuditAPIKeyRevokeOthers, "api_key", k.ID, before, k)
+		}
+	}
+	c.String(http.StatusNoContent, "revoked")
+}
+
+// apiKeyOwner returns the id of the user whose API keys are being managed.
+//
+// Users can only manage their own keys, unless they have
+// permission to manage users, in both cases authenticated with OAuth2,
+// so that a leaked key can't be used to create more keys.
+// If it returns false, a response has already been written.
+func (s *Server) apiKeyOwner(c *gin.Context) (uint, bool) {
+	au, err := s.authenticate(c)
+	if err != nil {
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "you must be authenticated to manage api keys"})
+		return 0, false
+	}
+	if authenticatedWithAPIKey(c) {
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "api keys can't be managed with an api key"})
+		return 0, false
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "invalid id: " + err.Error()})
+		return 0, false
+	}
+	if au.ID != uint(id) && !s.can(c.Request.Context(), au, models.PermissionUserManage) {
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "id does not match authenticated user"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func validScope(scope models.Scope) bool {
+	for _, s := range models.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
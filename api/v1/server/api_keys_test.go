@@ -0,0 +1,212 @@
+package server_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/JonathanGzzBen/ingenialists/api/v1/models"
+	"github.com/JonathanGzzBen/ingenialists/api/v1/server"
+)
+
+// createAPIKey creates an API key for the user authenticated in
+// testing mode, which has ID = 1, and returns it.
+func createAPIKey(t *testing.T, ts *httptest.Server, scopes ...models.Scope) server.CreatedAPIKeyDTO {
+	body, err := json.Marshal(server.CreateAPIKeyDTO{Name: "Publishing script", Scopes: scopes})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/v1/users/1/api-keys", ts.URL), bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req.Header.Add(server.AccessTokenName, "Writer")
+	res, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+	var k server.CreatedAPIKeyDTO
+	err = json.NewDecoder(res.Body).Decode(&k)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return k
+}
+
+func TestCreateAPIKeyAsSameUserReturnOk(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	k := createAPIKey(t, ts, models.ScopeArticlesWrite)
+	if len(k.Key) == 0 {
+		t.Fatalf("Expected key to be set")
+	}
+	if k.Scopes != string(models.ScopeArticlesWrite) {
+		t.Fatalf("Expected %v, got %v", models.ScopeArticlesWrite, k.Scopes)
+	}
+
+	stored, err := s.APIKeysRepo.GetAPIKey(context.Background(), k.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if stored.KeyHash == k.Key {
+		t.Fatalf("Expected key to be stored hashed")
+	}
+}
+
+func TestCreateAPIKeyAsDifferentUserReturnForbidden(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	body, err := json.Marshal(server.CreateAPIKeyDTO{Name: "Publishing script"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/v1/users/2/api-keys", ts.URL), bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req.Header.Add(server.AccessTokenName, "Writer")
+	res, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode != http.StatusForbidden {
+		t.Fatalf("Expected status code %v, got %v", http.StatusForbidden, res.StatusCode)
+	}
+}
+
+func TestCreateAPIKeyWithUnknownScopeReturnBadRequest(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	body, err := json.Marshal(server.CreateAPIKeyDTO{Name: "Publishing script", Scopes: []models.Scope{"everything"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/v1/users/1/api-keys", ts.URL), bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req.Header.Add(server.AccessTokenName, "Writer")
+	res, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected status code %v, got %v", http.StatusBadRequest, res.StatusCode)
+	}
+}
+
+func TestAuthenticateWithAPIKeyReturnOk(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	u := models.User{Name: "Script Owner", Role: models.RoleWriter}
+	s.UsersRepo.CreateUser(context.Background(), &u)
+	k := createAPIKey(t, ts, models.ScopeArticlesWrite)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/v1/auth", ts.URL), nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req.Header.Add(server.AccessTokenName, k.Key)
+	res, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+	var resUser models.User
+	err = json.NewDecoder(res.Body).Decode(&resUser)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resUser.ID != u.ID {
+		t.Fatalf("Expected %v, got %v", u.ID, resUser.ID)
+	}
+
+	stored, err := s.APIKeysRepo.GetAPIKey(context.Background(), k.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if stored.LastUsedAt == nil {
+		t.Fatalf("Expected LastUsedAt to be set")
+	}
+}
+
+func TestCreateArticleWithAPIKeyWithoutScopeReturnForbidden(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	u := models.User{Name: "Script Owner", Role: models.RoleWriter}
+	s.UsersRepo.CreateUser(context.Background(), &u)
+	k := createAPIKey(t, ts, models.ScopeUsersWrite)
+
+	body, err := json.Marshal(server.CreateArticleDTO{Title: "First article"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/v1/articles", ts.URL), bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req.Header.Add(server.AccessTokenName, k.Key)
+	res, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode != http.StatusForbidden {
+		t.Fatalf("Expected status code %v, got %v", http.StatusForbidden, res.StatusCode)
+	}
+}
+
+func TestRevokeAPIKeyReturnNoContent(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	u := models.User{Name: "Script Owner", Role: models.RoleWriter}
+	s.UsersRepo.CreateUser(context.Background(), &u)
+	k := createAPIKey(t, ts, models.ScopeArticlesWrite)
+
+	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/v1/users/1/api-keys/%d", ts.URL, k.ID), nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req.Header.Add(server.AccessTokenName, "Writer")
+	res, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode != http.StatusNoContent {
+		t.Fatalf("Expected status code %v, got %v", http.StatusNoContent, res.StatusCode)
+	}
+
+	// Revoked key can't authenticate anymore
+	req, err = http.NewRequest(http.MethodGet, fmt.Sprintf("%s/v1/auth", ts.URL), nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req.Header.Add(server.AccessTokenName, k.Key)
+	res, err = ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode != http.StatusForbidden {
+		t.Fatalf("Expected status code %v, got %v", http.StatusForbidden, res.StatusCode)
+	}
+}
@@ -0,0 +1,211 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/JonathanGzzBen/ingenialists/api/v1/models"
+	"github.com/JonathanGzzBen/ingenialists/api/v1/repository"
+	"github.com/gin-gonic/gin"
+)
+
+type SaveArticleAuthorDTO struct {
+	Role models.AuthorRole `json:"role" binding:"required" example:"contributor"`
+}
+
+// SaveArticleAuthor is the handler for PUT requests to /articles/:id/authors/:userId
+// 	@ID SaveArticleAuthor
+// 	@Summary Credit article author
+// 	@Description Credit user as an author of article, or change their role if already credited.
+// 	@Description Only the user that created the article and editors can change its authors.
+// 	@Tags articles
+// 	@Security AccessToken
+// 	@Param id path int true "Article ID"
+// 	@Param userId path int true "User ID"
+// 	@Param author body SaveArticleAuthorDTO true "Author"
+// 	@Success 200 {object} models.ArticleAuthor
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 403 {object} models.APIError
+// 	@Failure 404 {object} models.APIError
+// 	@Failure 500 {object} models.APIError
+// 	@Router /articles/{id}/authors/{userId} [put]
+func (s *Server) SaveArticleAuthor(c *gin.Context) {
+	au, article, ok := s.articleAuthorsOwner(c)
+	if !ok {
+		return
+	}
+	uid, err := strconv.Atoi(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "invalid user id: " + err.Error()})
+		return
+	}
+	var saa SaveArticleAuthorDTO
+	if err := c.ShouldBindJSON(&saa); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "invalid author: " + err.Error()})
+		return
+	}
+	if !validAuthorRole(saa.Role) {
+		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "unknown author role " + string(saa.Role)})
+		return
+	}
+	if uint(uid) != article.UserID {
+		if _, err := s.UsersRepo.GetUser(c.Request.Context(), uint(uid), repository.Projection{}); err != nil {
+			c.JSON(http.StatusNotFound, models.APIError{Code: http.StatusNotFound, Message: "user not found"})
+			return
+		}
+	}
+	aa, err := s.ArticlesRepo.SaveArticleAuthor(c.Request.Context(), &models.ArticleAuthor{
+		ArticleID: article.ID,
+		UserID:    uint(uid),
+		Role:      saa.Role,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: "could not save author: " + err.Error()})
+		return
+	}
+	if article.UserID != au.ID {
+		s.audit(c, au, models.AuditArticleUpdateOthers, "article", article.ID, article.Authors, aa)
+	}
+	c.JSON(http.StatusOK, aa)
+}
+
+// RemoveArticleAuthor is the handler for DELETE requests to /articles/:id/authors/:userId
+// 	@ID RemoveArticleAuthor
+// 	@Summary Remove article author
+// 	@Description Stop crediting user as an author of article.
+// 	@Description The user that created the article can't be removed.
+// 	@Tags articles
+// 	@Security AccessToken
+// 	@Param id path int true "Article ID"
+// 	@Param userId path int true "User ID"
+// 	@Success 204 {object} string
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 403 {object} models.APIError
+// 	@Failure 404 {object} models.APIError
+// 	@Failure 500 {object} models.APIError
+// 	@Router /articles/{id}/authors/{userId} [delete]
+func (s *Server) RemoveArticleAuthor(c *gin.Context) {
+	au, article, ok := s.articleAuthorsOwner(c)
+	if !ok {
+		return
+	}
+	uid, err := strconv.Atoi(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "invalid user id: " + err.Error()})
+		return
+	}
+	if uint(uid) == article.UserID {
+		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "the user that created the article can't be removed from its authors"})
+		return
+	}
+	err = s.ArticlesRepo.RemoveArticleAuthor(c.Request.Context(), article.ID, uint(uid))
+	if err == repository.ErrNotFound {
+		c.JSON(http.StatusNotFound, models.APIError{Code: http.StatusNotFound, Message: "user is not an author of article"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: "could not remove author: " + err.Error()})
+		return
+	}
+	if article.UserID != au.ID {
+		s.audit(c, au, models.AuditArticleUpdateOthers, "article", article.ID, article.Authors, nil)
+	}
+	c.String(http.StatusNoContent, "deleted")
+}
+
+// GetUserArticles is the handler for GET requests to /users/:id/articles
+// 	@ID GetUserArticles
+// 	@Summary Get articles of user
+// 	@Description Get published articles crediting user with matching ID
+// 	@Description as one of their authors, including co-authored articles.
+// 	@Tags users
+// 	@Param id path int true "User ID"
+// 	@Param fields query string false "Fields to include separated by commas, body is left out unless listed"
+// 	@Param expand query string false "Associations to include separated by commas: user, category, authors"
+// 	@Success 200 {array} models.Article
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 500 {object} models.APIError
+// 	@Router /users/{id}/articles [get]
+func (s *Server) GetUserArticles(c *gin.Context) {
+	p := projection(c)
+	if err := checkProjection(p, models.Article{}, articleAssociations); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: err.Error()})
+		return
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "invalid id: " + err.Error()})
+		return
+	}
+	articles, err := s.ArticlesRepo.GetAllArticles(c.Request.Context(), repository.ArticlesQuery{
+		Published:  true,
+		AuthorID:   uint(id),
+		Projection: p,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: "could not get articles"})
+		return
+	}
+	if err := s.attachSeries(c, articles); err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: "could not get series of articles"})
+		return
+	}
+	renderProjection(c, http.StatusOK, articles, p, articleAssociations)
+}
+
+// articleAuthorsOwner authenticates the request in c and gets the article
+// in its path, writing an error response if the authenticated user
+// can't change the authors of the article.
+func (s *Server) articleAuthorsOwner(c *gin.Context) (*models.User, *models.Article, bool) {
+	au, err := s.authenticate(c)
+	if err != nil {
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "you must be authenticated to change article authors"})
+		return nil, nil, false
+	}
+	if !hasScope(c, models.ScopeArticlesWrite) {
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "api key lacks scope " + string(models.ScopeArticlesWrite)})
+		return nil, nil, false
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "invalid id: " + err.Error()})
+		return nil, nil, false
+	}
+	article, err := s.ArticlesRepo.GetArticle(c.Request.Context(), uint(id), repository.WholeArticle)
+	if err == repository.ErrNotFound {
+		c.JSON(http.StatusNotFound, models.APIError{Code: http.StatusNotFound, Message: "article not found"})
+		return nil, nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return nil, nil, false
+	}
+	if article.UserID != au.ID && !s.can(c.Request.Context(), au, models.PermissionArticleEditAny) {
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "you can only change authors of articles created by you"})
+		return nil, nil, false
+	}
+	return au, article, true
+}
+
+// isArticleAuthor reports whether user with matching id
+// is credited as one of the authors of a.
+func isArticleAuthor(a *models.Article, id uint) bool {
+	if a.UserID == id {
+		return true
+	}
+	for _, author := range a.Authors {
+		if author.UserID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func validAuthorRole(role models.AuthorRole) bool {
+	for _, r := range models.AuthorRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,158 @@
+package server_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/JonathanGzzBen/ingenialists/api/v1/models"
+	"github.com/JonathanGzzBen/ingenialists/api/v1/server"
+)
+
+// createCoAuthoredArticle registers an article created by another user
+// that credits user with ID = 1, the authenticated user in testing mode,
+// as a contributor.
+func createCoAuthoredArticle(t *testing.T, s *server.Server) *models.Article {
+	for _, name := range []string{"Testing User", "Main Author"} {
+		if _, err := s.UsersRepo.CreateUser(context.Background(), &models.User{Name: name, Role: models.RoleWriter}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	a := createArticle(t, s)
+	a, err := s.ArticlesRepo.CreateArticle(context.Background(), &models.Article{
+		UserID:     2,
+		CategoryID: a.CategoryID,
+		Title:      "Joint review",
+		Authors:    []models.ArticleAuthor{{UserID: 1, Role: models.AuthorRoleContributor}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return a
+}
+
+func TestCreateArticleCreditsCreatorAsAuthor(t *testing.T) {
+	s := NewTestServer()
+
+	a := createArticle(t, s)
+	if len(a.Authors) != 1 || a.Authors[0].UserID != 1 || a.Authors[0].Role != models.AuthorRoleAuthor {
+		t.Fatalf("Expected creator to be credited as author, got %v", a.Authors)
+	}
+}
+
+func TestUpdateArticleAsCoAuthorReturnOk(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	a := createCoAuthoredArticle(t, s)
+
+	uaJSONBytes, err := json.Marshal(server.UpdateArticleDTO{CategoryID: a.CategoryID, Title: "Joint review, updated"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("%s/v1/articles/%d", ts.URL, a.ID), bytes.NewBuffer(uaJSONBytes))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req.Header.Add(server.AccessTokenName, "Writer")
+	res, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+}
+
+func TestSaveArticleAuthorAsCoAuthorReturnForbidden(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	a := createCoAuthoredArticle(t, s)
+
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("%s/v1/articles/%d/authors/1", ts.URL, a.ID), bytes.NewBufferString(`{"role":"editor"}`))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req.Header.Add(server.AccessTokenName, "Writer")
+	res, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode != http.StatusForbidden {
+		t.Fatalf("Expected status code %v, got %v", http.StatusForbidden, res.StatusCode)
+	}
+}
+
+func TestSaveArticleAuthorAsCreatorReturnOk(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	if _, err := s.UsersRepo.CreateUser(context.Background(), &models.User{Name: "Testing User"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	coAuthor, err := s.UsersRepo.CreateUser(context.Background(), &models.User{Name: "Co-author"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	a := createArticle(t, s)
+
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("%s/v1/articles/%d/authors/%d", ts.URL, a.ID, coAuthor.ID), bytes.NewBufferString(`{"role":"editor"}`))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req.Header.Add(server.AccessTokenName, "Writer")
+	res, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+	var aa models.ArticleAuthor
+	if err := json.NewDecoder(res.Body).Decode(&aa); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if aa.User.Name != coAuthor.Name || aa.Role != models.AuthorRoleEditor {
+		t.Fatalf("Expected %v as %v, got %v as %v", coAuthor.Name, models.AuthorRoleEditor, aa.User.Name, aa.Role)
+	}
+
+	res = doAs(t, ts, http.MethodDelete, fmt.Sprintf("/v1/articles/%d/authors/%d", a.ID, coAuthor.ID), "Writer", nil, nil)
+	if res.StatusCode != http.StatusNoContent {
+		t.Fatalf("Expected status code %v, got %v", http.StatusNoContent, res.StatusCode)
+	}
+	res = doAs(t, ts, http.MethodDelete, fmt.Sprintf("/v1/articles/%d/authors/%d", a.ID, a.UserID), "Writer", nil, nil)
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected status code %v, got %v", http.StatusBadRequest, res.StatusCode)
+	}
+}
+
+func TestGetUserArticlesIncludesCoAuthoredArticles(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	createCoAuthoredArticle(t, s)
+
+	res, err := http.Get(fmt.Sprintf("%s/v1/users/1/articles", ts.URL))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+	var articles []models.Article
+	if err := json.NewDecoder(res.Body).Decode(&articles); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(articles) != 2 {
+		t.Fatalf("Expected %v, got %v", 2, len(articles))
+	}
+}
@@ -1,8 +1,10 @@
 package server
 
 import (
+	"context"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/JonathanGzzBen/ingenialists/api/v1/models"
 	"github.com/JonathanGzzBen/ingenialists/api/v1/repository"
@@ -15,6 +17,9 @@ type CreateArticleDTO struct {
 	Title      string `json:"title"`
 	ImageURL   string `json:"imageUrl"`
 	Tags       string `json:"tags"`
+	// PublishAt schedules the article to be published later,
+	// it is published right away if empty
+	PublishAt *time.Time `json:"publishAt"`
 }
 
 type UpdateArticleDTO struct {
@@ -23,43 +28,115 @@ type UpdateArticleDTO struct {
 	Title      string `json:"title"`
 	ImageURL   string `json:"imageUrl"`
 	Tags       string `json:"tags"`
+	// PublishAt reschedules an article that isn't published yet,
+	// the schedule is left unchanged if empty
+	PublishAt *time.Time `json:"publishAt"`
 }
 
 // GetAllArticles is the handler for GET requests to /articles
 // 	@ID GetAllArticles
 // 	@Summary Get all articles
-// 	@Description Get all registered articles.
+// 	@Description Get all published articles, or scheduled articles
+// 	@Description of authenticated user if status is scheduled.
+// 	@Description Archived articles are only listed if status is archived.
 // 	@Tags articles
+// 	@Security AccessToken
+// 	@Param categoryId query int false "Only get articles in category with this ID"
+// 	@Param includeDescendants query bool false "Also get articles in subcategories of categoryId"
+// 	@Param status query string false "Publication status" Enums(published, scheduled, archived)
+// 	@Param fields query string false "Fields to include separated by commas, body is left out unless listed"
+// 	@Param expand query string false "Associations to include separated by commas: user, category, authors"
 // 	@Success 200 {array} models.Article
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 403 {object} models.APIError
 // 	@Failure 500 {object} models.APIError
 // 	@Router /articles [get]
 func (s *Server) GetAllArticles(c *gin.Context) {
-	articles, err := s.ArticlesRepo.GetAllArticles()
+	p := projection(c)
+	if err := checkProjection(p, models.Article{}, articleAssociations); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: err.Error()})
+		return
+	}
+	q := repository.ArticlesQuery{Projection: p}
+	switch c.Query("status") {
+	case "", "published":
+		q.Published = true
+	case "archived":
+		q.Published = true
+		q.Archived = true
+	case "scheduled":
+		au, err := s.authenticate(c)
+		if err != nil {
+			c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "you must be authenticated to get scheduled articles"})
+			return
+		}
+		q.Scheduled = true
+		// Editors see every scheduled article, others only theirs
+		if !s.can(c.Request.Context(), au, models.PermissionArticleEditAny) {
+			q.UserID = au.ID
+		}
+	default:
+		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "invalid status: " + c.Query("status")})
+		return
+	}
+	if cid := c.Query("categoryId"); cid != "" {
+		categoryID, err := strconv.Atoi(cid)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "invalid categoryId: " + err.Error()})
+			return
+		}
+		q.CategoryIDs = []uint{uint(categoryID)}
+		if c.Query("includeDescendants") == "true" {
+			categories, err := s.CategoriesRepo.GetAllCategories(c.Request.Context(), repository.Projection{})
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: "could not get categories"})
+				return
+			}
+			q.CategoryIDs = categoryDescendants(categories, uint(categoryID))
+		}
+	}
+	articles, err := s.ArticlesRepo.GetAllArticles(c.Request.Context(), q)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: "could not get articles"})
 		return
 	}
-	c.JSON(http.StatusOK, articles)
+	if err := s.attachSeries(c, articles); err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: "could not get series of articles"})
+		return
+	}
+	renderProjection(c, http.StatusOK, articles, p, articleAssociations)
 }
 
 // GetArticle is the handler for GET requests to /article/:id
 // 	@ID GetArticle
 // 	@Summary Get article
 // 	@Description Get article with matching ID.
+// 	@Description Articles not published yet are only found by their author and editors.
 // 	@Tags articles
 // 	@Param id path int true "Article ID"
+// 	@Param fields query string false "Fields to include separated by commas"
+// 	@Param expand query string false "Associations to include separated by commas: user, category, authors"
 // 	@Success 200 {object} models.Article
+// 	@Failure 400 {object} models.APIError
 // 	@Failure 404 {object} models.APIError
 // 	@Failure 500 {object} models.APIError
 // 	@Router /articles/{id} [get]
 func (s *Server) GetArticle(c *gin.Context) {
+	p := projection(c)
+	if err := checkProjection(p, models.Article{}, articleAssociations); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: err.Error()})
+		return
+	}
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "invalid id: " + err.Error()})
 		return
 	}
-	article, err := s.ArticlesRepo.GetArticle(uint(id))
-	if err == repository.ErrNotFound {
+	article, err := s.ArticlesRepo.GetArticle(c.Request.Context(), uint(id), p)
+	if err == nil {
+		err = s.loadUnpublishedAuthors(c.Request.Context(), article, p)
+	}
+	if err == repository.ErrNotFound || (err == nil && !s.canSeeArticle(c, article)) {
 		c.JSON(http.StatusNotFound, models.APIError{Code: http.StatusNotFound, Message: "category not found"})
 		return
 	}
@@ -67,7 +144,64 @@ func (s *Server) GetArticle(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, article)
+	if err := s.attachArticleSeries(c, article); err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: "could not get series of article"})
+		return
+	}
+	lastModified(c, article.UpdatedAt)
+	etagVersion(c, article.Version)
+	renderProjection(c, http.StatusOK, article, p, articleAssociations)
+}
+
+// GetArticleBySlug is the handler for GET requests to /articles/by-slug/:slug
+// 	@ID GetArticleBySlug
+// 	@Summary Get article by slug
+// 	@Description Get article with matching slug. Slugs articles had before
+// 	@Description being renamed redirect to their current slug.
+// 	@Tags articles
+// 	@Param slug path string true "Article slug"
+// 	@Param fields query string false "Fields to include separated by commas"
+// 	@Param expand query string false "Associations to include separated by commas: user, category, authors"
+// 	@Success 200 {object} models.Article
+// 	@Success 301 {object} string
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 404 {object} models.APIError
+// 	@Failure 500 {object} models.APIError
+// 	@Router /articles/by-slug/{slug} [get]
+func (s *Server) GetArticleBySlug(c *gin.Context) {
+	p := projection(c)
+	if err := checkProjection(p, models.Article{}, articleAssociations); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: err.Error()})
+		return
+	}
+	slug := c.Param("slug")
+	article, err := s.ArticlesRepo.GetArticleBySlug(c.Request.Context(), slug, p)
+	if err == nil {
+		err = s.loadUnpublishedAuthors(c.Request.Context(), article, p)
+	}
+	if err == repository.ErrNotFound || (err == nil && !s.canSeeArticle(c, article)) {
+		c.JSON(http.StatusNotFound, models.APIError{Code: http.StatusNotFound, Message: "article not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+	if article.Slug != slug {
+		location := "/v1/articles/by-slug/" + article.Slug
+		if c.Request.URL.RawQuery != "" {
+			location += "?" + c.Request.URL.RawQuery
+		}
+		c.Redirect(http.StatusMovedPermanently, location)
+		return
+	}
+	if err := s.attachArticleSeries(c, article); err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: "could not get series of article"})
+		return
+	}
+	lastModified(c, article.UpdatedAt)
+	etagVersion(c, article.Version)
+	renderProjection(c, http.StatusOK, article, p, articleAssociations)
 }
 
 // CreateArticles is the handler for POST requests to /articles
@@ -83,14 +217,17 @@ func (s *Server) GetArticle(c *gin.Context) {
 // 	@Failure 500 {object} models.APIError
 // 	@Router /articles [post]
 func (s *Server) CreateArticle(c *gin.Context) {
-	at := c.GetHeader(AccessTokenName)
-	au, err := s.userByAccessToken(at)
+	au, err := s.authenticate(c)
 	if err != nil {
 		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "you must be authenticated to create an article"})
 		return
 	}
-	if !(au.Role == models.RoleWriter || au.Role == models.RoleAdministrator) {
-		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "only Writers and Administrators can create articles"})
+	if !hasScope(c, models.ScopeArticlesWrite) {
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "api key lacks scope " + string(models.ScopeArticlesWrite)})
+		return
+	}
+	if !s.can(c.Request.Context(), au, models.PermissionArticlePublish) {
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "you don't have permission to publish articles"})
 		return
 	}
 	var ca CreateArticleDTO
@@ -99,13 +236,6 @@ func (s *Server) CreateArticle(c *gin.Context) {
 		return
 	}
 
-	// Verify that a category with matching ID exists
-	_, err = s.CategoriesRepo.GetCategory(ca.CategoryID)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "category with provided id could not be retrieved"})
-		return
-	}
-
 	article := &models.Article{
 		UserID:     au.ID,
 		CategoryID: ca.CategoryID,
@@ -113,8 +243,23 @@ func (s *Server) CreateArticle(c *gin.Context) {
 		Title:      ca.Title,
 		ImageURL:   ca.ImageURL,
 		Tags:       ca.Tags,
+		PublishAt:  ca.PublishAt,
+	}
+	// The category is checked in the same unit of work the article
+	// is created in, so that it can't be deleted in between
+	var categoryErr error
+	err = s.inUnitOfWork(c.Request.Context(), func(r repository.Repositories) error {
+		if _, categoryErr = r.Categories.GetCategory(c.Request.Context(), ca.CategoryID, repository.Projection{}); categoryErr != nil {
+			return categoryErr
+		}
+		var err error
+		article, err = r.Articles.CreateArticle(c.Request.Context(), article)
+		return err
+	})
+	if categoryErr != nil {
+		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "category with provided id could not be retrieved"})
+		return
 	}
-	article, err = s.ArticlesRepo.CreateArticle(article)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: "could not create article: " + err.Error()})
 		return
@@ -125,30 +270,67 @@ func (s *Server) CreateArticle(c *gin.Context) {
 // UpdateArticle is the handler for PUT requests to /articles
 // 	@ID UpdateArticle
 // 	@Summary Update article
-// 	@Description Updates a registered article.
+// 	@Description Updates a registered article, any of its authors can update it.
+// 	@Description If the article changed since the version in If-Match,
+// 	@Description it's not updated and its current version is returned.
 // 	@Tags articles
 // 	@Param id path int true "Article ID"
+// 	@Param If-Match header string false "ETag of the article being updated"
 // 	@Param article body UpdateArticleDTO true "Article"
 // 	@Security AccessToken
 // 	@Success 200 {object} models.Article
 // 	@Failure 400 {object} models.APIError
 // 	@Failure 403 {object} models.APIError
 // 	@Failure 404 {object} models.APIError
+// 	@Failure 412 {object} models.Article
 // 	@Failure 500 {object} models.APIError
 // 	@Router /articles/{id} [put]
 func (s *Server) UpdateArticle(c *gin.Context) {
-	at := c.GetHeader(AccessTokenName)
-	au, err := s.userByAccessToken(at)
+	s.updateArticle(c, false)
+}
+
+// PatchArticle is the handler for PATCH requests to /articles/:id
+// 	@ID PatchArticle
+// 	@Summary Patch article
+// 	@Description Changes only the fields of a registered article present in a
+// 	@Description JSON merge patch (application/merge-patch+json) or the fields
+// 	@Description changed by a JSON Patch (application/json-patch+json).
+// 	@Description Any of its authors can patch it.
+// 	@Tags articles
+// 	@Accept json
+// 	@Param id path int true "Article ID"
+// 	@Param If-Match header string false "ETag of the article being patched"
+// 	@Param patch body UpdateArticleDTO true "Article fields to change"
+// 	@Security AccessToken
+// 	@Success 200 {object} models.Article
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 403 {object} models.APIError
+// 	@Failure 404 {object} models.APIError
+// 	@Failure 412 {object} models.Article
+// 	@Failure 500 {object} models.APIError
+// 	@Router /articles/{id} [patch]
+func (s *Server) PatchArticle(c *gin.Context) {
+	s.updateArticle(c, true)
+}
+
+// updateArticle updates the article with the DTO in the request in c,
+// or with the DTO of the article patched with it if patch is true.
+func (s *Server) updateArticle(c *gin.Context, patch bool) {
+	au, err := s.authenticate(c)
 	if err != nil {
 		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "you must be authenticated to update an article"})
 		return
 	}
+	if !hasScope(c, models.ScopeArticlesWrite) {
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "api key lacks scope " + string(models.ScopeArticlesWrite)})
+		return
+	}
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "invalid id: " + err.Error()})
 		return
 	}
-	article, err := s.ArticlesRepo.GetArticle(uint(id))
+	article, err := s.ArticlesRepo.GetArticle(c.Request.Context(), uint(id), repository.WholeArticle)
 	if err == repository.ErrNotFound {
 		c.JSON(http.StatusNotFound, models.APIError{Code: http.StatusNotFound, Message: "article with provided id not found"})
 		return
@@ -158,29 +340,69 @@ func (s *Server) UpdateArticle(c *gin.Context) {
 		return
 	}
 
-	if article.UserID != au.ID {
-		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "you can only modify articles created by you"})
+	if !isArticleAuthor(article, au.ID) && !s.can(c.Request.Context(), au, models.PermissionArticleEditAny) {
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "you can only modify articles you are an author of"})
+		return
+	}
+	if !ifMatch(c, article.Version) {
+		c.JSON(http.StatusPreconditionFailed, article)
 		return
 	}
 
 	var ua UpdateArticleDTO
-	if err := c.ShouldBindJSON(&ua); err != nil {
+	if patch {
+		// PublishAt is left nil, so that the schedule
+		// is only changed if the patch sets it
+		ua = UpdateArticleDTO{
+			CategoryID: article.CategoryID,
+			Body:       article.Body,
+			Title:      article.Title,
+			ImageURL:   article.ImageURL,
+			Tags:       article.Tags,
+		}
+		err = bindPatch(c, &ua)
+	} else {
+		err = c.ShouldBindJSON(&ua)
+	}
+	if err != nil {
 		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "invalid article: " + err.Error()})
 		return
 	}
 
+	if ua.PublishAt != nil && article.PublishedAt != nil {
+		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "article is already published"})
+		return
+	}
+
+	before := *article
+	if ua.PublishAt != nil {
+		article.PublishAt = ua.PublishAt
+	}
 	article.CategoryID = ua.CategoryID
 	article.Body = ua.Body
 	article.Title = ua.Title
 	article.ImageURL = ua.ImageURL
 	article.Tags = ua.Tags
 
-	article, err = s.ArticlesRepo.UpdateArticle(article)
-
+	article, err = s.ArticlesRepo.UpdateArticle(c.Request.Context(), article)
+	if err == repository.ErrVersionConflict {
+		// Someone else updated the article since it was read
+		if current, err := s.ArticlesRepo.GetArticle(c.Request.Context(), uint(id), repository.WholeArticle); err == nil {
+			c.JSON(http.StatusPreconditionFailed, current)
+			return
+		}
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusBadRequest, Message: "could not save updated article: " + err.Error()})
 		return
 	}
+	if !isArticleAuthor(&before, au.ID) {
+		s.audit(c, au, models.AuditArticleUpdateOthers, "article", article.ID, before, article)
+	}
+	if err := s.attachArticleSeries(c, article); err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: "could not get series of article"})
+		return
+	}
 	c.JSON(http.StatusOK, article)
 }
 
@@ -197,35 +419,164 @@ func (s *Server) UpdateArticle(c *gin.Context) {
 // 	@Failure 500 {object} models.APIError
 // 	@Router /articles/{id} [delete]
 func (s *Server) DeleteArticle(c *gin.Context) {
-	at := c.GetHeader(AccessTokenName)
-	au, err := s.userByAccessToken(at)
+	au, err := s.authenticate(c)
 	if err != nil {
 		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "you must be authenticated to delete an article"})
 		return
 	}
+	if !hasScope(c, models.ScopeArticlesWrite) {
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "api key lacks scope " + string(models.ScopeArticlesWrite)})
+		return
+	}
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "invalid id: " + err.Error()})
 		return
 	}
 
-	article, err := s.ArticlesRepo.GetArticle(uint(id))
+	article, err := s.ArticlesRepo.GetArticle(c.Request.Context(), uint(id), repository.WholeArticle)
 	if err != nil {
 		c.JSON(http.StatusNotFound, models.APIError{Code: http.StatusNotFound, Message: err.Error()})
 		return
 	}
 
 	// If article doens't belong to authenticated user
-	// and authenticated user is not administrator
-	if !(article.UserID == au.ID || au.Role == models.RoleAdministrator) {
-		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "you are not authenticated as administrator or this article doesn't belong to you"})
+	// and authenticated user can't delete articles of others
+	if !(article.UserID == au.ID || s.can(c.Request.Context(), au, models.PermissionArticleDeleteAny)) {
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "you don't have permission to delete this article"})
 		return
 	}
 
-	err = s.ArticlesRepo.DeleteArticle(uint(id))
+	err = s.ArticlesRepo.DeleteArticle(c.Request.Context(), uint(id), au.ID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: "could not delete article: " + err.Error()})
 		return
 	}
+	if article.UserID != au.ID {
+		s.audit(c, au, models.AuditArticleDeleteOthers, "article", article.ID, article, nil)
+	}
 	c.String(http.StatusNoContent, "deleted")
 }
+
+// GetDeletedArticles is the handler for GET requests to /articles/trash
+// 	@ID GetDeletedArticles
+// 	@Summary Get articles in trash
+// 	@Description Get deleted articles that can still be restored.
+// 	@Description Users that can delete any article get all of them, other users only get their own.
+// 	@Tags articles
+// 	@Security AccessToken
+// 	@Success 200 {array} models.Article
+// 	@Failure 403 {object} models.APIError
+// 	@Failure 500 {object} models.APIError
+// 	@Router /articles/trash [get]
+func (s *Server) GetDeletedArticles(c *gin.Context) {
+	au, err := s.authenticate(c)
+	if err != nil {
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "you must be authenticated to get articles in trash"})
+		return
+	}
+	articles, err := s.ArticlesRepo.GetDeletedArticles(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: "could not get articles in trash"})
+		return
+	}
+	if !s.can(c.Request.Context(), au, models.PermissionArticleDeleteAny) {
+		own := make([]models.Article, 0, len(articles))
+		for _, a := range articles {
+			if a.UserID == au.ID {
+				own = append(own, a)
+			}
+		}
+		articles = own
+	}
+	c.JSON(http.StatusOK, articles)
+}
+
+// RestoreArticle is the handler for POST requests to /articles/:id/restore
+// 	@ID RestoreArticle
+// 	@Summary Restore article
+// 	@Description Take article with matching ID out of trash.
+// 	@Tags articles
+// 	@Param id path int true "Article ID"
+// 	@Security AccessToken
+// 	@Success 200 {object} models.Article
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 403 {object} models.APIError
+// 	@Failure 404 {object} models.APIError
+// 	@Failure 500 {object} models.APIError
+// 	@Router /articles/{id}/restore [post]
+func (s *Server) RestoreArticle(c *gin.Context) {
+	au, err := s.authenticate(c)
+	if err != nil {
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "you must be authenticated to restore an article"})
+		return
+	}
+	if !hasScope(c, models.ScopeArticlesWrite) {
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "api key lacks scope " + string(models.ScopeArticlesWrite)})
+		return
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "invalid id: " + err.Error()})
+		return
+	}
+	article, err := s.ArticlesRepo.GetDeletedArticle(c.Request.Context(), uint(id))
+	if err == repository.ErrNotFound {
+		c.JSON(http.StatusNotFound, models.APIError{Code: http.StatusNotFound, Message: "article not found in trash"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+	if !(article.UserID == au.ID || s.can(c.Request.Context(), au, models.PermissionArticleDeleteAny)) {
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "you don't have permission to restore this article"})
+		return
+	}
+	article, err = s.ArticlesRepo.RestoreArticle(c.Request.Context(), article.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: "could not restore article: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, article)
+}
+
+// loadUnpublishedAuthors loads the authors of a, loaded with p,
+// if it isn't published and p doesn't expand them, since
+// canSeeArticle needs them to tell whether it's visible.
+func (s *Server) loadUnpublishedAuthors(ctx context.Context, a *models.Article, p repository.Projection) error {
+	if a.PublishedAt != nil || p.Expands("authors") {
+		return nil
+	}
+	authors, err := s.ArticlesRepo.GetArticle(ctx, a.ID, repository.Projection{Fields: []string{"id"}, Expand: []string{"authors"}})
+	if err != nil {
+		return err
+	}
+	a.Authors = authors.Authors
+	return nil
+}
+
+// canSeeArticle reports whether the request in c can get a.
+// Articles that aren't published yet are only visible
+// to their author and users that can edit any article.
+func (s *Server) canSeeArticle(c *gin.Context, a *models.Article) bool {
+	if a.PublishedAt != nil {
+		return true
+	}
+	au, err := s.authenticate(c)
+	if err != nil {
+		return false
+	}
+	return s.articleVisibility(c.Request.Context(), au)(a)
+}
+
+// articleVisibility returns whether articles are visible to au,
+// as in canSeeArticle, nil if the request isn't authenticated.
+// It's meant for checking many articles with a single lookup
+// of the permissions of au.
+func (s *Server) articleVisibility(ctx context.Context, au *models.User) func(*models.Article) bool {
+	editAny := au != nil && s.can(ctx, au, models.PermissionArticleEditAny)
+	return func(a *models.Article) bool {
+		return a.PublishedAt != nil || editAny || (au != nil && isArticleAuthor(a, au.ID))
+	}
+}
@@ -0,0 +1,250 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/JonathanGzzBen/ingenialists/api/v1/models"
+	"github.com/JonathanGzzBen/ingenialists/api/v1/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// maxBulkArticles is the most articles a bulk operation can change
+const maxBulkArticles = 1000
+
+// Actions of bulk operations on articles
+const (
+	BulkChangeCategory = "change-category"
+	BulkAddTags        = "add-tags"
+	BulkRemoveTags     = "remove-tags"
+	BulkArchive        = "archive"
+	BulkDelete         = "delete"
+)
+
+// Results of each article in a bulk operation
+const (
+	BulkResultChanged   = "changed"
+	BulkResultUnchanged = "unchanged"
+	BulkResultNotFound  = "not_found"
+)
+
+type BulkArticlesDTO struct {
+	Action string `json:"action" binding:"required" enums:"change-category,add-tags,remove-tags,archive,delete"`
+	// IDs are the articles to operate on, use either IDs or Filter
+	IDs    []uint                 `json:"ids"`
+	Filter *BulkArticlesFilterDTO `json:"filter"`
+	// CategoryID is where change-category moves articles to
+	CategoryID uint `json:"categoryId"`
+	// Tags are added or removed by add-tags and remove-tags
+	Tags []string `json:"tags"`
+	// DryRun reports what would change without changing anything
+	DryRun bool `json:"dryRun"`
+}
+
+// BulkArticlesFilterDTO selects articles like the query parameters
+// of GET /articles, zero valued fields don't filter. At least
+// one of CategoryID, Status and AuthorID is required.
+type BulkArticlesFilterDTO struct {
+	CategoryID         uint   `json:"categoryId"`
+	IncludeDescendants bool   `json:"includeDescendants"`
+	Status             string `json:"status" enums:"published,scheduled,archived"`
+	AuthorID           uint   `json:"authorId"`
+}
+
+type BulkArticlesReportDTO struct {
+	Action string `json:"action"`
+	DryRun bool   `json:"dryRun"`
+	// Changed is how many articles were changed,
+	// or would be in a dry run
+	Changed int                    `json:"changed"`
+	Results []BulkArticleResultDTO `json:"results"`
+}
+
+type BulkArticleResultDTO struct {
+	ArticleID uint   `json:"articleId"`
+	Result    string `json:"result" enums:"changed,unchanged,not_found"`
+}
+
+// BulkArticles is the handler for POST requests to /articles/bulk
+// 	@ID BulkArticles
+// 	@Summary Change many articles
+// 	@Description Change category, add or remove tags, archive or delete articles
+// 	@Description listed by ID or matching a filter, reporting the result of each.
+// 	@Description Articles are changed in a single transaction, if any of them is
+// 	@Description not found none is changed and the report is returned with 422.
+// 	@Tags articles
+// 	@Security AccessToken
+// 	@Param operation body BulkArticlesDTO true "Bulk operation"
+// 	@Success 200 {object} BulkArticlesReportDTO
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 403 {object} models.APIError
+// 	@Failure 409 {object} models.APIError
+// 	@Failure 422 {object} BulkArticlesReportDTO
+// 	@Failure 500 {object} models.APIError
+// 	@Router /articles/bulk [post]
+func (s *Server) BulkArticles(c *gin.Context) {
+	au, err := s.authenticate(c)
+	if err != nil {
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "you must be authenticated to change articles in bulk"})
+		return
+	}
+	if !hasScope(c, models.ScopeArticlesWrite) {
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "api key lacks scope " + string(models.ScopeArticlesWrite)})
+		return
+	}
+	if !s.can(c.Request.Context(), au, models.PermissionArticleBulk) {
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "you don't have permission to change articles in bulk"})
+		return
+	}
+	var ba BulkArticlesDTO
+	if err := c.ShouldBindJSON(&ba); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "invalid bulk operation: " + err.Error()})
+		return
+	}
+
+	op := repository.BulkOperation{}
+	switch ba.Action {
+	case BulkChangeCategory:
+		if ba.CategoryID == 0 {
+			c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "categoryId is required to change category"})
+			return
+		}
+		if _, err := s.CategoriesRepo.GetCategory(c.Request.Context(), ba.CategoryID, repository.Projection{}); err != nil {
+			c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "category not found"})
+			return
+		}
+		op.CategoryID = ba.CategoryID
+	case BulkAddTags, BulkRemoveTags:
+		var tags []string
+		for _, t := range ba.Tags {
+			if t = strings.TrimSpace(t); t == "" || strings.Contains(t, ",") {
+				c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "tags can't be empty nor contain commas"})
+				return
+			}
+			tags = append(tags, t)
+		}
+		if len(tags) == 0 {
+			c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "tags are required to " + ba.Action})
+			return
+		}
+		if ba.Action == BulkAddTags {
+			op.AddTags = tags
+		} else {
+			op.RemoveTags = tags
+		}
+	case BulkArchive:
+		op.Archive = true
+	case BulkDelete:
+		op.Delete = true
+		op.DeletedBy = au.ID
+	default:
+		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "unknown action " + ba.Action})
+		return
+	}
+
+	ids, msg, err := s.bulkArticleIDs(c.Request.Context(), ba)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: "could not get articles"})
+		return
+	}
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: msg})
+		return
+	}
+
+	op.DryRun = ba.DryRun
+	results, err := s.ArticlesRepo.BulkUpdateArticles(c.Request.Context(), ids, op)
+	if err == repository.ErrVersionConflict {
+		c.JSON(http.StatusConflict, models.APIError{Code: http.StatusConflict, Message: "articles changed while being updated, no article was changed"})
+		return
+	}
+	if err != nil && err != repository.ErrNotFound {
+		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: "could not change articles, no article was changed"})
+		return
+	}
+	report := BulkArticlesReportDTO{Action: ba.Action, DryRun: ba.DryRun, Results: make([]BulkArticleResultDTO, len(results))}
+	for i, r := range results {
+		report.Results[i] = BulkArticleResultDTO{ArticleID: r.ArticleID, Result: BulkResultUnchanged}
+		switch {
+		case !r.Found:
+			report.Results[i].Result = BulkResultNotFound
+		case r.Changed:
+			report.Results[i].Result = BulkResultChanged
+			report.Changed++
+		}
+	}
+	if err == repository.ErrNotFound {
+		report.Changed = 0
+		c.JSON(http.StatusUnprocessableEntity, report)
+		return
+	}
+	if ba.DryRun || report.Changed == 0 {
+		c.JSON(http.StatusOK, report)
+		return
+	}
+	// Bulk operations have many targets,
+	// which are in the report
+	s.audit(c, au, models.AuditArticlesBulk, "article", 0, nil, report)
+	c.JSON(http.StatusOK, report)
+}
+
+// bulkArticleIDs returns the IDs of the articles ba operates on,
+// or a message saying why it doesn't select them correctly.
+func (s *Server) bulkArticleIDs(ctx context.Context, ba BulkArticlesDTO) ([]uint, string, error) {
+	if len(ba.IDs) > 0 && ba.Filter != nil {
+		return nil, "use either ids or filter, not both", nil
+	}
+	var ids []uint
+	seen := map[uint]bool{}
+	for _, id := range ba.IDs {
+		if !seen[id] {
+			ids = append(ids, id)
+		}
+		seen[id] = true
+	}
+	if ba.Filter != nil {
+		f := ba.Filter
+		if f.CategoryID == 0 && f.Status == "" && f.AuthorID == 0 {
+			return nil, "filter needs at least one of categoryId, status or authorId", nil
+		}
+		q := repository.ArticlesQuery{AuthorID: f.AuthorID}
+		switch f.Status {
+		case "":
+		case "published":
+			q.Published = true
+		case "scheduled":
+			q.Scheduled = true
+		case "archived":
+			q.Archived = true
+		default:
+			return nil, "invalid status: " + f.Status, nil
+		}
+		if f.CategoryID != 0 {
+			q.CategoryIDs = []uint{f.CategoryID}
+			if f.IncludeDescendants {
+				categories, err := s.CategoriesRepo.GetAllCategories(ctx, repository.Projection{})
+				if err != nil {
+					return nil, "", err
+				}
+				q.CategoryIDs = categoryDescendants(categories, f.CategoryID)
+			}
+		}
+		q.Fields = []string{"id"}
+		articles, err := s.ArticlesRepo.GetAllArticles(ctx, q)
+		if err != nil {
+			return nil, "", err
+		}
+		ids = make([]uint, len(articles))
+		for i, a := range articles {
+			ids[i] = a.ID
+		}
+	}
+	if len(ids) == 0 && ba.Filter == nil {
+		return nil, "ids or filter are required", nil
+	}
+	if len(ids) > maxBulkArticles {
+		return nil, "too many articles, at most 1000 can be changed at once", nil
+	}
+	return ids, "", nil
+}
@@ -0,0 +1,233 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/JonathanGzzBen/ingenialists/api/v1/models"
+	"github.com/JonathanGzzBen/ingenialists/api/v1/repository"
+	"github.com/JonathanGzzBen/ingenialists/api/v1/server"
+)
+
+// createArticles registers n articles in a new category
+func createArticles(t *testing.T, s *server.Server, n int) []*models.Article {
+	c, err := s.CategoriesRepo.CreateCategory(context.Background(), &models.Category{Name: "Databases", Slug: "databases"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var articles []*models.Article
+	for i := 0; i < n; i++ {
+		a, err := s.ArticlesRepo.CreateArticle(context.Background(), &models.Article{UserID: 1, CategoryID: c.ID, Title: "Article", Tags: "sql"})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		articles = append(articles, a)
+	}
+	return articles
+}
+
+func TestBulkArticlesAsEditorReturnForbidden(t *testing.T) {
+	t.Parallel()
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	articles := createArticles(t, s, 1)
+
+	res := doAs(t, ts, http.MethodPost, "/v1/articles/bulk", "Editor", server.BulkArticlesDTO{Action: server.BulkArchive, IDs: []uint{articles[0].ID}}, nil)
+	if res.StatusCode != http.StatusForbidden {
+		t.Fatalf("Expected status code %v, got %v", http.StatusForbidden, res.StatusCode)
+	}
+}
+
+func TestBulkChangeCategory(t *testing.T) {
+	t.Parallel()
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	articles := createArticles(t, s, 3)
+	target, err := s.CategoriesRepo.CreateCategory(context.Background(), &models.Category{Name: "Networks", Slug: "networks"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	ba := server.BulkArticlesDTO{
+		Action:     server.BulkChangeCategory,
+		IDs:        []uint{articles[0].ID, articles[1].ID},
+		CategoryID: target.ID,
+		DryRun:     true,
+	}
+
+	var report server.BulkArticlesReportDTO
+	res := doAs(t, ts, http.MethodPost, "/v1/articles/bulk", "Administrator", ba, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+	if err := json.NewDecoder(res.Body).Decode(&report); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if report.Changed != 2 || !report.DryRun {
+		t.Fatalf("Expected dry run changing 2 articles, got %+v", report)
+	}
+	if a, _ := s.ArticlesRepo.GetArticle(context.Background(), articles[0].ID, repository.WholeArticle); a.CategoryID == target.ID {
+		t.Fatalf("Expected dry run not to change articles")
+	}
+
+	ba.DryRun = false
+	res = doAs(t, ts, http.MethodPost, "/v1/articles/bulk", "Administrator", ba, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+	for i, a := range articles {
+		a, _ = s.ArticlesRepo.GetArticle(context.Background(), a.ID, repository.WholeArticle)
+		if moved := a.CategoryID == target.ID; moved != (i < 2) {
+			t.Fatalf("Expected only first 2 articles to be moved, article %v in category %v", a.ID, a.CategoryID)
+		}
+	}
+}
+
+func TestBulkArticlesWithMissingArticleChangesNothing(t *testing.T) {
+	t.Parallel()
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	articles := createArticles(t, s, 1)
+
+	var report server.BulkArticlesReportDTO
+	res := doAs(t, ts, http.MethodPost, "/v1/articles/bulk", "Administrator", server.BulkArticlesDTO{
+		Action: server.BulkArchive,
+		IDs:    []uint{articles[0].ID, 100},
+	}, nil)
+	if res.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("Expected status code %v, got %v", http.StatusUnprocessableEntity, res.StatusCode)
+	}
+	if err := json.NewDecoder(res.Body).Decode(&report); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := []server.BulkArticleResultDTO{
+		{ArticleID: articles[0].ID, Result: server.BulkResultChanged},
+		{ArticleID: 100, Result: server.BulkResultNotFound},
+	}
+	if len(report.Results) != 2 || report.Results[0] != expected[0] || report.Results[1] != expected[1] {
+		t.Fatalf("Expected %v, got %v", expected, report.Results)
+	}
+	if a, _ := s.ArticlesRepo.GetArticle(context.Background(), articles[0].ID, repository.WholeArticle); a.ArchivedAt != nil {
+		t.Fatalf("Expected article not to be archived")
+	}
+}
+
+func TestBulkTagsWithFilter(t *testing.T) {
+	t.Parallel()
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	articles := createArticles(t, s, 2)
+	filter := &server.BulkArticlesFilterDTO{CategoryID: articles[0].CategoryID}
+
+	var report server.BulkArticlesReportDTO
+	res := doAs(t, ts, http.MethodPost, "/v1/articles/bulk", "Administrator", server.BulkArticlesDTO{Action: server.BulkAddTags, Filter: filter, Tags: []string{"postgres", "sql"}}, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+	if err := json.NewDecoder(res.Body).Decode(&report); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if report.Changed != 2 {
+		t.Fatalf("Expected %v, got %v", 2, report.Changed)
+	}
+	a, _ := s.ArticlesRepo.GetArticle(context.Background(), articles[1].ID, repository.WholeArticle)
+	if a.Tags != "sql,postgres" {
+		t.Fatalf("Expected %v, got %v", "sql,postgres", a.Tags)
+	}
+
+	res = doAs(t, ts, http.MethodPost, "/v1/articles/bulk", "Administrator", server.BulkArticlesDTO{Action: server.BulkRemoveTags, Filter: filter, Tags: []string{"sql"}}, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+	a, _ = s.ArticlesRepo.GetArticle(context.Background(), articles[1].ID, repository.WholeArticle)
+	if a.Tags != "postgres" {
+		t.Fatalf("Expected %v, got %v", "postgres", a.Tags)
+	}
+
+	res = doAs(t, ts, http.MethodPost, "/v1/articles/bulk", "Administrator", server.BulkArticlesDTO{Action: server.BulkRemoveTags, Filter: filter, Tags: []string{"sql"}}, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+	if err := json.NewDecoder(res.Body).Decode(&report); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if report.Changed != 0 || report.Results[0].Result != server.BulkResultUnchanged {
+		t.Fatalf("Expected no article to change, got %+v", report)
+	}
+}
+
+func TestBulkArchiveAndDelete(t *testing.T) {
+	t.Parallel()
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	articles := createArticles(t, s, 2)
+
+	res := doAs(t, ts, http.MethodPost, "/v1/articles/bulk", "Administrator", server.BulkArticlesDTO{Action: server.BulkArchive, IDs: []uint{articles[0].ID}}, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+	var listed []models.Article
+	getJSON(t, ts, "/v1/articles/", &listed)
+	if len(listed) != 1 || listed[0].ID != articles[1].ID {
+		t.Fatalf("Expected only article %v to be listed, got %v", articles[1].ID, listed)
+	}
+	listed = nil
+	getJSON(t, ts, "/v1/articles/?status=archived", &listed)
+	if len(listed) != 1 || listed[0].ID != articles[0].ID {
+		t.Fatalf("Expected only article %v to be archived, got %v", articles[0].ID, listed)
+	}
+
+	res = doAs(t, ts, http.MethodPost, "/v1/articles/bulk", "Administrator", server.BulkArticlesDTO{Action: server.BulkDelete, IDs: []uint{articles[0].ID, articles[1].ID}}, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+	trash, err := s.ArticlesRepo.GetDeletedArticles(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(trash) != 2 {
+		t.Fatalf("Expected %v articles in trash, got %v", 2, len(trash))
+	}
+}
+
+func TestBulkArticlesWithInvalidOperationReturnBadRequest(t *testing.T) {
+	t.Parallel()
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	articles := createArticles(t, s, 1)
+	ids := []uint{articles[0].ID}
+
+	for _, ba := range []server.BulkArticlesDTO{
+		{Action: "publish", IDs: ids},
+		{Action: server.BulkArchive},
+		{Action: server.BulkArchive, IDs: ids, Filter: &server.BulkArticlesFilterDTO{}},
+		{Action: server.BulkChangeCategory, IDs: ids},
+		{Action: server.BulkChangeCategory, IDs: ids, CategoryID: 100},
+		{Action: server.BulkAddTags, IDs: ids, Tags: []string{"a,b"}},
+		{Action: server.BulkArchive, Filter: &server.BulkArticlesFilterDTO{Status: "draft"}},
+		{Action: server.BulkDelete, Filter: &server.BulkArticlesFilterDTO{}},
+		{Action: server.BulkDelete, Filter: &server.BulkArticlesFilterDTO{IncludeDescendants: true}},
+	} {
+		res := doAs(t, ts, http.MethodPost, "/v1/articles/bulk", "Administrator", ba, nil)
+		if res.StatusCode != http.StatusBadRequest {
+			t.Fatalf("Expected status code %v for %+v, got %v", http.StatusBadRequest, ba, res.StatusCode)
+		}
+	}
+	if _, err := s.ArticlesRepo.GetArticle(context.Background(), articles[0].ID, repository.Projection{}); err != nil {
+		t.Fatalf("Expected article not to be deleted, got %v", err)
+	}
+}
@@ -2,15 +2,20 @@ package server_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/JonathanGzzBen/ingenialists/api/v1/models"
+	"github.com/JonathanGzzBen/ingenialists/api/v1/repository"
 	"github.com/JonathanGzzBen/ingenialists/api/v1/repository/mocks"
 	"github.com/JonathanGzzBen/ingenialists/api/v1/server"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestGetAllArticles(t *testing.T) {
@@ -34,7 +39,7 @@ func TestGetAllArticles(t *testing.T) {
 	}
 
 	mockArticlesRepo := &mocks.ArticlesRepository{}
-	mockArticlesRepo.On("GetAllArticles").Return(mockArticles, nil)
+	mockArticlesRepo.On("GetAllArticles", mock.Anything, repository.ArticlesQuery{Published: true}).Return(mockArticles, nil)
 	s.ArticlesRepo = mockArticlesRepo
 
 	res, err := http.Get(fmt.Sprintf("%s/v1/articles", ts.URL))
@@ -71,12 +76,14 @@ func TestGetArticle(t *testing.T) {
 	ts := httptest.NewServer(s.Router)
 	defer ts.Close()
 
+	publishedAt := time.Now()
 	aToGet := &models.Article{
-		ID:    1,
-		Title: "First article",
+		ID:          1,
+		Title:       "First article",
+		PublishedAt: &publishedAt,
 	}
 	mockArticlesRepo := &mocks.ArticlesRepository{}
-	mockArticlesRepo.On("GetArticle", aToGet.ID).Return(aToGet, nil)
+	mockArticlesRepo.On("GetArticle", mock.Anything, aToGet.ID, mock.Anything).Return(aToGet, nil)
 
 	s.ArticlesRepo = mockArticlesRepo
 
@@ -209,10 +216,10 @@ func TestCreateArticleAsWriterReturnOk(t *testing.T) {
 	aCreated.ID = 1
 
 	mockArticlesRepo := &mocks.ArticlesRepository{}
-	mockArticlesRepo.On("CreateArticle", &aToCreate).Return(&aCreated, nil)
+	mockArticlesRepo.On("CreateArticle", mock.Anything, &aToCreate).Return(&aCreated, nil)
 	s.ArticlesRepo = mockArticlesRepo
 	mockCategoriesRepo := &mocks.CategoriesRepository{}
-	mockCategoriesRepo.On("GetCategory", aToCreate.CategoryID).Return(&c, nil)
+	mockCategoriesRepo.On("GetCategory", mock.Anything, aToCreate.CategoryID, mock.Anything).Return(&c, nil)
 	s.CategoriesRepo = mockCategoriesRepo
 
 	maJSONBytes, err := json.Marshal(aToCreate)
@@ -272,10 +279,10 @@ func TestCreateArticleAsAdministratorReturnOk(t *testing.T) {
 	aCreated.ID = 1
 
 	mockArticlesRepo := &mocks.ArticlesRepository{}
-	mockArticlesRepo.On("CreateArticle", &aToCreate).Return(&aCreated, nil)
+	mockArticlesRepo.On("CreateArticle", mock.Anything, &aToCreate).Return(&aCreated, nil)
 	s.ArticlesRepo = mockArticlesRepo
 	mockCategoriesRepo := &mocks.CategoriesRepository{}
-	mockCategoriesRepo.On("GetCategory", aToCreate.CategoryID).Return(&c, nil)
+	mockCategoriesRepo.On("GetCategory", mock.Anything, aToCreate.CategoryID, mock.Anything).Return(&c, nil)
 	s.CategoriesRepo = mockCategoriesRepo
 
 	maJSONBytes, err := json.Marshal(aToCreate)
@@ -418,8 +425,8 @@ func TestUpdateArticleAsWriterThatOwnsArticleReturnOk(t *testing.T) {
 	aUpdated.Title = "Article Updated"
 
 	mockArticlesRepo := &mocks.ArticlesRepository{}
-	mockArticlesRepo.On("GetArticle", aToUpdate.ID).Return(&aToUpdate, nil)
-	mockArticlesRepo.On("UpdateArticle", &aToUpdate).Return(&aUpdated, nil)
+	mockArticlesRepo.On("GetArticle", mock.Anything, aToUpdate.ID, mock.Anything).Return(&aToUpdate, nil)
+	mockArticlesRepo.On("UpdateArticle", mock.Anything, &aToUpdate).Return(&aUpdated, nil)
 	s.ArticlesRepo = mockArticlesRepo
 
 	mcJSONBytes, err := json.Marshal(aToUpdate)
@@ -465,7 +472,7 @@ func TestUpdateArticleAsWriterThatDoesNotOwnArticleReturnForbidden(t *testing.T)
 	aUpdated.Title = "Article Updated"
 
 	mockArticlesRepo := &mocks.ArticlesRepository{}
-	mockArticlesRepo.On("GetArticle", aToUpdate.ID).Return(&aToUpdate, nil)
+	mockArticlesRepo.On("GetArticle", mock.Anything, aToUpdate.ID, mock.Anything).Return(&aToUpdate, nil)
 	s.ArticlesRepo = mockArticlesRepo
 
 	mcJSONBytes, err := json.Marshal(aToUpdate)
@@ -511,8 +518,8 @@ func TestUpdateArticleAsAdministratorThatOwnsArticleReturnOk(t *testing.T) {
 	aUpdated.Title = "Article Updated"
 
 	mockArticlesRepo := &mocks.ArticlesRepository{}
-	mockArticlesRepo.On("GetArticle", aToUpdate.ID).Return(&aToUpdate, nil)
-	mockArticlesRepo.On("UpdateArticle", &aToUpdate).Return(&aUpdated, nil)
+	mockArticlesRepo.On("GetArticle", mock.Anything, aToUpdate.ID, mock.Anything).Return(&aToUpdate, nil)
+	mockArticlesRepo.On("UpdateArticle", mock.Anything, &aToUpdate).Return(&aUpdated, nil)
 	s.ArticlesRepo = mockArticlesRepo
 
 	mcJSONBytes, err := json.Marshal(aToUpdate)
@@ -558,8 +565,8 @@ func TestUpdateArticleAsAdministratorThatDoesNotOwnArticleReturnOk(t *testing.T)
 	aUpdated.Title = "Article Updated"
 
 	mockArticlesRepo := &mocks.ArticlesRepository{}
-	mockArticlesRepo.On("GetArticle", aToUpdate.ID).Return(&aToUpdate, nil)
-	mockArticlesRepo.On("UpdateArticle", &aToUpdate).Return(&aUpdated, nil)
+	mockArticlesRepo.On("GetArticle", mock.Anything, aToUpdate.ID, mock.Anything).Return(&aToUpdate, nil)
+	mockArticlesRepo.On("UpdateArticle", mock.Anything, &aToUpdate).Return(&aUpdated, nil)
 	s.ArticlesRepo = mockArticlesRepo
 
 	mcJSONBytes, err := json.Marshal(aToUpdate)
@@ -602,7 +609,7 @@ func TestDeleteArticleAsUnauthenticatedUserReturnForbidden(t *testing.T) {
 	}
 
 	mockArticlesRepo := &mocks.ArticlesRepository{}
-	mockArticlesRepo.On("GetArticle", aToDelete.ID).Return(&aToDelete, nil)
+	mockArticlesRepo.On("GetArticle", mock.Anything, aToDelete.ID, mock.Anything).Return(&aToDelete, nil)
 	s.ArticlesRepo = mockArticlesRepo
 
 	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/v1/articles/%d", ts.URL, aToDelete.ID), nil)
@@ -626,7 +633,7 @@ func TestDeleteArticleAsUnauthenticatedUserReturnForbidden(t *testing.T) {
 	}
 
 	// Verify that mockArticle is still in database
-	aInDB, err := s.ArticlesRepo.GetArticle(aToDelete.ID)
+	aInDB, err := s.ArticlesRepo.GetArticle(context.Background(), aToDelete.ID, repository.WholeArticle)
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -648,7 +655,7 @@ func TestDeleteArticleAsReaderReturnForbidden(t *testing.T) {
 	}
 
 	mockArticlesRepo := &mocks.ArticlesRepository{}
-	mockArticlesRepo.On("GetArticle", aToDelete.ID).Return(&aToDelete, nil)
+	mockArticlesRepo.On("GetArticle", mock.Anything, aToDelete.ID, mock.Anything).Return(&aToDelete, nil)
 	s.ArticlesRepo = mockArticlesRepo
 
 	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/v1/articles/%d", ts.URL, aToDelete.ID), nil)
@@ -686,7 +693,7 @@ func TestDeleteArticleAsWriterThatDoesNotOwnArticleReturnForbidden(t *testing.T)
 	}
 
 	mockArticlesRepo := &mocks.ArticlesRepository{}
-	mockArticlesRepo.On("GetArticle", aToDelete.ID).Return(&aToDelete, nil)
+	mockArticlesRepo.On("GetArticle", mock.Anything, aToDelete.ID, mock.Anything).Return(&aToDelete, nil)
 	s.ArticlesRepo = mockArticlesRepo
 
 	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/v1/articles/%d", ts.URL, aToDelete.ID), nil)
@@ -724,8 +731,8 @@ func TestDeleteArticleAsWriterThatOwnsArticleReturnNoContent(t *testing.T) {
 	}
 
 	mockArticlesRepo := &mocks.ArticlesRepository{}
-	mockArticlesRepo.On("GetArticle", aToDelete.ID).Return(&aToDelete, nil)
-	mockArticlesRepo.On("DeleteArticle", aToDelete.ID).Return(nil)
+	mockArticlesRepo.On("GetArticle", mock.Anything, aToDelete.ID, mock.Anything).Return(&aToDelete, nil)
+	mockArticlesRepo.On("DeleteArticle", mock.Anything, aToDelete.ID, uint(1)).Return(nil)
 	s.ArticlesRepo = mockArticlesRepo
 
 	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/v1/articles/%d", ts.URL, aToDelete.ID), nil)
@@ -763,8 +770,8 @@ func TestDeleteArticleAsAdministratorThatDoesNotOwnArticleReturnNoContent(t *tes
 	}
 
 	mockArticlesRepo := &mocks.ArticlesRepository{}
-	mockArticlesRepo.On("GetArticle", aToDelete.ID).Return(&aToDelete, nil)
-	mockArticlesRepo.On("DeleteArticle", aToDelete.ID).Return(nil)
+	mockArticlesRepo.On("GetArticle", mock.Anything, aToDelete.ID, mock.Anything).Return(&aToDelete, nil)
+	mockArticlesRepo.On("DeleteArticle", mock.Anything, aToDelete.ID, uint(1)).Return(nil)
 	s.ArticlesRepo = mockArticlesRepo
 
 	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/v1/articles/%d", ts.URL, aToDelete.ID), nil)
@@ -802,8 +809,8 @@ func TestDeleteArticleAsAdministratorThatOwnsArticleReturnNoContent(t *testing.T
 	}
 
 	mockArticlesRepo := &mocks.ArticlesRepository{}
-	mockArticlesRepo.On("GetArticle", aToDelete.ID).Return(&aToDelete, nil)
-	mockArticlesRepo.On("DeleteArticle", aToDelete.ID).Return(nil)
+	mockArticlesRepo.On("GetArticle", mock.Anything, aToDelete.ID, mock.Anything).Return(&aToDelete, nil)
+	mockArticlesRepo.On("DeleteArticle", mock.Anything, aToDelete.ID, uint(1)).Return(nil)
 	s.ArticlesRepo = mockArticlesRepo
 
 	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/v1/articles/%d", ts.URL, aToDelete.ID), nil)
@@ -827,3 +834,227 @@ func TestDeleteArticleAsAdministratorThatOwnsArticleReturnNoContent(t *testing.T
 		t.Fatalf("Expected \"text/plain; charset=utf-8\", got %s", val[0])
 	}
 }
+
+func TestCreateArticleWithRepeatedTitleGetsSuffixedSlug(t *testing.T) {
+	s := NewTestServer()
+
+	first := createArticle(t, s)
+	second, err := s.ArticlesRepo.CreateArticle(context.Background(), &models.Article{UserID: 1, CategoryID: first.CategoryID, Title: first.Title})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if first.Slug != "first-article" {
+		t.Fatalf("Expected %v, got %v", "first-article", first.Slug)
+	}
+	if second.Slug != "first-article-2" {
+		t.Fatalf("Expected %v, got %v", "first-article-2", second.Slug)
+	}
+}
+
+func TestGetArticleBySlug(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	a := createArticle(t, s)
+
+	res, err := http.Get(fmt.Sprintf("%s/v1/articles/by-slug/%s", ts.URL, a.Slug))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+	var resArticle models.Article
+	if err := json.NewDecoder(res.Body).Decode(&resArticle); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resArticle.ID != a.ID {
+		t.Fatalf("Expected %v, got %v", a.ID, resArticle.ID)
+	}
+}
+
+func TestGetArticleByPreviousSlugRedirectsToCurrentSlug(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	a := createArticle(t, s)
+	oldSlug := a.Slug
+	a.Title = "Renamed article"
+	a, err := s.ArticlesRepo.UpdateArticle(context.Background(), a)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if a.Slug != "renamed-article" {
+		t.Fatalf("Expected %v, got %v", "renamed-article", a.Slug)
+	}
+
+	client := ts.Client()
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	res, err := client.Get(fmt.Sprintf("%s/v1/articles/by-slug/%s", ts.URL, oldSlug))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode != http.StatusMovedPermanently {
+		t.Fatalf("Expected status code %v, got %v", http.StatusMovedPermanently, res.StatusCode)
+	}
+	if location := res.Header.Get("Location"); location != "/v1/articles/by-slug/renamed-article" {
+		t.Fatalf("Expected %v, got %v", "/v1/articles/by-slug/renamed-article", location)
+	}
+}
+
+// scheduleArticle registers an article written by user with ID = 1
+// to be published in an hour.
+func scheduleArticle(t *testing.T, s *server.Server) *models.Article {
+	a := createArticle(t, s)
+	publishAt := time.Now().Add(time.Hour)
+	a, err := s.ArticlesRepo.CreateArticle(context.Background(), &models.Article{UserID: 1, CategoryID: a.CategoryID, Title: "Embargoed review", PublishAt: &publishAt})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if a.PublishedAt != nil {
+		t.Fatalf("Expected article not to be published")
+	}
+	return a
+}
+
+func TestGetScheduledArticleAsAnotherUserReturnNotFound(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	a := scheduleArticle(t, s)
+
+	res, err := http.Get(fmt.Sprintf("%s/v1/articles/%d", ts.URL, a.ID))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode != http.StatusNotFound {
+		t.Fatalf("Expected status code %v, got %v", http.StatusNotFound, res.StatusCode)
+	}
+
+	res = doAs(t, ts, http.MethodGet, fmt.Sprintf("/v1/articles/%d", a.ID), "Writer", nil, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+}
+
+func TestGetAllArticlesOnlyReturnsPublishedArticles(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	scheduleArticle(t, s)
+
+	for path, expected := range map[string]int{
+		"/v1/articles/":                  1,
+		"/v1/articles/?status=scheduled": 1,
+	} {
+		res := doAs(t, ts, http.MethodGet, path, "Writer", nil, nil)
+		var articles []models.Article
+		if err := json.NewDecoder(res.Body).Decode(&articles); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(articles) != expected {
+			t.Fatalf("Expected %v articles for %v, got %v", expected, path, len(articles))
+		}
+	}
+}
+
+func TestPublishDueArticles(t *testing.T) {
+	s := NewTestServer()
+
+	a := scheduleArticle(t, s)
+
+	n, err := s.ArticlesRepo.PublishDueArticles(context.Background(), time.Now())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if n != 0 {
+		t.Fatalf("Expected %v, got %v", 0, n)
+	}
+
+	n, err = s.ArticlesRepo.PublishDueArticles(context.Background(), time.Now().Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("Expected %v, got %v", 1, n)
+	}
+	a, err = s.ArticlesRepo.GetArticle(context.Background(), a.ID, repository.WholeArticle)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if a.PublishedAt == nil || !a.PublishedAt.Equal(*a.PublishAt) {
+		t.Fatalf("Expected article to be published at %v, got %v", a.PublishAt, a.PublishedAt)
+	}
+}
+
+func TestUpdateArticleComputesSummary(t *testing.T) {
+	s := NewTestServer()
+
+	a := createArticle(t, s)
+	a.Body = "# Indexes\n\nA **B-tree** index keeps keys [sorted](https://example.com).\n\n" + strings.Repeat("word ", 400)
+	a, err := s.ArticlesRepo.UpdateArticle(context.Background(), a)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if a.WordCount != 407 {
+		t.Fatalf("Expected %v, got %v", 407, a.WordCount)
+	}
+	if a.ReadingTime != 3 {
+		t.Fatalf("Expected %v, got %v", 3, a.ReadingTime)
+	}
+	if !strings.HasPrefix(a.Excerpt, "Indexes A B-tree index keeps keys sorted. word") || !strings.HasSuffix(a.Excerpt, "…") {
+		t.Fatalf("Expected plain text excerpt, got %v", a.Excerpt)
+	}
+}
+
+func TestGetAllArticlesOnlyIncludesBodyWhenRequested(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	a := createArticle(t, s)
+	a.Body = "Article body"
+	if _, err := s.ArticlesRepo.UpdateArticle(context.Background(), a); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for query, expected := range map[string]string{
+		"":                     "",
+		"?fields=body,excerpt": "Article body",
+	} {
+		res, err := http.Get(fmt.Sprintf("%s/v1/articles/%s", ts.URL, query))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		var articles []models.Article
+		if err := json.NewDecoder(res.Body).Decode(&articles); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(articles) != 1 {
+			t.Fatalf("Expected %v, got %v", 1, len(articles))
+		}
+		if articles[0].Body != expected {
+			t.Fatalf("Expected body %q for %q, got %q", expected, query, articles[0].Body)
+		}
+		if articles[0].Excerpt != "Article body" {
+			t.Fatalf("Expected %v, got %v", "Article body", articles[0].Excerpt)
+		}
+	}
+}
+
+func TestGetAllArticlesWithCanceledContextReturnError(t *testing.T) {
+	s := NewTestServer()
+	createArticle(t, s)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := s.ArticlesRepo.GetAllArticles(ctx, repository.ArticlesQuery{}); err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+}
@@ -0,0 +1,113 @@
+package server
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/JonathanGzzBen/ingenialists/api/v1/models"
+	"github.com/JonathanGzzBen/ingenialists/api/v1/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// GetAuditEntries is the handler for GET requests to /audit
+// 	@ID GetAuditEntries
+// 	@Summary Get audit log
+// 	@Description Get privileged actions, newest first.
+// 	@Tags audit
+// 	@Security AccessToken
+// 	@Param actorId query int false "Filter by ID of user that performed the action"
+// 	@Param targetType query string false "Filter by type of target" example(category)
+// 	@Param targetId query int false "Filter by ID of target"
+// 	@Param from query string false "Only actions performed at or after this time" example(2006-01-02T15:04:05Z)
+// 	@Param to query string false "Only actions performed at or before this time" example(2006-01-02T15:04:05Z)
+// 	@Success 200 {array} models.AuditEntry
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 403 {object} models.APIError
+// 	@Failure 500 {object} models.APIError
+// 	@Router /audit [get]
+func (s *Server) GetAuditEntries(c *gin.Context) {
+	au, err := s.authenticate(c)
+	if err != nil {
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "you must be authenticated to read the audit log"})
+		return
+	}
+	if !s.can(c.Request.Context(), au, models.PermissionAuditRead) {
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "you don't have permission to read the audit log"})
+		return
+	}
+
+	var f repository.AuditFilter
+	if v := c.Query("actorId"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "invalid actorId: " + err.Error()})
+			return
+		}
+		f.ActorID = uint(id)
+	}
+	f.TargetType = c.Query("targetType")
+	if v := c.Query("targetId"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "invalid targetId: " + err.Error()})
+			return
+		}
+		f.TargetID = uint(id)
+	}
+	if v := c.Query("from"); v != "" {
+		f.From, err = time.Parse(time.RFC3339, v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "invalid from: " + err.Error()})
+			return
+		}
+	}
+	if v := c.Query("to"); v != "" {
+		f.To, err = time.Parse(time.RFC3339, v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "invalid to: " + err.Error()})
+			return
+		}
+	}
+
+	entries, err := s.AuditRepo.GetAuditEntries(c.Request.Context(), f)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: "could not get audit log"})
+		return
+	}
+	c.JSON(http.StatusOK, entries)
+}
+
+// audit records that actor performed action on target.
+//
+// before and after are snapshots of the target, nil
+// when it didn't exist before or after the action.
+// Failing to record an entry is logged, the action
+// it describes has already been performed.
+func (s *Server) audit(c *gin.Context, actor *models.User, action models.AuditAction, targetType string, targetID uint, before, after interface{}) {
+	e := &models.AuditEntry{
+		ActorID:    actor.ID,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Before:     snapshot(before),
+		After:      snapshot(after),
+		RequestID:  c.GetString(requestIDContextKey),
+	}
+	if _, err := s.AuditRepo.CreateAuditEntry(c.Request.Context(), e); err != nil {
+		log.Printf("could not record audit entry %s of %s %d: %v", action, targetType, targetID, err)
+	}
+}
+
+func snapshot(v interface{}) models.Snapshot {
+	if v == nil {
+		return ""
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return models.Snapshot(b)
+}
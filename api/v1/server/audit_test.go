@@ -0,0 +1,131 @@
+package server_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/JonathanGzzBen/ingenialists/api/v1/models"
+	"github.com/JonathanGzzBen/ingenialists/api/v1/server"
+)
+
+// getAuditEntries requests the audit log with query as Administrator
+func getAuditEntries(t *testing.T, ts *httptest.Server, query string) []models.AuditEntry {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/v1/audit?%s", ts.URL, query), nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req.Header.Add(server.AccessTokenName, "Administrator")
+	res, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+	var entries []models.AuditEntry
+	err = json.NewDecoder(res.Body).Decode(&entries)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return entries
+}
+
+func TestUpdateUserChangeRoleIsAudited(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	u := models.User{ID: 2, Name: "Second User", Role: models.RoleReader}
+	s.UsersRepo.CreateUser(context.Background(), &u)
+	uUpdated := u
+	uUpdated.Role = models.RoleWriter
+
+	muJSONBytes, err := json.Marshal(uUpdated)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("%s/v1/users/%d", ts.URL, u.ID), bytes.NewBuffer(muJSONBytes))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req.Header.Add(server.AccessTokenName, "Administrator")
+	req.Header.Add(server.RequestIDHeader, "role-change-request")
+	res, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+
+	entries := getAuditEntries(t, ts, fmt.Sprintf("targetType=user&targetId=%d", u.ID))
+	if len(entries) != 1 {
+		t.Fatalf("Expected %v, got %v", 1, len(entries))
+	}
+	e := entries[0]
+	if e.Action != models.AuditUserRoleUpdate {
+		t.Fatalf("Expected %v, got %v", models.AuditUserRoleUpdate, e.Action)
+	}
+	if e.RequestID != "role-change-request" {
+		t.Fatalf("Expected %v, got %v", "role-change-request", e.RequestID)
+	}
+	var before, after models.User
+	if err := json.Unmarshal([]byte(e.Before), &before); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := json.Unmarshal([]byte(e.After), &after); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if before.Role != models.RoleReader || after.Role != models.RoleWriter {
+		t.Fatalf("Expected role change from %v to %v, got %v to %v", models.RoleReader, models.RoleWriter, before.Role, after.Role)
+	}
+
+	// Actions of other actors are filtered out
+	entries = getAuditEntries(t, ts, "actorId=2")
+	if len(entries) != 0 {
+		t.Fatalf("Expected %v, got %v", 0, len(entries))
+	}
+}
+
+func TestGetAuditEntriesAsWriterReturnForbidden(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/v1/audit", ts.URL), nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req.Header.Add(server.AccessTokenName, "Writer")
+	res, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode != http.StatusForbidden {
+		t.Fatalf("Expected status code %v, got %v", http.StatusForbidden, res.StatusCode)
+	}
+}
+
+func TestGetAuditEntriesWithInvalidTimeReturnBadRequest(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/v1/audit?from=yesterday", ts.URL), nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req.Header.Add(server.AccessTokenName, "Administrator")
+	res, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected status code %v, got %v", http.StatusBadRequest, res.StatusCode)
+	}
+}
@@ -2,10 +2,15 @@ package server
 
 import (
 	"context"
+	"errors"
+	"log"
 	"net/http"
 	"net/url"
+	"strings"
+	"time"
 
 	"github.com/JonathanGzzBen/ingenialists/api/v1/models"
+	"github.com/JonathanGzzBen/ingenialists/api/v1/repository"
 	"github.com/gin-gonic/gin"
 	"golang.org/x/oauth2"
 )
@@ -14,11 +19,40 @@ var (
 	state             = "ingenialists"
 	googleUserInfoURL = "https://www.googleapis.com/oauth2/v3/userinfo"
 	AccessTokenName   = "AccessToken"
+	// APIKeyPrefix is the beginning of every API key, it's how
+	// API keys are told apart from OAuth2 access tokens.
+	APIKeyPrefix = "ing_"
 )
 
+var errInvalidAPIKey = errors.New("invalid api key")
+
+// apiKeyContextKey is where the APIKey used to authenticate
+// a request is stored in its gin.Context
+const apiKeyContextKey = "apiKey"
+
+// userContextKey is where the authenticated
+// user is stored in gin.Context
+const userContextKey = "user"
+
 type IOauthConfig interface {
 	AuthCodeURL(string, ...oauth2.AuthCodeOption) string
 	Exchange(context.Context, string, ...oauth2.AuthCodeOption) (*oauth2.Token, error)
+	TokenSource(context.Context, *oauth2.Token) oauth2.TokenSource
+}
+
+// TokenDTO is returned to clients after a successful login or refresh.
+//
+// AccessToken is the provider's access token, RefreshToken is
+// issued by this API and can be exchanged at /auth/refresh.
+type TokenDTO struct {
+	AccessToken  string    `json:"access_token"`
+	TokenType    string    `json:"token_type,omitempty"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	Expiry       time.Time `json:"expiry,omitempty"`
+}
+
+type RefreshTokenDTO struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
 }
 
 // CurrentUser is the handler for GET requests to /auth
@@ -29,8 +63,7 @@ type IOauthConfig interface {
 // 	@Security AccessToken
 // 	@Router /auth [get]
 func (s *Server) GetCurrentUser(c *gin.Context) {
-	at := c.GetHeader(AccessTokenName)
-	u, err := s.userByAccessToken(at)
+	u, err := s.authenticate(c)
 	if err != nil {
 		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "invalid access token"})
 		return
@@ -48,7 +81,7 @@ func (s *Server) LoginGoogle(c *gin.Context) {
 // GoogleCallback is the handler for GET requests to /auth/google-callback
 // it's part of Google OAuth2 flow.
 //
-// Returns user's token.
+// Returns user's access token and a refresh token issued by this API.
 func (s *Server) GoogleCallback(c *gin.Context) {
 	if c.Request.URL.Query().Get("state") != state {
 		c.JSON(http.StatusBadRequest, &models.APIError{Code: http.StatusBadRequest, Message: "state did not match"})
@@ -56,34 +89,257 @@ func (s *Server) GoogleCallback(c *gin.Context) {
 	}
 
 	authCode := c.Request.URL.Query().Get("code")
-	ctx := context.Background()
-	token, err := s.googleConfig.Exchange(ctx, authCode)
+	token, err := s.googleConfig.Exchange(c.Request.Context(), authCode)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, &models.APIError{Code: http.StatusBadRequest, Message: "failed to exchange token: " + err.Error()})
 		return
 	}
 
-	uinfo, err := s.googleClient.userInfoByAccessToken(token.AccessToken)
+	uinfo, err := s.googleClient.userInfoByAccessToken(c.Request.Context(), token.AccessToken)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, &models.APIError{Code: http.StatusBadRequest, Message: "failed to get user info: " + err.Error()})
 		return
 	}
 
-	_, err = s.UsersRepo.GetUserByGoogleSub(uinfo.Sub)
-	if err != nil {
-		u := &models.User{
+	var u *models.User
+	err = s.inUnitOfWork(c.Request.Context(), func(r repository.Repositories) error {
+		var err error
+		if u, err = r.Users.GetUserByGoogleSub(c.Request.Context(), uinfo.Sub); err == nil {
+			return nil
+		}
+		u, err = r.Users.CreateUser(c.Request.Context(), &models.User{
 			GoogleSub:         uinfo.Sub,
 			ProfilePictureURL: uinfo.Picture,
 			Name:              uinfo.Name,
+		})
+		return err
+	})
+	if err != nil {
+		// A concurrent login of the same user may have registered it
+		// first, Google subs are unique so this one couldn't
+		var lookupErr error
+		if u, lookupErr = s.UsersRepo.GetUserByGoogleSub(c.Request.Context(), uinfo.Sub); lookupErr != nil {
+			c.JSON(http.StatusInternalServerError, &models.APIError{Code: http.StatusInternalServerError, Message: "could not register user: " + err.Error()})
+			return
+		}
+	}
+
+	t := TokenDTO{
+		AccessToken: token.AccessToken,
+		TokenType:   token.TokenType,
+		Expiry:      token.Expiry,
+	}
+	// Google only returns a refresh token when the user grants offline access,
+	// without it there is nothing the client could refresh.
+	if token.RefreshToken != "" {
+		family, err := newRandomToken(16)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, &models.APIError{Code: http.StatusInternalServerError, Message: "could not issue refresh token"})
+			return
+		}
+		pt, err := encryptToken(s.tokenEncryptionKey, token.RefreshToken)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, &models.APIError{Code: http.StatusInternalServerError, Message: "could not issue refresh token"})
+			return
+		}
+		t.RefreshToken, err = s.issueRefreshToken(c.Request.Context(), u.ID, family, pt)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, &models.APIError{Code: http.StatusInternalServerError, Message: "could not issue refresh token: " + err.Error()})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, t)
+}
+
+// RefreshToken is the handler for POST requests to /auth/refresh
+// 	@ID RefreshToken
+// 	@Summary Refresh access token
+// 	@Description Exchange a refresh token for a new access token and a new refresh token.
+// 	@Description Refresh tokens can only be used once, reusing one revokes every token issued from the same login.
+// 	@Tags auth
+// 	@Param token body RefreshTokenDTO true "Refresh token"
+// 	@Success 200 {object} TokenDTO
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 403 {object} models.APIError
+// 	@Failure 500 {object} models.APIError
+// 	@Router /auth/refresh [post]
+func (s *Server) RefreshToken(c *gin.Context) {
+	var rt RefreshTokenDTO
+	if err := c.ShouldBindJSON(&rt); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "invalid refresh token request: " + err.Error()})
+		return
+	}
+
+	stored, err := s.RefreshTokensRepo.GetRefreshTokenByHash(c.Request.Context(), hashToken(rt.RefreshToken))
+	if err == repository.ErrNotFound {
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "invalid refresh token"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	// A token that was already rotated or revoked is being reused,
+	// it may have been stolen, so the whole family is revoked.
+	if stored.RotatedAt != nil || stored.RevokedAt != nil {
+		if err := s.RefreshTokensRepo.RevokeRefreshTokenFamily(c.Request.Context(), stored.Family); err != nil {
+			c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: err.Error()})
+			return
+		}
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "refresh token has already been used"})
+		return
+	}
+	if time.Now().After(stored.ExpiresAt) {
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "refresh token expired"})
+		return
+	}
+
+	prt, err := decryptToken(s.tokenEncryptionKey, stored.ProviderToken)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: "could not read provider refresh token"})
+		return
+	}
+	token, err := s.googleConfig.TokenSource(c.Request.Context(), &oauth2.Token{RefreshToken: prt}).Token()
+	if err != nil {
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "failed to refresh provider token: " + err.Error()})
+		return
+	}
+	// Google may rotate its own refresh token too
+	pt := stored.ProviderToken
+	if token.RefreshToken != "" && token.RefreshToken != prt {
+		pt, err = encryptToken(s.tokenEncryptionKey, token.RefreshToken)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: "could not issue refresh token"})
+			return
+		}
+	}
+
+	// Another request may have rotated the token since it was read
+	err = s.RefreshTokensRepo.RotateRefreshToken(c.Request.Context(), stored.ID)
+	if err == repository.ErrTokenUsed {
+		if err := s.RefreshTokensRepo.RevokeRefreshTokenFamily(c.Request.Context(), stored.Family); err != nil {
+			c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: err.Error()})
+			return
+		}
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "refresh token has already been used"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+	newToken, err := s.issueRefreshToken(c.Request.Context(), stored.UserID, stored.Family, pt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: "could not issue refresh token: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, TokenDTO{
+		AccessToken:  token.AccessToken,
+		TokenType:    token.TokenType,
+		RefreshToken: newToken,
+		Expiry:       token.Expiry,
+	})
+}
+
+// issueRefreshToken stores a new refresh token for user uid in family
+// and returns it, providerToken must already be encrypted.
+func (s *Server) issueRefreshToken(ctx context.Context, uid uint, family string, providerToken string) (string, error) {
+	token, err := newRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+	_, err = s.RefreshTokensRepo.CreateRefreshToken(ctx, &models.RefreshToken{
+		UserID:        uid,
+		Family:        family,
+		TokenHash:     hashToken(token),
+		ProviderToken: providerToken,
+		ExpiresAt:     time.Now().Add(s.refreshTokenTTL),
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// authenticate returns the user that made the request.
+//
+// The AccessToken header may hold either an OAuth2 access token
+// or an API key, when it's an API key it's stored in c
+// so that hasScope can check it. Requests are only authenticated
+// once, later calls return the same user.
+func (s *Server) authenticate(c *gin.Context) (*models.User, error) {
+	if u := currentUser(c); u != nil {
+		return u, nil
+	}
+	at := c.GetHeader(AccessTokenName)
+	if !strings.HasPrefix(at, APIKeyPrefix) {
+		u, err := s.userByAccessToken(c.Request.Context(), at)
+		if err != nil {
+			return nil, err
+		}
+		c.Set(userContextKey, u)
+		return u, nil
+	}
+	k, err := s.APIKeysRepo.GetAPIKeyByHash(c.Request.Context(), hashToken(at))
+	if err != nil {
+		return nil, errInvalidAPIKey
+	}
+	now := time.Now()
+	if k.RevokedAt != nil || (k.ExpiresAt != nil && now.After(*k.ExpiresAt)) {
+		return nil, errInvalidAPIKey
+	}
+	u, err := s.UsersRepo.GetUser(c.Request.Context(), k.UserID, repository.Projection{})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.APIKeysRepo.TouchAPIKey(c.Request.Context(), k.ID, now); err != nil {
+		log.Printf("Could not update last use of API key %d: %v", k.ID, err)
+	}
+	k.LastUsedAt = &now
+	c.Set(apiKeyContextKey, k)
+	c.Set(userContextKey, u)
+	return u, nil
+}
+
+// currentUser returns the user authenticated by a previous
+// call to authenticate, or nil if there is none.
+func currentUser(c *gin.Context) *models.User {
+	v, ok := c.Get(userContextKey)
+	if !ok {
+		return nil
+	}
+	return v.(*models.User)
+}
+
+// hasScope reports whether the request authenticated in c
+// is allowed to act in scope.
+//
+// Requests authenticated with an OAuth2 access token have every scope.
+func hasScope(c *gin.Context, scope models.Scope) bool {
+	v, ok := c.Get(apiKeyContextKey)
+	if !ok {
+		return true
+	}
+	for _, ks := range strings.Split(v.(*models.APIKey).Scopes, ",") {
+		if models.Scope(strings.TrimSpace(ks)) == scope {
+			return true
 		}
-		s.UsersRepo.CreateUser(u)
 	}
+	return false
+}
 
-	c.JSON(http.StatusOK, token)
+// authenticatedWithAPIKey reports whether the request
+// in c was authenticated with an API key.
+func authenticatedWithAPIKey(c *gin.Context) bool {
+	_, ok := c.Get(apiKeyContextKey)
+	return ok
 }
 
-func (s *Server) userByAccessToken(at string) (*models.User, error) {
-	ui, err := s.googleClient.userInfoByAccessToken(at)
+func (s *Server) userByAccessToken(ctx context.Context, at string) (*models.User, error) {
+	ui, err := s.googleClient.userInfoByAccessToken(ctx, at)
 	if err != nil {
 		return nil, err
 	}
@@ -92,6 +348,8 @@ func (s *Server) userByAccessToken(at string) (*models.User, error) {
 		switch at {
 		case "Administrator":
 			role = models.RoleAdministrator
+		case "Editor":
+			role = models.RoleEditor
 		case "Writer":
 			role = models.RoleWriter
 		default:
@@ -99,7 +357,7 @@ func (s *Server) userByAccessToken(at string) (*models.User, error) {
 		}
 		return &models.User{ID: 1, GoogleSub: ui.Sub, Name: ui.Name, Role: role}, nil
 	}
-	u, err := s.UsersRepo.GetUserByGoogleSub(ui.Sub)
+	u, err := s.UsersRepo.GetUserByGoogleSub(ctx, ui.Sub)
 	if err != nil {
 		return nil, err
 	}
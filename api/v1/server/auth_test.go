@@ -1,10 +1,14 @@
 package server_test
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+
+	"github.com/JonathanGzzBen/ingenialists/api/v1/server"
 )
 
 func TestGetCurrentUser(t *testing.T) {
@@ -32,3 +36,120 @@ func TestGetCurrentUser(t *testing.T) {
 		t.Fatalf("Expected \"application/json; charset=utf-7\", got %s", val[0])
 	}
 }
+
+// googleCallbackToken completes the OAuth2 flow against ts
+// and returns the token issued by the API.
+func googleCallbackToken(t *testing.T, ts *httptest.Server) server.TokenDTO {
+	res, err := http.Get(fmt.Sprintf("%s/v1/auth/google-callback?state=ingenialists&code=code", ts.URL))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+	var token server.TokenDTO
+	err = json.NewDecoder(res.Body).Decode(&token)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return token
+}
+
+func refreshToken(t *testing.T, ts *httptest.Server, rt string) *http.Response {
+	body, err := json.Marshal(server.RefreshTokenDTO{RefreshToken: rt})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	res, err := http.Post(fmt.Sprintf("%s/v1/auth/refresh", ts.URL), "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return res
+}
+
+func TestGoogleCallbackReturnsRefreshToken(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	token := googleCallbackToken(t, ts)
+	if token.AccessToken != "AccessToken" {
+		t.Fatalf("Expected %v, got %v", "AccessToken", token.AccessToken)
+	}
+	if token.RefreshToken == "" {
+		t.Fatalf("Expected refresh token to be set")
+	}
+	// Google's refresh token must never reach the client
+	if token.RefreshToken == "GoogleRefreshToken" {
+		t.Fatalf("Expected refresh token issued by API, got provider's")
+	}
+}
+
+func TestRefreshTokenRotatesToken(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	token := googleCallbackToken(t, ts)
+
+	res := refreshToken(t, ts, token.RefreshToken)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+	var refreshed server.TokenDTO
+	err := json.NewDecoder(res.Body).Decode(&refreshed)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if refreshed.AccessToken != "AccessToken" {
+		t.Fatalf("Expected %v, got %v", "AccessToken", refreshed.AccessToken)
+	}
+	if refreshed.RefreshToken == "" || refreshed.RefreshToken == token.RefreshToken {
+		t.Fatalf("Expected a new refresh token, got %q", refreshed.RefreshToken)
+	}
+
+	res = refreshToken(t, ts, refreshed.RefreshToken)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+}
+
+func TestRefreshTokenReuseRevokesFamily(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	token := googleCallbackToken(t, ts)
+
+	res := refreshToken(t, ts, token.RefreshToken)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+	var refreshed server.TokenDTO
+	err := json.NewDecoder(res.Body).Decode(&refreshed)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Reusing the first token must fail
+	res = refreshToken(t, ts, token.RefreshToken)
+	if res.StatusCode != http.StatusForbidden {
+		t.Fatalf("Expected status code %v, got %v", http.StatusForbidden, res.StatusCode)
+	}
+	// and revoke the token that was issued from it
+	res = refreshToken(t, ts, refreshed.RefreshToken)
+	if res.StatusCode != http.StatusForbidden {
+		t.Fatalf("Expected status code %v, got %v", http.StatusForbidden, res.StatusCode)
+	}
+}
+
+func TestRefreshTokenWithUnknownTokenReturnForbidden(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	res := refreshToken(t, ts, "unknown")
+	if res.StatusCode != http.StatusForbidden {
+		t.Fatalf("Expected status code %v, got %v", http.StatusForbidden, res.StatusCode)
+	}
+}
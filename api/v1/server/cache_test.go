@@ -0,0 +1,51 @@
+package server_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/JonathanGzzBen/ingenialists/api/v1/models"
+	"github.com/JonathanGzzBen/ingenialists/api/v1/repository"
+	"github.com/JonathanGzzBen/ingenialists/api/v1/server"
+)
+
+func TestCachedArticleIsInvalidatedByUpdates(t *testing.T) {
+	sc := NewTestConfig()
+	sc.Cache = &repository.CacheConfig{}
+	s := server.NewServer(sc)
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	if _, ok := s.ArticlesRepo.(*repository.CachedArticlesRepository); !ok {
+		t.Fatalf("Expected articles to be cached, got %T", s.ArticlesRepo)
+	}
+	a := createArticle(t, s)
+	path := fmt.Sprintf("/v1/articles/%d", a.ID)
+	var got models.Article
+	if status := getJSON(t, ts, path, &got); status != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, status)
+	}
+
+	res := doAs(t, ts, http.MethodPatch, path, "Writer", `{"title":"Renamed article"}`, map[string]string{"Content-Type": server.MergePatchContentType})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+	got = models.Article{}
+	getJSON(t, ts, path, &got)
+	if got.Title != "Renamed article" {
+		t.Fatalf("Expected %v, got %v", "Renamed article", got.Title)
+	}
+
+	// Articles are cached along with their category
+	res = doAs(t, ts, http.MethodPatch, fmt.Sprintf("/v1/categories/%d", a.CategoryID), "Administrator", `{"name":"Data"}`, map[string]string{"Content-Type": server.MergePatchContentType})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+	got = models.Article{}
+	getJSON(t, ts, path+"?expand=category", &got)
+	if got.Category == nil || got.Category.Name != "Data" {
+		t.Fatalf("Expected %v, got %v", "Data", got.Category)
+	}
+}
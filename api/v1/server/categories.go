@@ -1,6 +1,7 @@
 package server
 
 import (
+	"context"
 	"net/http"
 	"strconv"
 
@@ -10,30 +11,64 @@ import (
 )
 
 type CreateCategoryDTO struct {
+	ParentID *uint  `json:"parentId"`
 	Name     string `json:"name"`
-	ImageURL string `json:"imageUrl"`
+	// Slug is generated from Name if empty
+	Slug        string `json:"slug" example:"databases"`
+	Description string `json:"description"`
+	ImageURL    string `json:"imageUrl"`
+	SortOrder   int    `json:"sortOrder"`
 }
 
 type UpdateCategoryDTO struct {
+	ParentID *uint  `json:"parentId"`
 	Name     string `json:"name"`
-	ImageURL string `json:"imageUrl"`
+	// Slug is left unchanged if empty
+	Slug        string `json:"slug" example:"databases"`
+	Description string `json:"description"`
+	ImageURL    string `json:"imageUrl"`
+	SortOrder   int    `json:"sortOrder"`
+}
+
+// CategoryTreeDTO is a category along with its subcategories.
+type CategoryTreeDTO struct {
+	models.Category
+	Children []CategoryTreeDTO `json:"children"`
+}
+
+// CategoryDeletionReportDTO describes what happened to the articles
+// of a deleted category.
+type CategoryDeletionReportDTO struct {
+	CategoryID uint `json:"categoryId"`
+	// Action is either "reassign" or "trash"
+	Action           string `json:"action" example:"reassign"`
+	ReassignedTo     uint   `json:"reassignedTo,omitempty"`
+	AffectedArticles []uint `json:"affectedArticles"`
 }
 
 // GetAllCategories is the handler for GET requests to /categories
 // 	@ID GetAllCategories
 // 	@Summary Get all categories
-// 	@Description Get all registered categories.
+// 	@Description Get all registered categories sorted by sort order and name.
 // 	@Tags categories
+// 	@Param fields query string false "Fields to include separated by commas"
+// 	@Param expand query string false "Associations to include separated by commas: parent"
 // 	@Success 200 {array} models.Category
+// 	@Failure 400 {object} models.APIError
 // 	@Failure 500 {object} models.APIError
 // 	@Router /categories [get]
 func (s *Server) GetAllCategories(c *gin.Context) {
-	categories, err := s.CategoriesRepo.GetAllCategories()
+	p := projection(c)
+	if err := checkProjection(p, models.Category{}, categoryAssociations); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: err.Error()})
+		return
+	}
+	categories, err := s.CategoriesRepo.GetAllCategories(c.Request.Context(), p)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: "could not get categories"})
 		return
 	}
-	c.JSON(http.StatusOK, categories)
+	renderProjection(c, http.StatusOK, categories, p, categoryAssociations)
 }
 
 // GetCategory is the handler for GET requests to /categories/:id
@@ -42,17 +77,25 @@ func (s *Server) GetAllCategories(c *gin.Context) {
 // 	@Description Get category with matching ID.
 // 	@Tags categories
 // 	@Param id path int true "Category ID"
+// 	@Param fields query string false "Fields to include separated by commas"
+// 	@Param expand query string false "Associations to include separated by commas: parent"
 // 	@Success 200 {object} models.Category
+// 	@Failure 400 {object} models.APIError
 // 	@Failure 404 {object} models.APIError
 // 	@Failure 500 {object} models.APIError
 // 	@Router /categories/{id} [get]
 func (s *Server) GetCategory(c *gin.Context) {
+	p := projection(c)
+	if err := checkProjection(p, models.Category{}, categoryAssociations); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: err.Error()})
+		return
+	}
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "invalid id: " + err.Error()})
 		return
 	}
-	category, err := s.CategoriesRepo.GetCategory(uint(id))
+	category, err := s.CategoriesRepo.GetCategory(c.Request.Context(), uint(id), p)
 	if err == repositories.ErrNotFound {
 		c.JSON(http.StatusNotFound, models.APIError{Code: http.StatusNotFound, Message: "category not found"})
 		return
@@ -61,7 +104,60 @@ func (s *Server) GetCategory(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: "could not find category"})
 		return
 	}
-	c.JSON(http.StatusOK, category)
+	lastModified(c, category.UpdatedAt)
+	etagVersion(c, category.Version)
+	renderProjection(c, http.StatusOK, category, p, categoryAssociations)
+}
+
+// GetCategoryTree is the handler for GET requests to /categories/tree
+// 	@ID GetCategoryTree
+// 	@Summary Get category tree
+// 	@Description Get top level categories with their subcategories nested in children,
+// 	@Description siblings are sorted by sort order and name.
+// 	@Tags categories
+// 	@Success 200 {array} CategoryTreeDTO
+// 	@Failure 500 {object} models.APIError
+// 	@Router /categories/tree [get]
+func (s *Server) GetCategoryTree(c *gin.Context) {
+	categories, err := s.CategoriesRepo.GetAllCategories(c.Request.Context(), repositories.Projection{})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: "could not get categories"})
+		return
+	}
+	c.JSON(http.StatusOK, categoryTree(categories))
+}
+
+// GetCategoryBySlug is the handler for GET requests to /categories/by-slug/:slug
+// 	@ID GetCategoryBySlug
+// 	@Summary Get category by slug
+// 	@Description Get category with matching slug.
+// 	@Tags categories
+// 	@Param slug path string true "Category slug"
+// 	@Param fields query string false "Fields to include separated by commas"
+// 	@Param expand query string false "Associations to include separated by commas: parent"
+// 	@Success 200 {object} models.Category
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 404 {object} models.APIError
+// 	@Failure 500 {object} models.APIError
+// 	@Router /categories/by-slug/{slug} [get]
+func (s *Server) GetCategoryBySlug(c *gin.Context) {
+	p := projection(c)
+	if err := checkProjection(p, models.Category{}, categoryAssociations); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: err.Error()})
+		return
+	}
+	category, err := s.CategoriesRepo.GetCategoryBySlug(c.Request.Context(), c.Param("slug"), p)
+	if err == repositories.ErrNotFound {
+		c.JSON(http.StatusNotFound, models.APIError{Code: http.StatusNotFound, Message: "category not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: "could not find category"})
+		return
+	}
+	lastModified(c, category.UpdatedAt)
+	etagVersion(c, category.Version)
+	renderProjection(c, http.StatusOK, category, p, categoryAssociations)
 }
 
 // CreateCategory is the handler for POST requests to /categories
@@ -74,30 +170,62 @@ func (s *Server) GetCategory(c *gin.Context) {
 // 	@Success 200 {object} models.Category
 // 	@Failure 400 {object} models.APIError
 // 	@Failure 403 {object} models.APIError
+// 	@Failure 409 {object} models.APIError
 // 	@Failure 500 {object} models.APIError
 // 	@Router /categories [post]
 func (s *Server) CreateCategory(c *gin.Context) {
-	at := c.GetHeader(AccessTokenName)
-	u, err := s.userByAccessToken(at)
+	u, err := s.authenticate(c)
 	if err != nil {
 		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "you must be authenticated to create a category"})
 		return
 	}
-	if u.Role != models.RoleAdministrator {
-		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "only users with role Administrator can create categories"})
+	if !hasScope(c, models.ScopeCategoriesAdmin) {
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "api key lacks scope " + string(models.ScopeCategoriesAdmin)})
 		return
 	}
-	var category *models.Category
-	if err := c.ShouldBindJSON(&category); err != nil {
+	if !s.can(c.Request.Context(), u, models.PermissionCategoryManage) {
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "you don't have permission to create categories"})
+		return
+	}
+	var cc CreateCategoryDTO
+	if err := c.ShouldBindJSON(&cc); err != nil {
 		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusInternalServerError, Message: "invalid category"})
 		return
 	}
+
+	category := &models.Category{
+		ParentID:    cc.ParentID,
+		Name:        cc.Name,
+		Slug:        cc.Slug,
+		Description: cc.Description,
+		ImageURL:    cc.ImageURL,
+		SortOrder:   cc.SortOrder,
+	}
+	if category.Slug == "" {
+		category.Slug = models.Slugify(category.Name)
+	}
+	if category.Slug != models.Slugify(category.Slug) || category.Slug == "" {
+		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "invalid slug, use lowercase letters, digits and hyphens"})
+		return
+	}
+	if msg, err := s.invalidParent(c.Request.Context(), category.ID, category.ParentID); err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	} else if msg != "" {
+		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: msg})
+		return
+	}
 	// result := s.db.Create(&category)
-	category, err = s.CategoriesRepo.CreateCategory(category)
+	category, err = s.CategoriesRepo.CreateCategory(c.Request.Context(), category)
+	if err == repositories.ErrSlugTaken {
+		c.JSON(http.StatusConflict, models.APIError{Code: http.StatusConflict, Message: "slug is already in use"})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: "could not create category"})
 		return
 	}
+	s.audit(c, u, models.AuditCategoryCreate, "category", category.ID, nil, category)
 	c.JSON(http.StatusOK, category)
 }
 
@@ -105,25 +233,63 @@ func (s *Server) CreateCategory(c *gin.Context) {
 // 	@ID UpdateCategory
 // 	@Summary Update category
 // 	@Description Updates a registered category.
+// 	@Description If the category changed since the version in If-Match,
+// 	@Description it's not updated and its current version is returned.
 // 	@Tags categories
 // 	@Param id path int true "Category ID"
+// 	@Param If-Match header string false "ETag of the category being updated"
 // 	@Param category body UpdateCategoryDTO true "Category"
 // 	@Security AccessToken
 // 	@Success 200 {object} models.Category
 // 	@Failure 400 {object} models.APIError
 // 	@Failure 403 {object} models.APIError
 // 	@Failure 404 {object} models.APIError
+// 	@Failure 409 {object} models.APIError
+// 	@Failure 412 {object} models.Category
 // 	@Failure 500 {object} models.APIError
 // 	@Router /categories/{id} [put]
 func (s *Server) UpdateCategory(c *gin.Context) {
-	at := c.GetHeader(AccessTokenName)
-	u, err := s.userByAccessToken(at)
+	s.updateCategory(c, false)
+}
+
+// PatchCategory is the handler for PATCH requests to /categories/:id
+// 	@ID PatchCategory
+// 	@Summary Patch category
+// 	@Description Changes only the fields of a registered category present in a
+// 	@Description JSON merge patch (application/merge-patch+json) or the fields
+// 	@Description changed by a JSON Patch (application/json-patch+json).
+// 	@Tags categories
+// 	@Accept json
+// 	@Param id path int true "Category ID"
+// 	@Param If-Match header string false "ETag of the category being patched"
+// 	@Param patch body UpdateCategoryDTO true "Category fields to change"
+// 	@Security AccessToken
+// 	@Success 200 {object} models.Category
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 403 {object} models.APIError
+// 	@Failure 404 {object} models.APIError
+// 	@Failure 409 {object} models.APIError
+// 	@Failure 412 {object} models.Category
+// 	@Failure 500 {object} models.APIError
+// 	@Router /categories/{id} [patch]
+func (s *Server) PatchCategory(c *gin.Context) {
+	s.updateCategory(c, true)
+}
+
+// updateCategory updates the category with the DTO in the request in c,
+// or with the DTO of the category patched with it if patch is true.
+func (s *Server) updateCategory(c *gin.Context, patch bool) {
+	u, err := s.authenticate(c)
 	if err != nil {
 		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "you must be authenticated to update a category"})
 		return
 	}
-	if u.Role != models.RoleAdministrator {
-		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "only users with role Administrator can update categories"})
+	if !hasScope(c, models.ScopeCategoriesAdmin) {
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "api key lacks scope " + string(models.ScopeCategoriesAdmin)})
+		return
+	}
+	if !s.can(c.Request.Context(), u, models.PermissionCategoryManage) {
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "you don't have permission to update categories"})
 		return
 	}
 	id, err := strconv.Atoi(c.Param("id"))
@@ -132,7 +298,7 @@ func (s *Server) UpdateCategory(c *gin.Context) {
 		return
 	}
 	var category *models.Category
-	category, err = s.CategoriesRepo.GetCategory(uint(id))
+	category, err = s.CategoriesRepo.GetCategory(c.Request.Context(), uint(id), repositories.Projection{})
 	if err == repositories.ErrNotFound {
 		c.JSON(http.StatusNotFound, models.APIError{Code: http.StatusNotFound, Message: "category with provided id not found"})
 		return
@@ -141,20 +307,68 @@ func (s *Server) UpdateCategory(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusBadRequest, Message: err.Error()})
 		return
 	}
+	if !ifMatch(c, category.Version) {
+		c.JSON(http.StatusPreconditionFailed, category)
+		return
+	}
 
 	var cu UpdateCategoryDTO
-	if err := c.ShouldBindJSON(&cu); err != nil {
+	if patch {
+		cu = UpdateCategoryDTO{
+			ParentID:    category.ParentID,
+			Name:        category.Name,
+			Slug:        category.Slug,
+			Description: category.Description,
+			ImageURL:    category.ImageURL,
+			SortOrder:   category.SortOrder,
+		}
+		err = bindPatch(c, &cu)
+	} else {
+		err = c.ShouldBindJSON(&cu)
+	}
+	if err != nil {
 		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "invalid category: " + err.Error()})
 		return
 	}
 
+	if cu.Slug != "" && cu.Slug != models.Slugify(cu.Slug) {
+		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "invalid slug, use lowercase letters, digits and hyphens"})
+		return
+	}
+	if msg, err := s.invalidParent(c.Request.Context(), category.ID, cu.ParentID); err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	} else if msg != "" {
+		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: msg})
+		return
+	}
+
+	before := *category
+	category.ParentID = cu.ParentID
 	category.Name = cu.Name
+	if cu.Slug != "" {
+		category.Slug = cu.Slug
+	}
+	category.Description = cu.Description
 	category.ImageURL = cu.ImageURL
-	category, err = s.CategoriesRepo.UpdateCategory(category)
+	category.SortOrder = cu.SortOrder
+	category, err = s.CategoriesRepo.UpdateCategory(c.Request.Context(), category)
+	if err == repositories.ErrSlugTaken {
+		c.JSON(http.StatusConflict, models.APIError{Code: http.StatusConflict, Message: "slug is already in use"})
+		return
+	}
+	if err == repositories.ErrVersionConflict {
+		// Someone else updated the category since it was read
+		if current, err := s.CategoriesRepo.GetCategory(c.Request.Context(), uint(id), repositories.Projection{}); err == nil {
+			c.JSON(http.StatusPreconditionFailed, current)
+			return
+		}
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusBadRequest, Message: "could not save updated category"})
 		return
 	}
+	s.audit(c, u, models.AuditCategoryUpdate, "category", category.ID, before, category)
 	c.JSON(http.StatusOK, category)
 }
 
@@ -162,24 +376,33 @@ func (s *Server) UpdateCategory(c *gin.Context) {
 // 	@ID DeleteCategory
 // 	@Summary Delete category
 // 	@Description Delete category with matching ID.
+// 	@Description A category with articles is only deleted if reassignTo or cascade
+// 	@Description say what to do with them, the response then reports affected articles.
 // 	@Tags categories
 // 	@Param id path int true "Category ID"
+// 	@Param reassignTo query int false "ID of category to move articles to"
+// 	@Param cascade query string false "Move articles to trash along with category" Enums(trash)
 // 	@Security AccessToken
+// 	@Success 200 {object} CategoryDeletionReportDTO
 // 	@Success 204 {object} string
+// 	@Failure 400 {object} models.APIError
 // 	@Failure 403 {object} models.APIError
 // 	@Failure 404 {object} models.APIError
+// 	@Failure 409 {object} models.APIError
 // 	@Failure 500 {object} models.APIError
 // 	@Router /categories/{id} [delete]
 func (s *Server) DeleteCategory(c *gin.Context) {
-	at := c.GetHeader(AccessTokenName)
-	au, err := s.userByAccessToken(at)
+	au, err := s.authenticate(c)
 	if err != nil {
 		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "you must be authenticated to delete a category"})
 		return
 	}
-	// If authenticated user is not Administrator
-	if au.Role != models.RoleAdministrator {
-		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "you are not authenticated as administrator"})
+	if !hasScope(c, models.ScopeCategoriesAdmin) {
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "api key lacks scope " + string(models.ScopeCategoriesAdmin)})
+		return
+	}
+	if !s.can(c.Request.Context(), au, models.PermissionCategoryManage) {
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "you don't have permission to delete categories"})
 		return
 	}
 
@@ -189,16 +412,225 @@ func (s *Server) DeleteCategory(c *gin.Context) {
 		return
 	}
 
-	category, err := s.CategoriesRepo.GetCategory(uint(id))
+	var d repositories.CategoryDeletion
+	if rt := c.Query("reassignTo"); rt != "" {
+		reassignTo, err := strconv.Atoi(rt)
+		if err != nil || reassignTo <= 0 {
+			c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "invalid reassignTo: " + rt})
+			return
+		}
+		d.ReassignTo = uint(reassignTo)
+	}
+	if cascade := c.Query("cascade"); cascade != "" {
+		if cascade != "trash" {
+			c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "invalid cascade: only \"trash\" is supported"})
+			return
+		}
+		d.Cascade = true
+	}
+	if d.ReassignTo != 0 && d.Cascade {
+		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "reassignTo and cascade can't be used together"})
+		return
+	}
+	if d.ReassignTo == uint(id) {
+		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "can't reassign articles to the category being deleted"})
+		return
+	}
+
+	category, err := s.CategoriesRepo.GetCategory(c.Request.Context(), uint(id), repositories.Projection{})
 	if err == repositories.ErrNotFound {
 		c.JSON(http.StatusNotFound, models.APIError{Code: http.StatusNotFound, Message: "category not found"})
 		return
 	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+	if d.ReassignTo != 0 {
+		if _, err := s.CategoriesRepo.GetCategory(c.Request.Context(), d.ReassignTo, repositories.Projection{}); err != nil {
+			c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "category to reassign articles to not found"})
+			return
+		}
+	}
+
+	affected, err := s.CategoriesRepo.DeleteCategory(c.Request.Context(), category.ID, au.ID, d)
+	if err == repositories.ErrCategoryInUse {
+		c.JSON(http.StatusConflict, models.APIError{Code: http.StatusConflict, Message: "category has articles, use reassignTo or cascade=trash to delete it"})
+		return
+	}
+	if err == repositories.ErrNotFound {
+		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "category to reassign articles to not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: "could not delete category"})
+		return
+	}
+	if len(affected) == 0 {
+		s.audit(c, au, models.AuditCategoryDelete, "category", category.ID, category, nil)
+		c.String(http.StatusNoContent, "deleted")
+		return
+	}
+
+	report := CategoryDeletionReportDTO{
+		CategoryID:       category.ID,
+		Action:           "reassign",
+		ReassignedTo:     d.ReassignTo,
+		AffectedArticles: affected,
+	}
+	if d.Cascade {
+		report.Action = "trash"
+	}
+	s.audit(c, au, models.AuditCategoryDelete, "category", category.ID, category, report)
+	c.JSON(http.StatusOK, report)
+}
 
-	err = s.CategoriesRepo.DeleteCategory(category.ID)
+// GetDeletedCategories is the handler for GET requests to /categories/trash
+// 	@ID GetDeletedCategories
+// 	@Summary Get categories in trash
+// 	@Description Get deleted categories that can still be restored.
+// 	@Tags categories
+// 	@Security AccessToken
+// 	@Success 200 {array} models.Category
+// 	@Failure 403 {object} models.APIError
+// 	@Failure 500 {object} models.APIError
+// 	@Router /categories/trash [get]
+func (s *Server) GetDeletedCategories(c *gin.Context) {
+	au, err := s.authenticate(c)
+	if err != nil {
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "you must be authenticated to get categories in trash"})
+		return
+	}
+	if !s.can(c.Request.Context(), au, models.PermissionCategoryManage) {
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "you don't have permission to manage categories"})
+		return
+	}
+	categories, err := s.CategoriesRepo.GetDeletedCategories(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: "could not delete article"})
+		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: "could not get categories in trash"})
 		return
 	}
-	c.String(http.StatusNoContent, "deleted")
+	c.JSON(http.StatusOK, categories)
+}
+
+// RestoreCategory is the handler for POST requests to /categories/:id/restore
+// 	@ID RestoreCategory
+// 	@Summary Restore category
+// 	@Description Take category with matching ID out of trash.
+// 	@Tags categories
+// 	@Param id path int true "Category ID"
+// 	@Security AccessToken
+// 	@Success 200 {object} models.Category
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 403 {object} models.APIError
+// 	@Failure 404 {object} models.APIError
+// 	@Failure 500 {object} models.APIError
+// 	@Router /categories/{id}/restore [post]
+func (s *Server) RestoreCategory(c *gin.Context) {
+	au, err := s.authenticate(c)
+	if err != nil {
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "you must be authenticated to restore a category"})
+		return
+	}
+	if !hasScope(c, models.ScopeCategoriesAdmin) {
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "api key lacks scope " + string(models.ScopeCategoriesAdmin)})
+		return
+	}
+	if !s.can(c.Request.Context(), au, models.PermissionCategoryManage) {
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "you don't have permission to restore categories"})
+		return
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "invalid id: " + err.Error()})
+		return
+	}
+	category, err := s.CategoriesRepo.RestoreCategory(c.Request.Context(), uint(id))
+	if err == repositories.ErrNotFound {
+		c.JSON(http.StatusNotFound, models.APIError{Code: http.StatusNotFound, Message: "category not found in trash"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: "could not restore category: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, category)
+}
+
+// invalidParent returns why category with matching id
+// can't be a subcategory of category with parentID,
+// or an empty string if it can.
+func (s *Server) invalidParent(ctx context.Context, id uint, parentID *uint) (string, error) {
+	seen := map[uint]bool{}
+	for p := parentID; p != nil; {
+		if *p == id {
+			return "a category can't be a subcategory of itself or its subcategories", nil
+		}
+		if seen[*p] {
+			break
+		}
+		seen[*p] = true
+		parent, err := s.CategoriesRepo.GetCategory(ctx, *p, repositories.Projection{})
+		if err == repositories.ErrNotFound {
+			return "parent category not found", nil
+		}
+		if err != nil {
+			return "", err
+		}
+		p = parent.ParentID
+	}
+	return "", nil
+}
+
+// categoryTree nests categories under their parents, keeping their order.
+// Categories whose parent is not in categories are at the top level.
+func categoryTree(categories []models.Category) []CategoryTreeDTO {
+	ids := map[uint]bool{}
+	for _, category := range categories {
+		ids[category.ID] = true
+	}
+	children := map[uint][]models.Category{}
+	var roots []models.Category
+	for _, category := range categories {
+		if category.ParentID == nil || !ids[*category.ParentID] {
+			roots = append(roots, category)
+			continue
+		}
+		children[*category.ParentID] = append(children[*category.ParentID], category)
+	}
+	var build func([]models.Category) []CategoryTreeDTO
+	build = func(cs []models.Category) []CategoryTreeDTO {
+		nodes := make([]CategoryTreeDTO, 0, len(cs))
+		for _, category := range cs {
+			nodes = append(nodes, CategoryTreeDTO{
+				Category: category,
+				Children: build(children[category.ID]),
+			})
+		}
+		return nodes
+	}
+	return build(roots)
+}
+
+// categoryDescendants returns the ID of category with matching id
+// followed by the IDs of all its subcategories in categories.
+// Each ID is returned once, even if categories form a cycle.
+func categoryDescendants(categories []models.Category, id uint) []uint {
+	children := map[uint][]uint{}
+	for _, category := range categories {
+		if category.ParentID != nil {
+			children[*category.ParentID] = append(children[*category.ParentID], category.ID)
+		}
+	}
+	ids := []uint{id}
+	visited := map[uint]bool{id: true}
+	for i := 0; i < len(ids); i++ {
+		for _, child := range children[ids[i]] {
+			if !visited[child] {
+				visited[child] = true
+				ids = append(ids, child)
+			}
+		}
+	}
+	return ids
 }
@@ -2,6 +2,7 @@ package server_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -11,21 +12,23 @@ import (
 	"testing"
 
 	"github.com/JonathanGzzBen/ingenialists/api/v1/models"
+	"github.com/JonathanGzzBen/ingenialists/api/v1/repository"
 	"github.com/JonathanGzzBen/ingenialists/api/v1/repository/mocks"
 	"github.com/JonathanGzzBen/ingenialists/api/v1/server"
+	"github.com/stretchr/testify/mock"
 )
 
 // This data should not be modified, its purpose
 // is to be used to initialize database.
 var mockCategories = []models.Category{
-	{ID: 1231, Name: "First Category", ImageURL: "https://i.imgur.com/oCsJWt7.jpeg"},
-	{ID: 2131, Name: "Second Name", ImageURL: "https://i.imgur.com/oCsJWt7.jpeg"},
-	{ID: 56232, Name: "Third Name", ImageURL: "https://i.imgur.com/oCsJWt7.jpeg"},
+	{ID: 1231, Name: "First Category", Slug: "first-category", ImageURL: "https://i.imgur.com/oCsJWt7.jpeg"},
+	{ID: 2131, Name: "Second Name", Slug: "second-name", ImageURL: "https://i.imgur.com/oCsJWt7.jpeg"},
+	{ID: 56232, Name: "Third Name", Slug: "third-name", ImageURL: "https://i.imgur.com/oCsJWt7.jpeg"},
 }
 
 func TestGetAllCategories(t *testing.T) {
 	mockCategoriesRepo := &mocks.CategoriesRepository{}
-	mockCategoriesRepo.On("GetAllCategories").Return(mockCategories, nil)
+	mockCategoriesRepo.On("GetAllCategories", mock.Anything, repository.Projection{}).Return(mockCategories, nil)
 	s := NewTestServer()
 	s.CategoriesRepo = mockCategoriesRepo
 	ts := httptest.NewServer(s.Router)
@@ -67,7 +70,7 @@ func TestGetCategory(t *testing.T) {
 
 	mockCategory := &mockCategories[1]
 	mockCategoriesRepo := &mocks.CategoriesRepository{}
-	mockCategoriesRepo.On("GetCategory", mockCategory.ID).Return(mockCategory, nil)
+	mockCategoriesRepo.On("GetCategory", mock.Anything, mockCategory.ID, mock.Anything).Return(mockCategory, nil)
 
 	s.CategoriesRepo = mockCategoriesRepo
 
@@ -175,6 +178,33 @@ func TestCreateCategoryAsWriterReturnForbidden(t *testing.T) {
 	}
 }
 
+func TestCreateCategoryIgnoresFieldsNotInRequest(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	res := doAs(t, ts, http.MethodPost, "/v1/categories/", "Administrator", `{
+		"id": 42,
+		"name": "Software",
+		"version": 7,
+		"deletedAt": "2021-01-01T00:00:00Z",
+		"parent": {"name": "Engineering", "slug": "engineering"}
+	}`, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+	var created models.Category
+	if err := json.NewDecoder(res.Body).Decode(&created); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if created.ID == 42 || created.Version != 1 || created.DeletedAt.Valid || created.ParentID != nil {
+		t.Fatalf("Expected only fields of the request to be set, got %+v", created)
+	}
+	if _, err := s.CategoriesRepo.GetCategoryBySlug(context.Background(), "engineering", repository.Projection{}); err != repository.ErrNotFound {
+		t.Fatalf("Expected parent not to be created, got %v", err)
+	}
+}
+
 func TestCreateCategoryAsAdministratorReturnOk(t *testing.T) {
 	s := NewTestServer()
 	ts := httptest.NewServer(s.Router)
@@ -187,7 +217,7 @@ func TestCreateCategoryAsAdministratorReturnOk(t *testing.T) {
 	cCreated.ID = 1
 
 	mockCategoriesRepo := &mocks.CategoriesRepository{}
-	mockCategoriesRepo.On("CreateCategory", &cToCreate).Return(&cCreated, nil)
+	mockCategoriesRepo.On("CreateCategory", mock.Anything, &cToCreate).Return(&cCreated, nil)
 	s.CategoriesRepo = mockCategoriesRepo
 
 	mcJSONBytes, err := json.Marshal(cToCreate)
@@ -306,8 +336,8 @@ func TestUpdateCategoryAsAdministratorReturnOk(t *testing.T) {
 	cToUpdate.Name = "Category Updated"
 
 	mockCategoriesRepo := &mocks.CategoriesRepository{}
-	mockCategoriesRepo.On("UpdateCategory", &cToUpdate).Return(&cToUpdate, nil)
-	mockCategoriesRepo.On("GetCategory", cToUpdate.ID).Return(&cToUpdate, nil)
+	mockCategoriesRepo.On("UpdateCategory", mock.Anything, &cToUpdate).Return(&cToUpdate, nil)
+	mockCategoriesRepo.On("GetCategory", mock.Anything, cToUpdate.ID, mock.Anything).Return(&cToUpdate, nil)
 	s.CategoriesRepo = mockCategoriesRepo
 
 	mcJSONBytes, err := json.Marshal(cToUpdate)
@@ -413,8 +443,8 @@ func TestDeleteCategoryAsAdministratorReturnNoContent(t *testing.T) {
 	mockCategory := mockCategories[1]
 
 	mockCategoriesRepo := &mocks.CategoriesRepository{}
-	mockCategoriesRepo.On("DeleteCategory", mockCategory.ID).Return(nil)
-	mockCategoriesRepo.On("GetCategory", mockCategory.ID).Return(&mockCategory, nil)
+	mockCategoriesRepo.On("DeleteCategory", mock.Anything, mockCategory.ID, uint(1), repository.CategoryDeletion{}).Return([]uint{}, nil)
+	mockCategoriesRepo.On("GetCategory", mock.Anything, mockCategory.ID, mock.Anything).Return(&mockCategory, nil)
 	s.CategoriesRepo = mockCategoriesRepo
 
 	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/v1/categories/%d", ts.URL, mockCategory.ID), nil)
@@ -438,3 +468,244 @@ func TestDeleteCategoryAsAdministratorReturnNoContent(t *testing.T) {
 		t.Fatalf("Expected \"text/plain; charset=utf-8\", got %s", val[0])
 	}
 }
+
+func TestDeleteCategoryWithArticlesReturnConflict(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	a := createArticle(t, s)
+
+	res := doAs(t, ts, http.MethodDelete, fmt.Sprintf("/v1/categories/%d", a.CategoryID), "Administrator", nil, nil)
+	if res.StatusCode != http.StatusConflict {
+		t.Fatalf("Expected status code %v, got %v", http.StatusConflict, res.StatusCode)
+	}
+	if _, err := s.CategoriesRepo.GetCategory(context.Background(), a.CategoryID, repository.Projection{}); err != nil {
+		t.Fatalf("Expected category not to be deleted, got %v", err)
+	}
+}
+
+func TestDeleteCategoryReassigningArticlesReturnReport(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	a := createArticle(t, s)
+	target, err := s.CategoriesRepo.CreateCategory(context.Background(), &models.Category{Name: "Storage", Slug: "storage"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	res := doAs(t, ts, http.MethodDelete, fmt.Sprintf("/v1/categories/%d?reassignTo=%d", a.CategoryID, target.ID), "Administrator", nil, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+	var report server.CategoryDeletionReportDTO
+	if err := json.NewDecoder(res.Body).Decode(&report); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := server.CategoryDeletionReportDTO{
+		CategoryID:       a.CategoryID,
+		Action:           "reassign",
+		ReassignedTo:     target.ID,
+		AffectedArticles: []uint{a.ID},
+	}
+	if !reflect.DeepEqual(report, expected) {
+		t.Fatalf("Expected %v, got %v", expected, report)
+	}
+
+	a, err = s.ArticlesRepo.GetArticle(context.Background(), a.ID, repository.WholeArticle)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if a.CategoryID != target.ID {
+		t.Fatalf("Expected article to be in category %v, got %v", target.ID, a.CategoryID)
+	}
+}
+
+func TestDeleteCategoryReassigningToMissingCategoryReturnBadRequest(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	a := createArticle(t, s)
+
+	res := doAs(t, ts, http.MethodDelete, fmt.Sprintf("/v1/categories/%d?reassignTo=%d", a.CategoryID, a.CategoryID+100), "Administrator", nil, nil)
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected status code %v, got %v", http.StatusBadRequest, res.StatusCode)
+	}
+}
+
+func TestDeleteCategoryCascadingMovesArticlesToTrash(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	a := createArticle(t, s)
+
+	res := doAs(t, ts, http.MethodDelete, fmt.Sprintf("/v1/categories/%d?cascade=trash", a.CategoryID), "Administrator", nil, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+	if _, err := s.ArticlesRepo.GetArticle(context.Background(), a.ID, repository.WholeArticle); err != repository.ErrNotFound {
+		t.Fatalf("Expected %v, got %v", repository.ErrNotFound, err)
+	}
+	if _, err := s.ArticlesRepo.GetDeletedArticle(context.Background(), a.ID); err != nil {
+		t.Fatalf("Expected article to be in trash, got %v", err)
+	}
+}
+
+// createCategoryTree registers categories
+// Engineering > Software > Databases.
+func createCategoryTree(t *testing.T, s *server.Server) []*models.Category {
+	var categories []*models.Category
+	var parentID *uint
+	for _, name := range []string{"Engineering", "Software", "Databases"} {
+		c, err := s.CategoriesRepo.CreateCategory(context.Background(), &models.Category{ParentID: parentID, Name: name, Slug: models.Slugify(name)})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		categories = append(categories, c)
+		parentID = &c.ID
+	}
+	return categories
+}
+
+func TestGetCategoryTree(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	createCategoryTree(t, s)
+
+	res, err := http.Get(fmt.Sprintf("%s/v1/categories/tree", ts.URL))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+	var tree []server.CategoryTreeDTO
+	if err := json.NewDecoder(res.Body).Decode(&tree); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(tree) != 1 || len(tree[0].Children) != 1 || len(tree[0].Children[0].Children) != 1 {
+		t.Fatalf("Expected a single branch of three categories, got %v", tree)
+	}
+	if name := tree[0].Children[0].Children[0].Name; name != "Databases" {
+		t.Fatalf("Expected %v, got %v", "Databases", name)
+	}
+}
+
+func TestUpdateCategoryParentToDescendantReturnBadRequest(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	categories := createCategoryTree(t, s)
+
+	cu := server.UpdateCategoryDTO{ParentID: &categories[2].ID, Name: "Engineering"}
+	cuJSONBytes, err := json.Marshal(cu)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("%s/v1/categories/%d", ts.URL, categories[0].ID), bytes.NewBuffer(cuJSONBytes))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req.Header.Add(server.AccessTokenName, "Administrator")
+	res, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected status code %v, got %v", http.StatusBadRequest, res.StatusCode)
+	}
+}
+
+func TestGetCategoryBySlug(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	categories := createCategoryTree(t, s)
+
+	res, err := http.Get(fmt.Sprintf("%s/v1/categories/by-slug/software", ts.URL))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+	var category models.Category
+	if err := json.NewDecoder(res.Body).Decode(&category); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if category.ID != categories[1].ID {
+		t.Fatalf("Expected %v, got %v", categories[1].ID, category.ID)
+	}
+}
+
+func TestGetAllArticlesIncludingDescendantCategories(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	categories := createCategoryTree(t, s)
+	for _, category := range categories {
+		_, err := s.ArticlesRepo.CreateArticle(context.Background(), &models.Article{UserID: 1, CategoryID: category.ID, Title: category.Name})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	for query, expected := range map[string]int{
+		fmt.Sprintf("categoryId=%d", categories[1].ID):                         1,
+		fmt.Sprintf("categoryId=%d&includeDescendants=true", categories[1].ID): 2,
+		fmt.Sprintf("categoryId=%d&includeDescendants=true", categories[0].ID): 3,
+	} {
+		res, err := http.Get(fmt.Sprintf("%s/v1/articles?%s", ts.URL, query))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		var articles []models.Article
+		if err := json.NewDecoder(res.Body).Decode(&articles); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(articles) != expected {
+			t.Fatalf("Expected %v articles for %v, got %v", expected, query, len(articles))
+		}
+	}
+}
+
+func TestGetAllArticlesIncludingDescendantCategoriesWithCycle(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	categories := createCategoryTree(t, s)
+	for _, category := range categories {
+		_, err := s.ArticlesRepo.CreateArticle(context.Background(), &models.Article{UserID: 1, CategoryID: category.ID, Title: category.Name})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	// Categories saved before cycles were rejected may form one
+	root := *categories[0]
+	root.ParentID = &categories[2].ID
+	if _, err := s.CategoriesRepo.UpdateCategory(context.Background(), &root); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	res, err := http.Get(fmt.Sprintf("%s/v1/articles?categoryId=%d&includeDescendants=true", ts.URL, categories[1].ID))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var articles []models.Article
+	if err := json.NewDecoder(res.Body).Decode(&articles); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(articles) != 3 {
+		t.Fatalf("Expected %v articles, got %v", 3, len(articles))
+	}
+}
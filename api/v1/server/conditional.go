@@ -0,0 +1,160 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultCacheControl is the Cache-Control header of GET responses
+// of routes that ServerConfig.CacheControl doesn't list. Clients may
+// store responses but must revalidate them with their ETag before use.
+const defaultCacheControl = "no-cache"
+
+// versionContextKey is where the version of the resource
+// in a response is stored in gin.Context
+const versionContextKey = "version"
+
+// bufferedWriter holds the body of a response
+// until its ETag is known.
+type bufferedWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bufferedWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+// conditionalGET makes GET requests cacheable.
+//
+// Successful responses get a strong ETag, the hash of their body
+// preceded by the version of the resource if the handler called
+// etagVersion, and the Cache-Control header that cacheControl has for their route.
+// Requests whose If-None-Match matches the ETag, or that don't send
+// If-None-Match and whose If-Modified-Since is not before the
+// Last-Modified header set by the handler, get 304 Not Modified.
+func conditionalGET(cacheControl map[string]string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+		w := &bufferedWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+		c.Writer = w.ResponseWriter
+
+		if w.Status() == http.StatusOK {
+			cc, ok := cacheControl[c.FullPath()]
+			if !ok {
+				cc = defaultCacheControl
+			}
+			// Responses may depend on who makes the request,
+			// so shared caches must not give them to anyone else
+			if c.GetHeader(AccessTokenName) != "" {
+				cc = strings.Replace(cc, "public", "private", 1)
+			}
+			h := w.Header()
+			h.Set("Cache-Control", cc)
+			h.Add("Vary", AccessTokenName)
+			sum := sha256.Sum256(w.body.Bytes())
+			etag := hex.EncodeToString(sum[:16])
+			if v, ok := c.Get(versionContextKey); ok {
+				etag = fmt.Sprintf("%d-%s", v, etag)
+			}
+			etag = `"` + etag + `"`
+			h.Set("ETag", etag)
+			if notModified(c.Request, etag, h.Get("Last-Modified")) {
+				h.Del("Content-Type")
+				w.WriteHeader(http.StatusNotModified)
+				w.WriteHeaderNow()
+				return
+			}
+		}
+		w.ResponseWriter.Write(w.body.Bytes())
+	}
+}
+
+// notModified reports whether the representation of r with etag
+// and lastModified is already held by the client.
+func notModified(r *http.Request, etag string, lastModified string) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, t := range strings.Split(inm, ",") {
+			t = strings.TrimSpace(t)
+			// Weak comparison is used for If-None-Match
+			if t == "*" || strings.TrimPrefix(t, "W/") == etag {
+				return true
+			}
+		}
+		return false
+	}
+	ims, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	lm, err := http.ParseTime(lastModified)
+	if err != nil {
+		return false
+	}
+	return !lm.After(ims)
+}
+
+// etagVersion makes the ETag of the response in c start with
+// version, so that ifMatch can check it when the resource is updated.
+func etagVersion(c *gin.Context, version uint) {
+	c.Set(versionContextKey, version)
+}
+
+// ifMatch reports whether the If-Match header of the request in c,
+// if it has one, lists an ETag of a resource with version.
+func ifMatch(c *gin.Context, version uint) bool {
+	im := c.GetHeader("If-Match")
+	if im == "" {
+		return true
+	}
+	for _, t := range strings.Split(im, ",") {
+		t = strings.TrimSpace(t)
+		if t == "*" {
+			return true
+		}
+		// Strong comparison is used for If-Match,
+		// so weak ETags never match
+		if !strings.HasPrefix(t, `"`) {
+			continue
+		}
+		v, err := strconv.ParseUint(strings.SplitN(strings.Trim(t, `"`), "-", 2)[0], 10, 64)
+		if err == nil && uint(v) == version {
+			return true
+		}
+	}
+	return false
+}
+
+// lastModified sets the Last-Modified header of the response in c
+// to the latest of times. Zero times are ignored.
+//
+// Lists don't set it, removing an item from them
+// doesn't make any remaining item newer.
+func lastModified(c *gin.Context, times ...time.Time) {
+	var latest time.Time
+	for _, t := range times {
+		if t.After(latest) {
+			latest = t
+		}
+	}
+	if !latest.IsZero() {
+		c.Header("Last-Modified", latest.UTC().Format(http.TimeFormat))
+	}
+}
@@ -0,0 +1,199 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/JonathanGzzBen/ingenialists/api/v1/models"
+	"github.com/JonathanGzzBen/ingenialists/api/v1/repository"
+	"github.com/JonathanGzzBen/ingenialists/api/v1/server"
+)
+
+func TestGetArticleWithMatchingETagReturnsNotModified(t *testing.T) {
+	t.Parallel()
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	a := createArticle(t, s)
+	path := fmt.Sprintf("/v1/articles/%d", a.ID)
+
+	res, err := http.Get(ts.URL + path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	etag := res.Header.Get("ETag")
+	if etag == "" {
+		t.Fatalf("Expected ETag header")
+	}
+	if cc := res.Header.Get("Cache-Control"); cc != "no-cache" {
+		t.Fatalf("Expected Cache-Control %v, got %v", "no-cache", cc)
+	}
+
+	res = doAs(t, ts, http.MethodGet, path, "", nil, map[string]string{"If-None-Match": etag})
+	if res.StatusCode != http.StatusNotModified {
+		t.Fatalf("Expected status code %v, got %v", http.StatusNotModified, res.StatusCode)
+	}
+	body, _ := ioutil.ReadAll(res.Body)
+	if len(body) != 0 {
+		t.Fatalf("Expected empty body, got %s", body)
+	}
+
+	a.Title = "Renamed article"
+	if _, err := s.ArticlesRepo.UpdateArticle(context.Background(), a); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	res = doAs(t, ts, http.MethodGet, path, "", nil, map[string]string{"If-None-Match": etag})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+	if res.Header.Get("ETag") == etag {
+		t.Fatalf("Expected ETag to change after update")
+	}
+}
+
+func TestGetArticleNotModifiedSinceReturnsNotModified(t *testing.T) {
+	t.Parallel()
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	a := createArticle(t, s)
+	path := fmt.Sprintf("/v1/articles/%d", a.ID)
+
+	res, err := http.Get(ts.URL + path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	lm, err := http.ParseTime(res.Header.Get("Last-Modified"))
+	if err != nil {
+		t.Fatalf("Expected Last-Modified header, got %v", err)
+	}
+
+	res = doAs(t, ts, http.MethodGet, path, "", nil, map[string]string{"If-Modified-Since": lm.Format(http.TimeFormat)})
+	if res.StatusCode != http.StatusNotModified {
+		t.Fatalf("Expected status code %v, got %v", http.StatusNotModified, res.StatusCode)
+	}
+	res = doAs(t, ts, http.MethodGet, path, "", nil, map[string]string{"If-Modified-Since": lm.Add(-time.Second).Format(http.TimeFormat)})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+}
+
+func TestGetAllCategoriesHasETag(t *testing.T) {
+	t.Parallel()
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	createArticle(t, s)
+
+	res, err := http.Get(ts.URL + "/v1/categories/")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	etag := res.Header.Get("ETag")
+	if etag == "" {
+		t.Fatalf("Expected ETag header")
+	}
+	res = doAs(t, ts, http.MethodGet, "/v1/categories/", "", nil, map[string]string{"If-None-Match": `"other", W/` + etag})
+	if res.StatusCode != http.StatusNotModified {
+		t.Fatalf("Expected status code %v, got %v", http.StatusNotModified, res.StatusCode)
+	}
+}
+
+func TestUpdateArticleWithStaleIfMatchReturnPreconditionFailed(t *testing.T) {
+	t.Parallel()
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	a := createArticle(t, s)
+	path := fmt.Sprintf("/v1/articles/%d", a.ID)
+
+	res, err := http.Get(ts.URL + path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	etag := res.Header.Get("ETag")
+
+	a.Title = "Edited by first editor"
+	res = doAs(t, ts, http.MethodPut, path, "Writer", server.UpdateArticleDTO{CategoryID: a.CategoryID, Title: a.Title}, map[string]string{"If-Match": etag})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+
+	a.Title = "Edited by second editor"
+	res = doAs(t, ts, http.MethodPut, path, "Writer", server.UpdateArticleDTO{CategoryID: a.CategoryID, Title: a.Title}, map[string]string{"If-Match": etag})
+	if res.StatusCode != http.StatusPreconditionFailed {
+		t.Fatalf("Expected status code %v, got %v", http.StatusPreconditionFailed, res.StatusCode)
+	}
+	var current models.Article
+	if err := json.NewDecoder(res.Body).Decode(&current); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if current.Title != "Edited by first editor" || current.Version != 2 {
+		t.Fatalf("Expected current article at version 2, got %q at version %v", current.Title, current.Version)
+	}
+}
+
+func TestUpdateArticleWithWeakIfMatchReturnPreconditionFailed(t *testing.T) {
+	t.Parallel()
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	a := createArticle(t, s)
+	path := fmt.Sprintf("/v1/articles/%d", a.ID)
+
+	res := doAs(t, ts, http.MethodPut, path, "Writer", server.UpdateArticleDTO{CategoryID: a.CategoryID, Title: a.Title}, map[string]string{"If-Match": fmt.Sprintf(`W/"%d-0"`, a.Version)})
+	if res.StatusCode != http.StatusPreconditionFailed {
+		t.Fatalf("Expected status code %v, got %v", http.StatusPreconditionFailed, res.StatusCode)
+	}
+	res = doAs(t, ts, http.MethodPut, path, "Writer", server.UpdateArticleDTO{CategoryID: a.CategoryID, Title: a.Title}, map[string]string{"If-Match": "*"})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+}
+
+func TestUpdateStaleRecordReturnsVersionConflict(t *testing.T) {
+	t.Parallel()
+	s := NewTestServer()
+
+	a := createArticle(t, s)
+	first, _ := s.ArticlesRepo.GetArticle(context.Background(), a.ID, repository.WholeArticle)
+	second, _ := s.ArticlesRepo.GetArticle(context.Background(), a.ID, repository.WholeArticle)
+	if _, err := s.ArticlesRepo.UpdateArticle(context.Background(), first); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := s.ArticlesRepo.UpdateArticle(context.Background(), second); err != repository.ErrVersionConflict {
+		t.Fatalf("Expected %v, got %v", repository.ErrVersionConflict, err)
+	}
+
+	c, _ := s.CategoriesRepo.GetCategory(context.Background(), a.CategoryID, repository.Projection{})
+	stale := *c
+	if _, err := s.CategoriesRepo.UpdateCategory(context.Background(), c); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := s.CategoriesRepo.UpdateCategory(context.Background(), &stale); err != repository.ErrVersionConflict {
+		t.Fatalf("Expected %v, got %v", repository.ErrVersionConflict, err)
+	}
+
+	u, err := s.UsersRepo.CreateUser(context.Background(), &models.User{Name: "Testing User"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	staleUser := *u
+	if _, err := s.UsersRepo.UpdateUser(context.Background(), u); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := s.UsersRepo.UpdateUser(context.Background(), &staleUser); err != repository.ErrVersionConflict {
+		t.Fatalf("Expected %v, got %v", repository.ErrVersionConflict, err)
+	}
+}
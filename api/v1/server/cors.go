@@ -0,0 +1,164 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JonathanGzzBen/ingenialists/api/v1/models"
+	"github.com/gin-gonic/gin"
+)
+
+// defaultCORSMethods are the methods cross-origin requests
+// can use when CORSConfig doesn't specify AllowedMethods
+var defaultCORSMethods = []string{
+	http.MethodGet,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+}
+
+// defaultCORSHeaders are the headers cross-origin requests
+// can send when CORSConfig doesn't specify AllowedHeaders
+var defaultCORSHeaders = []string{
+	AccessTokenName,
+	"Content-Type",
+	"If-Match",
+	"If-None-Match",
+	"If-Modified-Since",
+	RequestIDHeader,
+}
+
+// defaultCORSExposedHeaders are the response headers scripts can read
+// when CORSConfig doesn't specify ExposedHeaders
+var defaultCORSExposedHeaders = []string{
+	"ETag",
+	"Last-Modified",
+	"Retry-After",
+	RequestIDHeader,
+	RateLimitLimitHeader,
+	RateLimitRemainingHeader,
+	RateLimitResetHeader,
+}
+
+// DevelopmentCORSConfig lets web clients served from any origin
+// call the API, it's used by development servers whose
+// ServerConfig doesn't specify CORS.
+var DevelopmentCORSConfig = CORSConfig{
+	AllowedOrigins: []string{"*"},
+	MaxAge:         10 * time.Minute,
+}
+
+// CORSConfig configures which origins browsers
+// let make requests to the API.
+type CORSConfig struct {
+	// AllowedOrigins are the origins allowed to make requests,
+	// like "https://ingenialists.com", "*" allows any origin
+	AllowedOrigins []string
+	// AllowedMethods are the methods requests can use,
+	// defaultCORSMethods if empty
+	AllowedMethods []string
+	// AllowedHeaders are the headers requests can send,
+	// defaultCORSHeaders if empty
+	AllowedHeaders []string
+	// ExposedHeaders are the response headers scripts can read,
+	// defaultCORSExposedHeaders if empty
+	ExposedHeaders []string
+	// AllowCredentials lets requests include cookies,
+	// it can't be used when AllowedOrigins has "*"
+	AllowCredentials bool
+	// MaxAge is how long browsers may cache preflight responses
+	MaxAge time.Duration
+}
+
+// allows reports whether requests from origin are allowed.
+func (cfg *CORSConfig) allows(origin string) bool {
+	for _, o := range cfg.AllowedOrigins {
+		if o == "*" || strings.EqualFold(o, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// anyOrigin reports whether requests from any origin are allowed.
+func (cfg *CORSConfig) anyOrigin() bool {
+	for _, o := range cfg.AllowedOrigins {
+		if o == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// validate returns an error if cfg would let any origin
+// read the responses to requests with credentials.
+func (cfg *CORSConfig) validate() error {
+	if cfg.anyOrigin() && cfg.AllowCredentials {
+		return errors.New("CORS credentials can't be allowed for any origin")
+	}
+	return nil
+}
+
+// joinOrDefault joins values, or defaults if values is empty.
+func joinOrDefault(values []string, defaults []string) string {
+	if len(values) == 0 {
+		values = defaults
+	}
+	return strings.Join(values, ", ")
+}
+
+// cors lets browsers make requests from the origins allowed by cfg.
+//
+// Preflight requests are answered by cors itself, with 204 No Content
+// if their origin is allowed and 403 Forbidden otherwise. Other requests
+// from origins that aren't allowed are served without CORS headers,
+// so browsers don't give their responses to scripts.
+func cors(cfg CORSConfig) gin.HandlerFunc {
+	methods := joinOrDefault(cfg.AllowedMethods, defaultCORSMethods)
+	headers := joinOrDefault(cfg.AllowedHeaders, defaultCORSHeaders)
+	exposed := joinOrDefault(cfg.ExposedHeaders, defaultCORSExposedHeaders)
+	maxAge := strconv.Itoa(int(cfg.MaxAge.Seconds()))
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+		h := c.Writer.Header()
+		h.Add("Vary", "Origin")
+		if !cfg.allows(origin) {
+			if preflight {
+				c.AbortWithStatusJSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "origin not allowed"})
+				return
+			}
+			c.Next()
+			return
+		}
+		if cfg.anyOrigin() {
+			h.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			h.Set("Access-Control-Allow-Origin", origin)
+		}
+		if cfg.AllowCredentials {
+			h.Set("Access-Control-Allow-Credentials", "true")
+		}
+		if preflight {
+			h.Add("Vary", "Access-Control-Request-Method")
+			h.Add("Vary", "Access-Control-Request-Headers")
+			h.Set("Access-Control-Allow-Methods", methods)
+			h.Set("Access-Control-Allow-Headers", headers)
+			if cfg.MaxAge > 0 {
+				h.Set("Access-Control-Max-Age", maxAge)
+			}
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+		h.Set("Access-Control-Expose-Headers", exposed)
+		c.Next()
+	}
+}
@@ -0,0 +1,147 @@
+package server_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/JonathanGzzBen/ingenialists/api/v1/server"
+)
+
+// newCORSServer returns a test server that only allows
+// requests from https://ingenialists.com.
+func newCORSServer(credentials bool) *server.Server {
+	sc := NewTestConfig()
+	sc.CORS = &server.CORSConfig{
+		AllowedOrigins:   []string{"https://ingenialists.com"},
+		AllowCredentials: credentials,
+		MaxAge:           time.Hour,
+	}
+	return server.NewServer(sc)
+}
+
+// corsHeaders returns the headers of a request from origin,
+// asking to make a request with requestMethod if not empty.
+func corsHeaders(origin string, requestMethod string) map[string]string {
+	h := map[string]string{"Origin": origin}
+	if requestMethod != "" {
+		h["Access-Control-Request-Method"] = requestMethod
+		h["Access-Control-Request-Headers"] = server.AccessTokenName
+	}
+	return h
+}
+
+func TestCORSPreflightReturnNoContent(t *testing.T) {
+	t.Parallel()
+	s := newCORSServer(false)
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	res := doAs(t, ts, http.MethodOptions, "/v1/articles/", "", nil, corsHeaders("https://ingenialists.com", http.MethodPost))
+
+	if res.StatusCode != http.StatusNoContent {
+		t.Fatalf("Expected status code %v, got %v", http.StatusNoContent, res.StatusCode)
+	}
+	if o := res.Header.Get("Access-Control-Allow-Origin"); o != "https://ingenialists.com" {
+		t.Fatalf("Expected %v, got %v", "https://ingenialists.com", o)
+	}
+	if m := res.Header.Get("Access-Control-Allow-Methods"); !strings.Contains(m, http.MethodPost) {
+		t.Fatalf("Expected %v to contain %v", m, http.MethodPost)
+	}
+	if h := res.Header.Get("Access-Control-Allow-Headers"); !strings.Contains(h, server.AccessTokenName) {
+		t.Fatalf("Expected %v to contain %v", h, server.AccessTokenName)
+	}
+	if a := res.Header.Get("Access-Control-Max-Age"); a != "3600" {
+		t.Fatalf("Expected %v, got %v", "3600", a)
+	}
+	if c := res.Header.Get("Access-Control-Allow-Credentials"); c != "" {
+		t.Fatalf("Expected no credentials header, got %v", c)
+	}
+}
+
+func TestCORSPreflightWithDisallowedOriginReturnForbidden(t *testing.T) {
+	t.Parallel()
+	s := newCORSServer(false)
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	res := doAs(t, ts, http.MethodOptions, "/v1/articles/", "", nil, corsHeaders("https://example.com", http.MethodPost))
+
+	if res.StatusCode != http.StatusForbidden {
+		t.Fatalf("Expected status code %v, got %v", http.StatusForbidden, res.StatusCode)
+	}
+	if o := res.Header.Get("Access-Control-Allow-Origin"); o != "" {
+		t.Fatalf("Expected no allowed origin, got %v", o)
+	}
+}
+
+func TestCORSRequestExposeHeaders(t *testing.T) {
+	t.Parallel()
+	s := newCORSServer(true)
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	res := doAs(t, ts, http.MethodGet, "/v1/categories/", "", nil, corsHeaders("https://ingenialists.com", ""))
+
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+	if o := res.Header.Get("Access-Control-Allow-Origin"); o != "https://ingenialists.com" {
+		t.Fatalf("Expected %v, got %v", "https://ingenialists.com", o)
+	}
+	if c := res.Header.Get("Access-Control-Allow-Credentials"); c != "true" {
+		t.Fatalf("Expected %v, got %v", "true", c)
+	}
+	if e := res.Header.Get("Access-Control-Expose-Headers"); !strings.Contains(e, "ETag") {
+		t.Fatalf("Expected %v to contain %v", e, "ETag")
+	}
+}
+
+func TestCORSRequestWithDisallowedOriginHasNoHeaders(t *testing.T) {
+	t.Parallel()
+	s := newCORSServer(false)
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	res := doAs(t, ts, http.MethodGet, "/v1/categories/", "", nil, corsHeaders("https://example.com", ""))
+
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+	if o := res.Header.Get("Access-Control-Allow-Origin"); o != "" {
+		t.Fatalf("Expected no allowed origin, got %v", o)
+	}
+}
+
+func TestCORSDevelopmentAllowAnyOrigin(t *testing.T) {
+	t.Parallel()
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	res := doAs(t, ts, http.MethodOptions, "/v1/categories/", "", nil, corsHeaders("http://localhost:3000", http.MethodGet))
+
+	if res.StatusCode != http.StatusNoContent {
+		t.Fatalf("Expected status code %v, got %v", http.StatusNoContent, res.StatusCode)
+	}
+	if o := res.Header.Get("Access-Control-Allow-Origin"); o != "*" {
+		t.Fatalf("Expected %v, got %v", "*", o)
+	}
+}
+
+func TestNewServerWithCredentialsForAnyOriginPanics(t *testing.T) {
+	t.Parallel()
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("Expected NewServer to panic")
+		}
+	}()
+	sc := NewTestConfig()
+	sc.CORS = &server.CORSConfig{
+		AllowedOrigins:   []string{"https://ingenialists.com", "*"},
+		AllowCredentials: true,
+	}
+	server.NewServer(sc)
+}
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -18,14 +19,18 @@ type googleUserInfoResponse struct {
 }
 
 type IGoogleClient interface {
-	userInfoByAccessToken(string) (*googleUserInfoResponse, error)
+	userInfoByAccessToken(context.Context, string) (*googleUserInfoResponse, error)
 }
 type GoogleClient struct{}
 type GoogleClientMock struct{}
 
 // userInfoByAccessToken returns userInfo
-func (g *GoogleClient) userInfoByAccessToken(at string) (*googleUserInfoResponse, error) {
-	response, err := http.Get(googleUserInfoURL + "?access_token=" + at)
+func (g *GoogleClient) userInfoByAccessToken(ctx context.Context, at string) (*googleUserInfoResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, googleUserInfoURL+"?access_token="+at, nil)
+	if err != nil {
+		return nil, err
+	}
+	response, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -39,9 +44,9 @@ func (g *GoogleClient) userInfoByAccessToken(at string) (*googleUserInfoResponse
 }
 
 // userInfoByAccessToken returns userInfo
-func (g *GoogleClientMock) userInfoByAccessToken(at string) (*googleUserInfoResponse, error) {
+func (g *GoogleClientMock) userInfoByAccessToken(ctx context.Context, at string) (*googleUserInfoResponse, error) {
 	switch at {
-	case "AccessToken", "Administrator", "Writer", "Reader":
+	case "AccessToken", "Administrator", "Editor", "Writer", "Reader":
 		return &googleUserInfoResponse{
 			Sub:  "123123213",
 			Name: "Mock User",
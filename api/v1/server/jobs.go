@@ -0,0 +1,68 @@
+package server
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// defaultTrashRetention is used when ServerConfig
+// doesn't specify TrashRetention
+const defaultTrashRetention = 30 * 24 * time.Hour
+
+// trashPurgeInterval is how often trash is checked
+// for items older than the retention period
+const trashPurgeInterval = time.Hour
+
+// publishInterval is how often scheduled articles
+// are checked for being due
+const publishInterval = time.Minute
+
+// startBackgroundJobs runs the jobs that keep
+// the server's data up to date while it serves requests.
+func (s *Server) startBackgroundJobs() {
+	go runEvery(trashPurgeInterval, s.purgeTrash)
+	go runEvery(publishInterval, s.publishDueArticles)
+}
+
+// runEvery calls f right away and then every d.
+func runEvery(d time.Duration, f func()) {
+	f()
+	t := time.NewTicker(d)
+	defer t.Stop()
+	for range t.C {
+		f()
+	}
+}
+
+// purgeTrash permanently deletes articles and categories
+// that have been in trash longer than the retention period.
+func (s *Server) purgeTrash() {
+	cutoff := time.Now().Add(-s.trashRetention)
+	n, err := s.ArticlesRepo.PurgeArticles(context.Background(), cutoff)
+	if err != nil {
+		log.Printf("could not purge articles from trash: %v", err)
+	} else if n > 0 {
+		log.Printf("purged %d articles from trash", n)
+	}
+	n, err = s.CategoriesRepo.PurgeCategories(context.Background(), cutoff)
+	if err != nil {
+		log.Printf("could not purge categories from trash: %v", err)
+	} else if n > 0 {
+		log.Printf("purged %d categories from trash", n)
+	}
+}
+
+// publishDueArticles publishes scheduled articles
+// whose publish time has come.
+//
+// Schedules are stored with the articles, so those that
+// came due while the server was down are published on start.
+func (s *Server) publishDueArticles() {
+	n, err := s.ArticlesRepo.PublishDueArticles(context.Background(), time.Now())
+	if err != nil {
+		log.Printf("could not publish scheduled articles: %v", err)
+	} else if n > 0 {
+		log.Printf("published %d scheduled articles", n)
+	}
+}
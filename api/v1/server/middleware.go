@@ -0,0 +1,27 @@
+package server
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader identifies a request, it's taken from the
+// request if the client sent it, otherwise it's generated.
+// It's always included in the response.
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDContextKey is where the request ID
+// is stored in gin.Context
+const requestIDContextKey = "requestID"
+
+// requestID sets the ID of every request.
+func requestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if len(id) == 0 || len(id) > 128 {
+			id, _ = newRandomToken(16)
+		}
+		c.Set(requestIDContextKey, id)
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/JonathanGzzBen/ingenialists/api/v1/models"
+	"github.com/JonathanGzzBen/ingenialists/api/v1/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// GetNotifications is the handler for GET requests to /notifications
+// 	@ID GetNotifications
+// 	@Summary Get notifications
+// 	@Description Get notifications of authenticated user, newest first.
+// 	@Tags notifications
+// 	@Security AccessToken
+// 	@Success 200 {array} models.Notification
+// 	@Failure 403 {object} models.APIError
+// 	@Failure 500 {object} models.APIError
+// 	@Router /notifications [get]
+func (s *Server) GetNotifications(c *gin.Context) {
+	au, err := s.authenticate(c)
+	if err != nil {
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "you must be authenticated to get notifications"})
+		return
+	}
+	ns, err := s.NotificationsRepo.GetNotificationsByUser(c.Request.Context(), au.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: "could not get notifications"})
+		return
+	}
+	c.JSON(http.StatusOK, ns)
+}
+
+// ReadNotification is the handler for PUT requests to /notifications/:id/read
+// 	@ID ReadNotification
+// 	@Summary Mark notification as read
+// 	@Tags notifications
+// 	@Security AccessToken
+// 	@Param id path int true "Notification ID"
+// 	@Success 200 {object} models.Notification
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 403 {object} models.APIError
+// 	@Failure 404 {object} models.APIError
+// 	@Failure 500 {object} models.APIError
+// 	@Router /notifications/{id}/read [put]
+func (s *Server) ReadNotification(c *gin.Context) {
+	au, err := s.authenticate(c)
+	if err != nil {
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "you must be authenticated to read notifications"})
+		return
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "invalid id: " + err.Error()})
+		return
+	}
+	n, err := s.NotificationsRepo.GetNotification(c.Request.Context(), uint(id))
+	if err == repository.ErrNotFound || (err == nil && n.UserID != au.ID) {
+		c.JSON(http.StatusNotFound, models.APIError{Code: http.StatusNotFound, Message: "notification not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+	if n.ReadAt == nil {
+		now := time.Now()
+		n.ReadAt = &now
+		n, err = s.NotificationsRepo.UpdateNotification(c.Request.Context(), n)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: "could not update notification: " + err.Error()})
+			return
+		}
+	}
+	c.JSON(http.StatusOK, n)
+}
+
+// notify sends message to user with matching id.
+//
+// Notifications are best effort, failing to send one
+// doesn't fail the action that caused it.
+func (s *Server) notify(ctx context.Context, uid uint, message string) {
+	s.NotificationsRepo.CreateNotification(ctx, &models.Notification{
+		UserID:  uid,
+		Message: message,
+	})
+}
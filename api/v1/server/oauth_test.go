@@ -26,7 +26,15 @@ func (o *OAuth2ConfigMock) AuthCodeURL(state string, opts ...oauth2.AuthCodeOpti
 
 func (o *OAuth2ConfigMock) Exchange(ctx context.Context, code string, opts ...oauth2.AuthCodeOption) (*oauth2.Token, error) {
 	return &oauth2.Token{
+		AccessToken:  "AccessToken",
+		RefreshToken: "GoogleRefreshToken",
+		Expiry:       time.Now().Add(1 * time.Hour),
+	}, nil
+}
+
+func (o *OAuth2ConfigMock) TokenSource(ctx context.Context, t *oauth2.Token) oauth2.TokenSource {
+	return oauth2.StaticTokenSource(&oauth2.Token{
 		AccessToken: "AccessToken",
 		Expiry:      time.Now().Add(1 * time.Hour),
-	}, nil
+	})
 }
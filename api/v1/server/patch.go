@@ -0,0 +1,167 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+const (
+	// MergePatchContentType is the media type of JSON merge patches,
+	// defined in RFC 7396. Patches sent as application/json
+	// are also treated as merge patches.
+	MergePatchContentType = "application/merge-patch+json"
+	// JSONPatchContentType is the media type of JSON Patch documents,
+	// defined in RFC 6902.
+	JSONPatchContentType = "application/json-patch+json"
+)
+
+// jsonPatchOperation is an operation of a JSON Patch document.
+type jsonPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// bindPatch applies the patch in the body of the request in c to dto,
+// a pointer to a DTO holding the current values of the resource,
+// and validates the result with the same rules as ShouldBindJSON.
+//
+// Members set to null by a merge patch, or removed by a JSON Patch,
+// get their zero value. JSON Patch documents may only change
+// top level members, with add, remove, replace, move, copy and test
+// operations.
+func bindPatch(c *gin.Context, dto interface{}) error {
+	b, err := json.Marshal(dto)
+	if err != nil {
+		return err
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return err
+	}
+	body, err := ioutil.ReadAll(c.Request.Body)
+	if err != nil {
+		return err
+	}
+	switch c.ContentType() {
+	case JSONPatchContentType:
+		var ops []jsonPatchOperation
+		if err := json.Unmarshal(body, &ops); err != nil {
+			return fmt.Errorf("invalid json patch: %v", err)
+		}
+		if err := applyJSONPatch(doc, ops); err != nil {
+			return err
+		}
+	case MergePatchContentType, binding.MIMEJSON:
+		var patch map[string]interface{}
+		if err := json.Unmarshal(body, &patch); err != nil {
+			return fmt.Errorf("invalid merge patch: %v", err)
+		}
+		mergePatch(doc, patch)
+	default:
+		return fmt.Errorf("unsupported content type %q, use %s or %s", c.ContentType(), MergePatchContentType, JSONPatchContentType)
+	}
+
+	if b, err = json.Marshal(doc); err != nil {
+		return err
+	}
+	// Members that were removed must not keep their current value
+	v := reflect.ValueOf(dto).Elem()
+	v.Set(reflect.Zero(v.Type()))
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.DisallowUnknownFields()
+	if err := d.Decode(dto); err != nil {
+		return err
+	}
+	return binding.Validator.ValidateStruct(dto)
+}
+
+// mergePatch applies merge patch to target as described in RFC 7396.
+func mergePatch(target map[string]interface{}, patch map[string]interface{}) {
+	for k, pv := range patch {
+		if pv == nil {
+			delete(target, k)
+			continue
+		}
+		if po, ok := pv.(map[string]interface{}); ok {
+			to, ok := target[k].(map[string]interface{})
+			if !ok {
+				to = map[string]interface{}{}
+			}
+			mergePatch(to, po)
+			target[k] = to
+			continue
+		}
+		target[k] = pv
+	}
+}
+
+// applyJSONPatch applies the operations of a JSON Patch to doc,
+// paths must point to its members.
+func applyJSONPatch(doc map[string]interface{}, ops []jsonPatchOperation) error {
+	for _, op := range ops {
+		member, err := patchMember(op.Path)
+		if err != nil {
+			return err
+		}
+		var value interface{}
+		if len(op.Value) > 0 {
+			if err := json.Unmarshal(op.Value, &value); err != nil {
+				return fmt.Errorf("invalid value for %s: %v", op.Path, err)
+			}
+		}
+		switch op.Op {
+		case "add", "replace":
+			if op.Value == nil {
+				return fmt.Errorf("%s operation on %s has no value", op.Op, op.Path)
+			}
+			if _, ok := doc[member]; !ok && op.Op == "replace" {
+				return fmt.Errorf("path %s doesn't exist", op.Path)
+			}
+			doc[member] = value
+		case "remove":
+			if _, ok := doc[member]; !ok {
+				return fmt.Errorf("path %s doesn't exist", op.Path)
+			}
+			delete(doc, member)
+		case "move", "copy":
+			from, err := patchMember(op.From)
+			if err != nil {
+				return err
+			}
+			v, ok := doc[from]
+			if !ok {
+				return fmt.Errorf("path %s doesn't exist", op.From)
+			}
+			if op.Op == "move" {
+				delete(doc, from)
+			}
+			doc[member] = v
+		case "test":
+			if !reflect.DeepEqual(doc[member], value) {
+				return fmt.Errorf("test of %s failed", op.Path)
+			}
+		default:
+			return fmt.Errorf("unsupported json patch operation %q", op.Op)
+		}
+	}
+	return nil
+}
+
+// patchMember returns the name of the top level member
+// JSON Pointer path points to.
+func patchMember(path string) (string, error) {
+	if !strings.HasPrefix(path, "/") || strings.Count(path, "/") != 1 {
+		return "", errors.New("unsupported path " + path + ", only top level members can be patched")
+	}
+	return strings.NewReplacer("~1", "/", "~0", "~").Replace(path[1:]), nil
+}
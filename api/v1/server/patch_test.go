@@ -0,0 +1,189 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/JonathanGzzBen/ingenialists/api/v1/models"
+	"github.com/JonathanGzzBen/ingenialists/api/v1/repository"
+	"github.com/JonathanGzzBen/ingenialists/api/v1/server"
+)
+
+func TestPatchArticleWithMergePatchOnlyChangesPatchedFields(t *testing.T) {
+	t.Parallel()
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	a := createArticle(t, s)
+	a.Body = "Body of the article"
+	a.Tags = "sql,databases"
+	if _, err := s.ArticlesRepo.UpdateArticle(context.Background(), a); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	res := doAs(t, ts, http.MethodPatch, fmt.Sprintf("/v1/articles/%d", a.ID), "Writer", `{"title": "Renamed article", "tags": null}`, map[string]string{"Content-Type": server.MergePatchContentType})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+	patched, err := s.ArticlesRepo.GetArticle(context.Background(), a.ID, repository.WholeArticle)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if patched.Title != "Renamed article" {
+		t.Fatalf("Expected %v, got %v", "Renamed article", patched.Title)
+	}
+	if patched.Tags != "" {
+		t.Fatalf("Expected tags to be removed, got %v", patched.Tags)
+	}
+	if patched.Body != a.Body || patched.CategoryID != a.CategoryID {
+		t.Fatalf("Expected body and category to be unchanged, got %q and %v", patched.Body, patched.CategoryID)
+	}
+}
+
+func TestPatchArticleCategory(t *testing.T) {
+	t.Parallel()
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	a := createArticle(t, s)
+	c, err := s.CategoriesRepo.CreateCategory(context.Background(), &models.Category{Name: "Networks", Slug: "networks"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	res := doAs(t, ts, http.MethodPatch, fmt.Sprintf("/v1/articles/%d", a.ID), "Writer", fmt.Sprintf(`{"categoryId": %d}`, c.ID), map[string]string{"Content-Type": server.MergePatchContentType})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+	patched, err := s.ArticlesRepo.GetArticle(context.Background(), a.ID, repository.WholeArticle)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if patched.CategoryID != c.ID {
+		t.Fatalf("Expected %v, got %v", c.ID, patched.CategoryID)
+	}
+	if patched.Title != a.Title {
+		t.Fatalf("Expected %v, got %v", a.Title, patched.Title)
+	}
+}
+
+func TestPatchArticleWithJSONPatch(t *testing.T) {
+	t.Parallel()
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	a := createArticle(t, s)
+	path := fmt.Sprintf("/v1/articles/%d", a.ID)
+
+	res := doAs(t, ts, http.MethodPatch, path, "Writer", `[
+		{"op": "test", "path": "/title", "value": "Another title"},
+		{"op": "replace", "path": "/title", "value": "Renamed article"}
+	]`, map[string]string{"Content-Type": server.JSONPatchContentType})
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected status code %v, got %v", http.StatusBadRequest, res.StatusCode)
+	}
+
+	res = doAs(t, ts, http.MethodPatch, path, "Writer", `[
+		{"op": "test", "path": "/title", "value": "First article"},
+		{"op": "replace", "path": "/title", "value": "Renamed article"},
+		{"op": "copy", "from": "/title", "path": "/body"}
+	]`, map[string]string{"Content-Type": server.JSONPatchContentType})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+	var patched models.Article
+	if err := json.NewDecoder(res.Body).Decode(&patched); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if patched.Title != "Renamed article" || patched.Body != "Renamed article" {
+		t.Fatalf("Expected title and body %q, got %q and %q", "Renamed article", patched.Title, patched.Body)
+	}
+}
+
+func TestPatchArticleWithInvalidPatchReturnBadRequest(t *testing.T) {
+	t.Parallel()
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	a := createArticle(t, s)
+	path := fmt.Sprintf("/v1/articles/%d", a.ID)
+
+	for _, p := range []struct {
+		contentType string
+		body        string
+	}{
+		{server.MergePatchContentType, `{"userId": 2}`},
+		{server.MergePatchContentType, `{"title": 5}`},
+		{server.MergePatchContentType, `[]`},
+		{server.JSONPatchContentType, `[{"op": "remove", "path": "/authors/0"}]`},
+		{server.JSONPatchContentType, `[{"op": "replace", "path": "/userId", "value": 2}]`},
+		{"text/plain", `title=Renamed`},
+	} {
+		res := doAs(t, ts, http.MethodPatch, path, "Writer", p.body, map[string]string{"Content-Type": p.contentType})
+		if res.StatusCode != http.StatusBadRequest {
+			t.Fatalf("Expected status code %v for %s, got %v", http.StatusBadRequest, p.body, res.StatusCode)
+		}
+	}
+}
+
+func TestPatchCategoryWithMergePatch(t *testing.T) {
+	t.Parallel()
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	tree := createCategoryTree(t, s)
+	databases := tree[2]
+
+	res := doAs(t, ts, http.MethodPatch, fmt.Sprintf("/v1/categories/%d", databases.ID), "Administrator", `{"parentId": null, "sortOrder": 3}`, map[string]string{"Content-Type": "application/json"})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+	patched, err := s.CategoriesRepo.GetCategory(context.Background(), databases.ID, repository.Projection{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if patched.ParentID != nil || patched.SortOrder != 3 {
+		t.Fatalf("Expected top level category sorted 3rd, got parent %v and sort order %v", patched.ParentID, patched.SortOrder)
+	}
+	if patched.Name != databases.Name || patched.Slug != databases.Slug {
+		t.Fatalf("Expected name and slug to be unchanged, got %q and %q", patched.Name, patched.Slug)
+	}
+}
+
+func TestPatchUserWithMergePatch(t *testing.T) {
+	t.Parallel()
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	u, err := s.UsersRepo.CreateUser(context.Background(), &models.User{Name: "Testing User", Gender: "Female", Role: models.RoleWriter})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	res := doAs(t, ts, http.MethodPatch, fmt.Sprintf("/v1/users/%d", u.ID), "Writer", `{"description": "Writes about databases"}`, map[string]string{"Content-Type": server.MergePatchContentType})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+	patched, err := s.UsersRepo.GetUser(context.Background(), u.ID, repository.Projection{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if patched.Description != "Writes about databases" || patched.Name != "Testing User" || patched.Gender != "Female" {
+		t.Fatalf("Expected only description to change, got %+v", patched)
+	}
+
+	res = doAs(t, ts, http.MethodPatch, fmt.Sprintf("/v1/users/%d", u.ID), "Writer", `{"name": null}`, map[string]string{"Content-Type": server.MergePatchContentType})
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected status code %v, got %v", http.StatusBadRequest, res.StatusCode)
+	}
+}
@@ -0,0 +1,138 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/JonathanGzzBen/ingenialists/api/v1/models"
+	"github.com/JonathanGzzBen/ingenialists/api/v1/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// Associations that can be expanded in each model
+var (
+	articleAssociations  = []string{"user", "category", "authors"}
+	categoryAssociations = []string{"parent"}
+	userAssociations     = []string{}
+)
+
+// projection reads the fields and expand query parameters of c,
+// both lists of JSON names separated by commas.
+func projection(c *gin.Context) repository.Projection {
+	return repository.Projection{
+		Fields: splitList(c.Query("fields")),
+		Expand: splitList(c.Query("expand")),
+	}
+}
+
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// checkProjection returns an error if p has a field that model doesn't have
+// or expands something that isn't one of associations.
+func checkProjection(p repository.Projection, model interface{}, associations []string) error {
+	fields := map[string]bool{}
+	for _, name := range jsonNames(reflect.TypeOf(model)) {
+		fields[name] = true
+	}
+	expandable := map[string]bool{}
+	for _, a := range associations {
+		expandable[a] = true
+	}
+	for _, f := range p.Fields {
+		if !fields[f] || expandable[f] {
+			return fmt.Errorf("unknown field %q", f)
+		}
+	}
+	for _, e := range p.Expand {
+		if !expandable[e] {
+			return fmt.Errorf("%q can't be expanded", e)
+		}
+	}
+	return nil
+}
+
+// jsonNames returns the names fields of struct type t have in JSON.
+func jsonNames(t reflect.Type) []string {
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous {
+			names = append(names, jsonNames(f.Type)...)
+			continue
+		}
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "-" || f.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// renderProjection writes v, a model or a slice of models, as JSON
+// without the associations in associations that p doesn't expand
+// and, if p has fields, without the fields that are not listed.
+// The id of models is always written.
+func renderProjection(c *gin.Context, code int, v interface{}, p repository.Projection, associations []string) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	var data interface{}
+	if err := d.Decode(&data); err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	keep := map[string]bool{"id": true}
+	for _, f := range p.Fields {
+		keep[f] = true
+	}
+	for _, e := range p.Expand {
+		keep[e] = true
+	}
+	project := func(m map[string]interface{}) {
+		for _, a := range associations {
+			if !keep[a] {
+				delete(m, a)
+			}
+		}
+		if len(p.Fields) == 0 {
+			return
+		}
+		for k := range m {
+			if !keep[k] {
+				delete(m, k)
+			}
+		}
+	}
+	switch data := data.(type) {
+	case map[string]interface{}:
+		project(data)
+	case []interface{}:
+		for _, item := range data {
+			if m, ok := item.(map[string]interface{}); ok {
+				project(m)
+			}
+		}
+	}
+	c.JSON(code, data)
+}
@@ -0,0 +1,143 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/JonathanGzzBen/ingenialists/api/v1/models"
+)
+
+// getJSON gets path from ts and decodes its response body into v,
+// returning the response status code.
+func getJSON(t *testing.T, ts *httptest.Server, path string, v interface{}) int {
+	res, err := http.Get(ts.URL + path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode == http.StatusOK {
+		if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	return res.StatusCode
+}
+
+func keys(m map[string]interface{}) []string {
+	var ks []string
+	for k := range m {
+		ks = append(ks, k)
+	}
+	sort.Strings(ks)
+	return ks
+}
+
+func TestGetAllArticlesWithFieldsOnlyReturnsThoseFields(t *testing.T) {
+	t.Parallel()
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	createArticle(t, s)
+
+	var articles []map[string]interface{}
+	code := getJSON(t, ts, "/v1/articles/?fields=title,slug", &articles)
+	if code != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, code)
+	}
+	if len(articles) != 1 {
+		t.Fatalf("Expected %v, got %v", 1, len(articles))
+	}
+	expected := []string{"id", "slug", "title"}
+	if !reflect.DeepEqual(keys(articles[0]), expected) {
+		t.Fatalf("Expected %v, got %v", expected, keys(articles[0]))
+	}
+}
+
+func TestGetAllArticlesOnlyExpandsRequestedAssociations(t *testing.T) {
+	t.Parallel()
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	a := createArticle(t, s)
+
+	var articles []models.Article
+	getJSON(t, ts, "/v1/articles/", &articles)
+	if articles[0].User != nil || articles[0].Category != nil {
+		t.Fatalf("Expected no associations, got user %v and category %v", articles[0].User, articles[0].Category)
+	}
+
+	articles = nil
+	getJSON(t, ts, "/v1/articles/?expand=category", &articles)
+	if articles[0].Category == nil || articles[0].Category.ID != a.CategoryID {
+		t.Fatalf("Expected category %v, got %v", a.CategoryID, articles[0].Category)
+	}
+	if articles[0].User != nil {
+		t.Fatalf("Expected user not to be expanded, got %v", articles[0].User)
+	}
+}
+
+func TestGetArticleOnlyExpandsRequestedAssociations(t *testing.T) {
+	t.Parallel()
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	a := createArticle(t, s)
+
+	var article map[string]interface{}
+	getJSON(t, ts, fmt.Sprintf("/v1/articles/%d?fields=title&expand=authors", a.ID), &article)
+	expected := []string{"authors", "id", "title"}
+	if !reflect.DeepEqual(keys(article), expected) {
+		t.Fatalf("Expected %v, got %v", expected, keys(article))
+	}
+}
+
+func TestGetWithUnknownFieldReturnBadRequest(t *testing.T) {
+	t.Parallel()
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	for _, path := range []string{
+		"/v1/articles/?fields=password",
+		"/v1/articles/?expand=comments",
+		"/v1/users/?fields=email",
+		"/v1/categories/?expand=children",
+	} {
+		var v interface{}
+		if code := getJSON(t, ts, path, &v); code != http.StatusBadRequest {
+			t.Fatalf("Expected status code %v for %v, got %v", http.StatusBadRequest, path, code)
+		}
+	}
+}
+
+func TestGetAllUsersWithFields(t *testing.T) {
+	t.Parallel()
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	if _, err := s.UsersRepo.CreateUser(context.Background(), &models.User{Name: "Testing User", Description: "Writes about databases"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var users []map[string]interface{}
+	getJSON(t, ts, "/v1/users/?fields=name", &users)
+	if len(users) != 1 {
+		t.Fatalf("Expected %v, got %v", 1, len(users))
+	}
+	expected := []string{"id", "name"}
+	if !reflect.DeepEqual(keys(users[0]), expected) {
+		t.Fatalf("Expected %v, got %v", expected, keys(users[0]))
+	}
+	if users[0]["name"] != "Testing User" {
+		t.Fatalf("Expected %v, got %v", "Testing User", users[0]["name"])
+	}
+}
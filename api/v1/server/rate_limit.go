@@ -0,0 +1,209 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/JonathanGzzBen/ingenialists/api/v1/models"
+	"github.com/gin-gonic/gin"
+)
+
+// Headers that tell clients how many more requests
+// they can make before being rate limited.
+const (
+	RateLimitLimitHeader     = "RateLimit-Limit"
+	RateLimitRemainingHeader = "RateLimit-Remaining"
+	RateLimitResetHeader     = "RateLimit-Reset"
+)
+
+// rateLimitSweepInterval is how often buckets of
+// clients that stopped making requests are checked for removal
+const rateLimitSweepInterval = time.Minute
+
+// RateLimitPolicy lets each client make Burst requests at once,
+// and then Rate requests per second. The zero value doesn't limit requests.
+type RateLimitPolicy struct {
+	Rate  float64
+	Burst int
+}
+
+// RateLimitConfig configures the rate limits of requests.
+//
+// Every request counts against the limit of its IP address.
+// Authenticated requests also count against the limit of the
+// user or API key they authenticated as, so a client can't get
+// past its limit by spreading its requests over many addresses.
+type RateLimitConfig struct {
+	// Default limits requests to routes without a policy of their own
+	Default RateLimitPolicy
+	// Writes limits POST, PUT, PATCH and DELETE requests
+	// to routes without a policy of their own
+	Writes RateLimitPolicy
+	// Routes are the policies of routes by method and route,
+	// like "POST /v1/articles/". Each route has its own limit.
+	Routes map[string]RateLimitPolicy
+}
+
+// policy returns the policy of requests in c and the name
+// of the limit it's counted against.
+func (cfg *RateLimitConfig) policy(c *gin.Context) (string, RateLimitPolicy) {
+	route := c.Request.Method + " " + c.FullPath()
+	if p, ok := cfg.Routes[route]; ok {
+		return route, p
+	}
+	switch c.Request.Method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return "writes", cfg.Writes
+	}
+	return "default", cfg.Default
+}
+
+// tokenBucket holds the requests a client can make right away,
+// refilled continuously up to the burst of its policy.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+	// refill is how long the bucket takes to be full from empty
+	refill time.Duration
+}
+
+// rateLimiter keeps the token buckets of clients
+// by limit and client.
+type rateLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{
+		buckets:   map[string]*tokenBucket{},
+		lastSweep: time.Now(),
+	}
+}
+
+// bucketState is how a bucket was left by taking a token from it.
+type bucketState struct {
+	// ok is whether there was a token to take
+	ok bool
+	// remaining is how many tokens are left
+	remaining int
+	// reset is how long until the bucket is full again
+	reset time.Duration
+	// wait is how long until there is a token,
+	// when there wasn't one
+	wait time.Duration
+}
+
+// take takes a token from the bucket under key.
+func (l *rateLimiter) take(key string, p RateLimitPolicy, now time.Time) bucketState {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if now.Sub(l.lastSweep) > rateLimitSweepInterval {
+		l.sweep(now)
+	}
+	b, found := l.buckets[key]
+	if !found {
+		b = &tokenBucket{
+			tokens: float64(p.Burst),
+			last:   now,
+			refill: secondsDuration(float64(p.Burst) / p.Rate),
+		}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(p.Burst), b.tokens+now.Sub(b.last).Seconds()*p.Rate)
+	b.last = now
+	var st bucketState
+	if b.tokens >= 1 {
+		b.tokens--
+		st.ok = true
+	} else {
+		st.wait = secondsDuration((1 - b.tokens) / p.Rate)
+	}
+	st.remaining = int(b.tokens)
+	st.reset = secondsDuration((float64(p.Burst) - b.tokens) / p.Rate)
+	return st
+}
+
+// sweep removes buckets that haven't been used for as long
+// as they take to refill, they would be full by now.
+func (l *rateLimiter) sweep(now time.Time) {
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= b.refill {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+func secondsDuration(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// rateLimitClient returns what tells apart the authenticated client
+// that made the request in c, the API key or the user it authenticated
+// as, or "" if it didn't send credentials or they aren't valid.
+//
+// Only verified credentials tell clients apart, otherwise clients
+// could get a new limit by sending a different token each time.
+func (s *Server) rateLimitClient(c *gin.Context) string {
+	if c.GetHeader(AccessTokenName) == "" {
+		return ""
+	}
+	u, err := s.authenticate(c)
+	if err != nil {
+		return ""
+	}
+	if v, ok := c.Get(apiKeyContextKey); ok {
+		return fmt.Sprintf("apikey:%d", v.(*models.APIKey).ID)
+	}
+	return fmt.Sprintf("user:%d", u.ID)
+}
+
+// rateLimit limits the requests clients make as configured in cfg.
+//
+// Requests are counted against the limit of their IP address first,
+// so that requests over it are rejected before their credentials
+// are verified, and then against the limit of their client.
+//
+// Responses tell clients their limit, the requests they have left
+// and in how many seconds they'll be able to make Burst requests again.
+// Requests over the limit get 429 Too Many Requests and are
+// told when to retry in the Retry-After header.
+func (s *Server) rateLimit(cfg *RateLimitConfig) gin.HandlerFunc {
+	l := newRateLimiter()
+	return func(c *gin.Context) {
+		name, p := cfg.policy(c)
+		if p.Burst <= 0 || p.Rate <= 0 {
+			c.Next()
+			return
+		}
+		now := time.Now()
+		st := l.take(name+" ip:"+c.ClientIP(), p, now)
+		if st.ok {
+			if client := s.rateLimitClient(c); client != "" {
+				// The client's own limit is reported if it's the lower one
+				if cst := l.take(name+" "+client, p, now); !cst.ok || cst.remaining < st.remaining {
+					st = cst
+				}
+			}
+		}
+		c.Header(RateLimitLimitHeader, strconv.Itoa(p.Burst))
+		c.Header(RateLimitRemainingHeader, strconv.Itoa(st.remaining))
+		c.Header(RateLimitResetHeader, strconv.Itoa(int(math.Ceil(st.reset.Seconds()))))
+		if !st.ok {
+			retryAfter := int(math.Ceil(st.wait.Seconds()))
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, models.APIError{
+				Code:    http.StatusTooManyRequests,
+				Message: fmt.Sprintf("rate limit exceeded, retry in %d seconds", retryAfter),
+			})
+			return
+		}
+		c.Next()
+	}
+}
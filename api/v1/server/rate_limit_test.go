@@ -0,0 +1,127 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/JonathanGzzBen/ingenialists/api/v1/models"
+	"github.com/JonathanGzzBen/ingenialists/api/v1/server"
+)
+
+// newRateLimitedServer returns a test server that lets
+// each client make two requests for categories.
+func newRateLimitedServer() *server.Server {
+	sc := NewTestConfig()
+	sc.RateLimit = &server.RateLimitConfig{
+		Routes: map[string]server.RateLimitPolicy{
+			"GET /v1/categories/": {Rate: 0.001, Burst: 2},
+		},
+	}
+	return server.NewServer(sc)
+}
+
+func TestRateLimitReturnTooManyRequests(t *testing.T) {
+	t.Parallel()
+	s := newRateLimitedServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	for i := 1; i >= 0; i-- {
+		res := doAs(t, ts, http.MethodGet, "/v1/categories/", "", nil, nil)
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+		}
+		if l := res.Header.Get(server.RateLimitLimitHeader); l != "2" {
+			t.Fatalf("Expected %v, got %v", "2", l)
+		}
+		if r := res.Header.Get(server.RateLimitRemainingHeader); r != strconv.Itoa(i) {
+			t.Fatalf("Expected %v, got %v", i, r)
+		}
+	}
+
+	res := doAs(t, ts, http.MethodGet, "/v1/categories/", "", nil, nil)
+	if res.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("Expected status code %v, got %v", http.StatusTooManyRequests, res.StatusCode)
+	}
+	if res.Header.Get("Retry-After") == "" || res.Header.Get(server.RateLimitResetHeader) == "" {
+		t.Fatalf("Expected Retry-After and %v headers to be set", server.RateLimitResetHeader)
+	}
+	var apiErr models.APIError
+	err := json.NewDecoder(res.Body).Decode(&apiErr)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if apiErr.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected %v, got %v", http.StatusTooManyRequests, apiErr.Code)
+	}
+
+	// Routes without a policy aren't limited
+	res = doAs(t, ts, http.MethodGet, "/v1/categories/tree", "", nil, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+	if res.Header.Get(server.RateLimitLimitHeader) != "" {
+		t.Fatalf("Expected no %v header", server.RateLimitLimitHeader)
+	}
+}
+
+func TestRateLimitIsKeptByAuthenticatedClient(t *testing.T) {
+	t.Parallel()
+	sc := NewTestConfig()
+	sc.TrustedProxies = []string{"127.0.0.1"}
+	sc.RateLimit = &server.RateLimitConfig{
+		Routes: map[string]server.RateLimitPolicy{
+			"GET /v1/categories/": {Rate: 0.001, Burst: 2},
+		},
+	}
+	s := server.NewServer(sc)
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	u := models.User{Name: "Script Owner", Role: models.RoleReader}
+	if _, err := s.UsersRepo.CreateUser(context.Background(), &u); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	k := createAPIKey(t, ts)
+	// Clients that authenticate are limited wherever they make requests from
+	for _, token := range []string{"Reader", k.Key} {
+		for i, addr := range []string{"203.0.113.1", "203.0.113.2", "203.0.113.3"} {
+			res := doAs(t, ts, http.MethodGet, "/v1/categories/", token, nil, map[string]string{"X-Forwarded-For": addr})
+			expected := http.StatusOK
+			if i == 2 {
+				expected = http.StatusTooManyRequests
+			}
+			if res.StatusCode != expected {
+				t.Fatalf("Expected status code %v for request %v, got %v", expected, i, res.StatusCode)
+			}
+		}
+	}
+	// while other addresses keep their own limit
+	res := doAs(t, ts, http.MethodGet, "/v1/categories/", "", nil, map[string]string{"X-Forwarded-For": "203.0.113.4"})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+}
+
+func TestRateLimitUnverifiedTokensShareAddressLimit(t *testing.T) {
+	t.Parallel()
+	s := newRateLimitedServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	// A different token each time doesn't get a new limit
+	for i, token := range []string{"invalid-1", server.APIKeyPrefix + "invalid-2"} {
+		res := doAs(t, ts, http.MethodGet, "/v1/categories/", token, nil, nil)
+		if res.StatusCode == http.StatusTooManyRequests {
+			t.Fatalf("Expected request %v not to be limited", i)
+		}
+	}
+	res := doAs(t, ts, http.MethodGet, "/v1/categories/", server.APIKeyPrefix+"invalid-3", nil, nil)
+	if res.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("Expected status code %v, got %v", http.StatusTooManyRequests, res.StatusCode)
+	}
+}
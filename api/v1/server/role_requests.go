@@ -0,0 +1,295 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/JonathanGzzBen/ingenialists/api/v1/models"
+	"github.com/JonathanGzzBen/ingenialists/api/v1/repository"
+	"github.com/gin-gonic/gin"
+)
+
+type CreateRoleRequestDTO struct {
+	Role       models.Role `json:"role" binding:"required" example:"Writer"`
+	Motivation string      `json:"motivation" binding:"required"`
+}
+
+type ReviewRoleRequestDTO struct {
+	Note string `json:"note"`
+}
+
+// GetAllRoleRequests is the handler for GET requests to /role-requests
+// 	@ID GetAllRoleRequests
+// 	@Summary Get role requests
+// 	@Description Get all role requests if authenticated user can assign roles,
+// 	@Description otherwise get role requests of authenticated user.
+// 	@Tags role-requests
+// 	@Security AccessToken
+// 	@Param status query string false "Filter by status" Enums(Pending, Approved, Rejected)
+// 	@Success 200 {array} models.RoleRequest
+// 	@Failure 403 {object} models.APIError
+// 	@Failure 500 {object} models.APIError
+// 	@Router /role-requests [get]
+func (s *Server) GetAllRoleRequests(c *gin.Context) {
+	au, err := s.authenticate(c)
+	if err != nil {
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "you must be authenticated to get role requests"})
+		return
+	}
+	var rrs []models.RoleRequest
+	if s.can(c.Request.Context(), au, models.PermissionUserRoleAssign) {
+		rrs, err = s.RoleRequestsRepo.GetAllRoleRequests(c.Request.Context(), models.RoleRequestStatus(c.Query("status")))
+	} else {
+		rrs, err = s.RoleRequestsRepo.GetRoleRequestsByUser(c.Request.Context(), au.ID)
+		if status := models.RoleRequestStatus(c.Query("status")); err == nil && status != "" {
+			filtered := rrs[:0]
+			for _, rr := range rrs {
+				if rr.Status == status {
+					filtered = append(filtered, rr)
+				}
+			}
+			rrs = filtered
+		}
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: "could not get role requests"})
+		return
+	}
+	c.JSON(http.StatusOK, rrs)
+}
+
+// GetRoleRequest is the handler for GET requests to /role-requests/:id
+// 	@ID GetRoleRequest
+// 	@Summary Get role request
+// 	@Description Get role request with matching ID.
+// 	@Tags role-requests
+// 	@Security AccessToken
+// 	@Param id path int true "Role request ID"
+// 	@Success 200 {object} models.RoleRequest
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 403 {object} models.APIError
+// 	@Failure 404 {object} models.APIError
+// 	@Failure 500 {object} models.APIError
+// 	@Router /role-requests/{id} [get]
+func (s *Server) GetRoleRequest(c *gin.Context) {
+	au, err := s.authenticate(c)
+	if err != nil {
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "you must be authenticated to get a role request"})
+		return
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "invalid id: " + err.Error()})
+		return
+	}
+	rr, err := s.RoleRequestsRepo.GetRoleRequest(c.Request.Context(), uint(id))
+	// Requests of other users are reported as not found
+	// to users that can't review them
+	if err == repository.ErrNotFound || (err == nil && rr.UserID != au.ID && !s.can(c.Request.Context(), au, models.PermissionUserRoleAssign)) {
+		c.JSON(http.StatusNotFound, models.APIError{Code: http.StatusNotFound, Message: "role request not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, rr)
+}
+
+// CreateRoleRequest is the handler for POST requests to /role-requests
+// 	@ID CreateRoleRequest
+// 	@Summary Request role
+// 	@Description Request authenticated user's role to be changed.
+// 	@Tags role-requests
+// 	@Security AccessToken
+// 	@Param roleRequest body CreateRoleRequestDTO true "Role request"
+// 	@Success 200 {object} models.RoleRequest
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 403 {object} models.APIError
+// 	@Failure 500 {object} models.APIError
+// 	@Router /role-requests [post]
+func (s *Server) CreateRoleRequest(c *gin.Context) {
+	au, err := s.authenticate(c)
+	if err != nil {
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "you must be authenticated to request a role"})
+		return
+	}
+	if !hasScope(c, models.ScopeUsersWrite) {
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "api key lacks scope " + string(models.ScopeUsersWrite)})
+		return
+	}
+	var crr CreateRoleRequestDTO
+	if err := c.ShouldBindJSON(&crr); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "invalid role request: " + err.Error()})
+		return
+	}
+	if crr.Role == au.Role {
+		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "you already have role " + string(crr.Role)})
+		return
+	}
+	if _, err := s.RolesRepo.GetRole(c.Request.Context(), crr.Role); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "unknown role " + string(crr.Role)})
+		return
+	}
+
+	rrs, err := s.RoleRequestsRepo.GetRoleRequestsByUser(c.Request.Context(), au.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+	for _, rr := range rrs {
+		if rr.Status == models.RoleRequestPending {
+			c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "you already have a pending role request"})
+			return
+		}
+	}
+
+	rr, err := s.RoleRequestsRepo.CreateRoleRequest(c.Request.Context(), &models.RoleRequest{
+		UserID:     au.ID,
+		Role:       crr.Role,
+		Motivation: crr.Motivation,
+		Status:     models.RoleRequestPending,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: "could not create role request: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, rr)
+}
+
+// ApproveRoleRequest is the handler for POST requests to /role-requests/:id/approve
+// 	@ID ApproveRoleRequest
+// 	@Summary Approve role request
+// 	@Description Give requested role to user and notify them.
+// 	@Tags role-requests
+// 	@Security AccessToken
+// 	@Param id path int true "Role request ID"
+// 	@Param review body ReviewRoleRequestDTO false "Review"
+// 	@Success 200 {object} models.RoleRequest
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 403 {object} models.APIError
+// 	@Failure 404 {object} models.APIError
+// 	@Failure 500 {object} models.APIError
+// 	@Router /role-requests/{id}/approve [post]
+func (s *Server) ApproveRoleRequest(c *gin.Context) {
+	s.reviewRoleRequest(c, models.RoleRequestApproved)
+}
+
+// RejectRoleRequest is the handler for POST requests to /role-requests/:id/reject
+// 	@ID RejectRoleRequest
+// 	@Summary Reject role request
+// 	@Description Reject role request and notify user.
+// 	@Tags role-requests
+// 	@Security AccessToken
+// 	@Param id path int true "Role request ID"
+// 	@Param review body ReviewRoleRequestDTO false "Review"
+// 	@Success 200 {object} models.RoleRequest
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 403 {object} models.APIError
+// 	@Failure 404 {object} models.APIError
+// 	@Failure 500 {object} models.APIError
+// 	@Router /role-requests/{id}/reject [post]
+func (s *Server) RejectRoleRequest(c *gin.Context) {
+	s.reviewRoleRequest(c, models.RoleRequestRejected)
+}
+
+func (s *Server) reviewRoleRequest(c *gin.Context, status models.RoleRequestStatus) {
+	au, err := s.authenticate(c)
+	if err != nil {
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "you must be authenticated to review a role request"})
+		return
+	}
+	if !hasScope(c, models.ScopeUsersWrite) {
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "api key lacks scope " + string(models.ScopeUsersWrite)})
+		return
+	}
+	if !s.can(c.Request.Context(), au, models.PermissionUserRoleAssign) {
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "you don't have permission to review role requests"})
+		return
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "invalid id: " + err.Error()})
+		return
+	}
+	var review ReviewRoleRequestDTO
+	// Review body is optional
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&review); err != nil {
+			c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "invalid review: " + err.Error()})
+			return
+		}
+	}
+
+	rr, err := s.RoleRequestsRepo.GetRoleRequest(c.Request.Context(), uint(id))
+	if err == repository.ErrNotFound {
+		c.JSON(http.StatusNotFound, models.APIError{Code: http.StatusNotFound, Message: "role request not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+	if rr.Status != models.RoleRequestPending {
+		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "role request was already reviewed"})
+		return
+	}
+	if rr.UserID == au.ID {
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "you can't review your own role request"})
+		return
+	}
+
+	before := *rr
+	rr.Status = status
+	rr.ReviewerID = &au.ID
+	rr.ReviewNote = review.Note
+	// The status is changed first, and only if it's still pending,
+	// so that concurrent reviews can't both change the role
+	var u, userBefore *models.User
+	err = s.inUnitOfWork(c.Request.Context(), func(r repository.Repositories) error {
+		rr, err = r.RoleRequests.ReviewRoleRequest(c.Request.Context(), rr)
+		if err != nil {
+			return err
+		}
+		if status != models.RoleRequestApproved {
+			return nil
+		}
+		u, err = r.Users.GetUser(c.Request.Context(), rr.UserID, repository.Projection{})
+		if err != nil {
+			return err
+		}
+		b := *u
+		userBefore = &b
+		u.Role = rr.Role
+		u, err = r.Users.UpdateUser(c.Request.Context(), u)
+		return err
+	})
+	if err == repository.ErrAlreadyReviewed {
+		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "role request was already reviewed"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: "could not review role request: " + err.Error()})
+		return
+	}
+	if u != nil {
+		s.audit(c, au, models.AuditUserRoleUpdate, "user", u.ID, *userBefore, u)
+	}
+	action := models.AuditRoleRequestReject
+	if status == models.RoleRequestApproved {
+		action = models.AuditRoleRequestApprove
+	}
+	s.audit(c, au, action, "role_request", rr.ID, before, rr)
+
+	message := fmt.Sprintf("Your request to become %s was %s.", rr.Role, map[models.RoleRequestStatus]string{
+		models.RoleRequestApproved: "approved",
+		models.RoleRequestRejected: "rejected",
+	}[status])
+	if review.Note != "" {
+		message += " " + review.Note
+	}
+	s.notify(c.Request.Context(), rr.UserID, message)
+
+	c.JSON(http.StatusOK, rr)
+}
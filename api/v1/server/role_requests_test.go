@@ -0,0 +1,266 @@
+package server_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/JonathanGzzBen/ingenialists/api/v1/models"
+	"github.com/JonathanGzzBen/ingenialists/api/v1/repository"
+	"github.com/JonathanGzzBen/ingenialists/api/v1/repository/mocks"
+	"github.com/JonathanGzzBen/ingenialists/api/v1/server"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateRoleRequestAsReaderReturnOk(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	body, err := json.Marshal(server.CreateRoleRequestDTO{Role: models.RoleWriter, Motivation: "I review databases"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/v1/role-requests", ts.URL), bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req.Header.Add(server.AccessTokenName, "Reader")
+	res, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+	var rr models.RoleRequest
+	err = json.NewDecoder(res.Body).Decode(&rr)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if rr.Status != models.RoleRequestPending {
+		t.Fatalf("Expected %v, got %v", models.RoleRequestPending, rr.Status)
+	}
+
+	// A second request while the first one is pending is refused
+	req, err = http.NewRequest(http.MethodPost, fmt.Sprintf("%s/v1/role-requests", ts.URL), bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req.Header.Add(server.AccessTokenName, "Reader")
+	res, err = ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected status code %v, got %v", http.StatusBadRequest, res.StatusCode)
+	}
+}
+
+func TestCreateRoleRequestWithUnknownRoleReturnBadRequest(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	body, err := json.Marshal(server.CreateRoleRequestDTO{Role: "Overlord", Motivation: "Because"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/v1/role-requests", ts.URL), bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req.Header.Add(server.AccessTokenName, "Reader")
+	res, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected status code %v, got %v", http.StatusBadRequest, res.StatusCode)
+	}
+}
+
+// pendingRoleRequest registers a second user, since in testing mode
+// the authenticated user always has ID = 1, and a pending request of it.
+func pendingRoleRequest(t *testing.T, s *server.Server) *models.RoleRequest {
+	s.UsersRepo.CreateUser(context.Background(), &models.User{ID: 1, Name: "Reviewer"})
+	u := &models.User{ID: 2, Name: "Aspiring Writer", Role: models.RoleReader}
+	s.UsersRepo.CreateUser(context.Background(), u)
+	rr, err := s.RoleRequestsRepo.CreateRoleRequest(context.Background(), &models.RoleRequest{
+		UserID:     u.ID,
+		Role:       models.RoleWriter,
+		Motivation: "I review databases",
+		Status:     models.RoleRequestPending,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return rr
+}
+
+func TestApproveRoleRequestAsAdministratorReturnOk(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	rr := pendingRoleRequest(t, s)
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/v1/role-requests/%d/approve", ts.URL, rr.ID), nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req.Header.Add(server.AccessTokenName, "Administrator")
+	res, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+
+	u, err := s.UsersRepo.GetUser(context.Background(), rr.UserID, repository.Projection{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if u.Role != models.RoleWriter {
+		t.Fatalf("Expected %v, got %v", models.RoleWriter, u.Role)
+	}
+	ns, err := s.NotificationsRepo.GetNotificationsByUser(context.Background(), rr.UserID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(ns) != 1 {
+		t.Fatalf("Expected %v, got %v", 1, len(ns))
+	}
+}
+
+func TestRejectRoleRequestAsAdministratorReturnOk(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	rr := pendingRoleRequest(t, s)
+
+	body, err := json.Marshal(server.ReviewRoleRequestDTO{Note: "Publish a few guest posts first."})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/v1/role-requests/%d/reject", ts.URL, rr.ID), bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req.Header.Add(server.AccessTokenName, "Administrator")
+	res, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+	var resRR models.RoleRequest
+	err = json.NewDecoder(res.Body).Decode(&resRR)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resRR.Status != models.RoleRequestRejected {
+		t.Fatalf("Expected %v, got %v", models.RoleRequestRejected, resRR.Status)
+	}
+
+	u, err := s.UsersRepo.GetUser(context.Background(), rr.UserID, repository.Projection{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if u.Role != models.RoleReader {
+		t.Fatalf("Expected %v, got %v", models.RoleReader, u.Role)
+	}
+}
+
+func TestApproveRoleRequestAsWriterReturnForbidden(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	rr := pendingRoleRequest(t, s)
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/v1/role-requests/%d/approve", ts.URL, rr.ID), nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req.Header.Add(server.AccessTokenName, "Writer")
+	res, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode != http.StatusForbidden {
+		t.Fatalf("Expected status code %v, got %v", http.StatusForbidden, res.StatusCode)
+	}
+}
+
+func TestUpdateUserChangeOwnRoleReturnBadRequest(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	uToUpdate := models.User{ID: 1, Name: "First User", Role: models.RoleReader}
+	uUpdated := uToUpdate
+	uUpdated.Role = models.RoleAdministrator
+
+	mockUsersRepo := &mocks.UsersRepository{}
+	mockUsersRepo.On("GetUser", mock.Anything, uToUpdate.ID, mock.Anything).Return(&uToUpdate, nil)
+	s.UsersRepo = mockUsersRepo
+
+	muJSONBytes, err := json.Marshal(uUpdated)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("%s/v1/users/%d", ts.URL, uToUpdate.ID), bytes.NewBuffer(muJSONBytes))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req.Header.Add(server.AccessTokenName, "Reader")
+	res, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected status code %v, got %v", http.StatusBadRequest, res.StatusCode)
+	}
+}
+
+func TestApproveRoleRequestWhenUserUpdateFailsKeepsItPending(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	rr := pendingRoleRequest(t, s)
+
+	mockUsersRepo := &mocks.UsersRepository{}
+	mockUsersRepo.On("GetUser", mock.Anything, rr.UserID, mock.Anything).Return(&models.User{ID: rr.UserID, Role: models.RoleReader}, nil)
+	mockUsersRepo.On("UpdateUser", mock.Anything, mock.Anything).Return(nil, repository.ErrCouldNotUpdate)
+	s.UsersRepo = mockUsersRepo
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/v1/role-requests/%d/approve", ts.URL, rr.ID), nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req.Header.Add(server.AccessTokenName, "Administrator")
+	res, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("Expected status code %v, got %v", http.StatusInternalServerError, res.StatusCode)
+	}
+
+	rr, err = s.RoleRequestsRepo.GetRoleRequest(context.Background(), rr.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if rr.Status != models.RoleRequestPending {
+		t.Fatalf("Expected %v, got %v", models.RoleRequestPending, rr.Status)
+	}
+}
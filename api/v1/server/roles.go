@@ -0,0 +1,140 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/JonathanGzzBen/ingenialists/api/v1/models"
+	"github.com/JonathanGzzBen/ingenialists/api/v1/repository"
+	"github.com/gin-gonic/gin"
+)
+
+type SaveRoleDTO struct {
+	Description string              `json:"description"`
+	Permissions []models.Permission `json:"permissions" example:"article.publish"`
+}
+
+// GetAllRoles is the handler for GET requests to /roles
+// 	@ID GetAllRoles
+// 	@Summary Get all roles
+// 	@Description Get all roles and the permissions they grant.
+// 	@Tags roles
+// 	@Success 200 {array} models.RoleDefinition
+// 	@Failure 500 {object} models.APIError
+// 	@Router /roles [get]
+func (s *Server) GetAllRoles(c *gin.Context) {
+	roles, err := s.RolesRepo.GetAllRoles(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: "could not get roles"})
+		return
+	}
+	c.JSON(http.StatusOK, roles)
+}
+
+// GetRole is the handler for GET requests to /roles/:name
+// 	@ID GetRole
+// 	@Summary Get role
+// 	@Description Get role with matching name.
+// 	@Tags roles
+// 	@Param name path string true "Role name"
+// 	@Success 200 {object} models.RoleDefinition
+// 	@Failure 404 {object} models.APIError
+// 	@Failure 500 {object} models.APIError
+// 	@Router /roles/{name} [get]
+func (s *Server) GetRole(c *gin.Context) {
+	role, err := s.RolesRepo.GetRole(c.Request.Context(), models.Role(c.Param("name")))
+	if err == repository.ErrNotFound {
+		c.JSON(http.StatusNotFound, models.APIError{Code: http.StatusNotFound, Message: "role not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, role)
+}
+
+// SaveRole is the handler for PUT requests to /roles/:name
+// 	@ID SaveRole
+// 	@Summary Create or update role
+// 	@Description Create role with provided name or replace its permissions if it exists.
+// 	@Description Administrator role can't be modified.
+// 	@Tags roles
+// 	@Security AccessToken
+// 	@Param name path string true "Role name"
+// 	@Param role body SaveRoleDTO true "Role"
+// 	@Success 200 {object} models.RoleDefinition
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 403 {object} models.APIError
+// 	@Failure 500 {object} models.APIError
+// 	@Router /roles/{name} [put]
+func (s *Server) SaveRole(c *gin.Context) {
+	au, err := s.authenticate(c)
+	if err != nil {
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "you must be authenticated to manage roles"})
+		return
+	}
+	if !hasScope(c, models.ScopeUsersWrite) {
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "api key lacks scope " + string(models.ScopeUsersWrite)})
+		return
+	}
+	if !s.can(c.Request.Context(), au, models.PermissionRoleManage) {
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "you don't have permission to manage roles"})
+		return
+	}
+	name := models.Role(c.Param("name"))
+	if name == models.RoleAdministrator {
+		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "Administrator role can't be modified"})
+		return
+	}
+	var sr SaveRoleDTO
+	if err := c.ShouldBindJSON(&sr); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "invalid role: " + err.Error()})
+		return
+	}
+	permissions := make([]string, 0, len(sr.Permissions))
+	for _, p := range sr.Permissions {
+		if !validPermission(p) {
+			c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "unknown permission " + string(p)})
+			return
+		}
+		permissions = append(permissions, string(p))
+	}
+	before, err := s.RolesRepo.GetRole(c.Request.Context(), name)
+	if err != nil && err != repository.ErrNotFound {
+		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+	role, err := s.RolesRepo.SaveRole(c.Request.Context(), &models.RoleDefinition{
+		Name:        name,
+		Description: sr.Description,
+		Permissions: strings.Join(permissions, ","),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: "could not save role: " + err.Error()})
+		return
+	}
+	// Roles are identified by name, which is in the snapshots,
+	// so there is no target ID
+	s.audit(c, au, models.AuditRoleSave, "role", 0, before, role)
+	c.JSON(http.StatusOK, role)
+}
+
+// can reports whether the role of u grants p.
+func (s *Server) can(ctx context.Context, u *models.User, p models.Permission) bool {
+	role, err := s.RolesRepo.GetRole(ctx, u.Role)
+	if err != nil {
+		return false
+	}
+	return role.HasPermission(p)
+}
+
+func validPermission(permission models.Permission) bool {
+	for _, p := range models.Permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,184 @@
+package server_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/JonathanGzzBen/ingenialists/api/v1/models"
+	"github.com/JonathanGzzBen/ingenialists/api/v1/repository/mocks"
+	"github.com/JonathanGzzBen/ingenialists/api/v1/server"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetAllRolesIncludesDefaultRoles(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	res, err := http.Get(fmt.Sprintf("%s/v1/roles", ts.URL))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+
+	var resRoles []models.RoleDefinition
+	err = json.NewDecoder(res.Body).Decode(&resRoles)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(resRoles) != len(models.DefaultRoles) {
+		t.Fatalf("Expected %v, got %v", len(models.DefaultRoles), len(resRoles))
+	}
+}
+
+func TestSaveRoleAsWriterReturnForbidden(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	body, err := json.Marshal(server.SaveRoleDTO{Permissions: []models.Permission{models.PermissionCategoryManage}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("%s/v1/roles/Writer", ts.URL), bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req.Header.Add(server.AccessTokenName, "Writer")
+	res, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode != http.StatusForbidden {
+		t.Fatalf("Expected status code %v, got %v", http.StatusForbidden, res.StatusCode)
+	}
+}
+
+func TestSaveRoleAsAdministratorReturnOk(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	body, err := json.Marshal(server.SaveRoleDTO{
+		Description: "Manages categories",
+		Permissions: []models.Permission{models.PermissionCategoryManage},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("%s/v1/roles/Curator", ts.URL), bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req.Header.Add(server.AccessTokenName, "Administrator")
+	res, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+
+	role, err := s.RolesRepo.GetRole(context.Background(), "Curator")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !role.HasPermission(models.PermissionCategoryManage) {
+		t.Fatalf("Expected role to have permission %v", models.PermissionCategoryManage)
+	}
+}
+
+func TestSaveAdministratorRoleReturnBadRequest(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	body, err := json.Marshal(server.SaveRoleDTO{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("%s/v1/roles/Administrator", ts.URL), bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req.Header.Add(server.AccessTokenName, "Administrator")
+	res, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected status code %v, got %v", http.StatusBadRequest, res.StatusCode)
+	}
+}
+
+func TestUpdateArticleAsEditorThatDoesNotOwnArticleReturnOk(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	aToUpdate := models.Article{
+		ID:         1,
+		Title:      "First article",
+		CategoryID: 1,
+		UserID:     2,
+	}
+
+	aUpdated := aToUpdate
+	aUpdated.Title = "Article Updated"
+
+	mockArticlesRepo := &mocks.ArticlesRepository{}
+	mockArticlesRepo.On("GetArticle", mock.Anything, aToUpdate.ID, mock.Anything).Return(&aToUpdate, nil)
+	mockArticlesRepo.On("UpdateArticle", mock.Anything, &aToUpdate).Return(&aUpdated, nil)
+	s.ArticlesRepo = mockArticlesRepo
+
+	mcJSONBytes, err := json.Marshal(aUpdated)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("%s/v1/articles/%d", ts.URL, aToUpdate.ID), bytes.NewBuffer(mcJSONBytes))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req.Header.Add(server.AccessTokenName, "Editor")
+	res, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+}
+
+func TestCreateCategoryAsEditorReturnForbidden(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	s.CategoriesRepo = &mocks.CategoriesRepository{}
+
+	mcJSONBytes, err := json.Marshal(models.Category{Name: "Databases"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/v1/categories", ts.URL), bytes.NewBuffer(mcJSONBytes))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req.Header.Add(server.AccessTokenName, "Editor")
+	res, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode != http.StatusForbidden {
+		t.Fatalf("Expected status code %v, got %v", http.StatusForbidden, res.StatusCode)
+	}
+}
@@ -0,0 +1,155 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultReferrerPolicy is used when SecurityConfig
+// doesn't specify ReferrerPolicy
+const defaultReferrerPolicy = "strict-origin-when-cross-origin"
+
+// apiContentSecurityPolicy is the Content-Security-Policy of
+// API responses, they're JSON and never load anything.
+const apiContentSecurityPolicy = "default-src 'none'; frame-ancestors 'none'"
+
+// defaultSwaggerContentSecurityPolicy is used when SecurityConfig
+// doesn't specify SwaggerContentSecurityPolicy. The swagger UI
+// has inline scripts and styles, and loads fonts from Google Fonts.
+const defaultSwaggerContentSecurityPolicy = "default-src 'self'; " +
+	"script-src 'self' 'unsafe-inline'; " +
+	"style-src 'self' 'unsafe-inline' https://fonts.googleapis.com; " +
+	"font-src 'self' https://fonts.gstatic.com; " +
+	"img-src 'self' data:; " +
+	"frame-ancestors 'none'"
+
+// swaggerPath is the prefix of the routes of the swagger UI
+const swaggerPath = "/v1/swagger/"
+
+// SecurityConfig configures the security headers of responses.
+type SecurityConfig struct {
+	// HSTSMaxAge is how long browsers must only make requests over HTTPS,
+	// sent in responses to HTTPS requests. If zero, HSTS isn't sent
+	HSTSMaxAge time.Duration
+	// HSTSIncludeSubdomains makes HSTS apply to subdomains too
+	HSTSIncludeSubdomains bool
+	// ReferrerPolicy is the Referrer-Policy header,
+	// defaultReferrerPolicy if empty
+	ReferrerPolicy string
+	// SwaggerContentSecurityPolicy is the Content-Security-Policy of
+	// the swagger UI, defaultSwaggerContentSecurityPolicy if empty
+	SwaggerContentSecurityPolicy string
+}
+
+// securityHeaders sets the security headers configured in cfg.
+func securityHeaders(cfg SecurityConfig) gin.HandlerFunc {
+	referrerPolicy := cfg.ReferrerPolicy
+	if referrerPolicy == "" {
+		referrerPolicy = defaultReferrerPolicy
+	}
+	swaggerCSP := cfg.SwaggerContentSecurityPolicy
+	if swaggerCSP == "" {
+		swaggerCSP = defaultSwaggerContentSecurityPolicy
+	}
+	hsts := fmt.Sprintf("max-age=%d", int(cfg.HSTSMaxAge.Seconds()))
+	if cfg.HSTSIncludeSubdomains {
+		hsts += "; includeSubDomains"
+	}
+	return func(c *gin.Context) {
+		h := c.Writer.Header()
+		h.Set("X-Content-Type-Options", "nosniff")
+		h.Set("X-Frame-Options", "DENY")
+		h.Set("Referrer-Policy", referrerPolicy)
+		if strings.HasPrefix(c.Request.URL.Path, swaggerPath) {
+			h.Set("Content-Security-Policy", swaggerCSP)
+		} else {
+			h.Set("Content-Security-Policy", apiContentSecurityPolicy)
+		}
+		if cfg.HSTSMaxAge > 0 && isHTTPS(c) {
+			h.Set("Strict-Transport-Security", hsts)
+		}
+		c.Next()
+	}
+}
+
+// isHTTPS reports whether the request in c was made over HTTPS,
+// to the server itself or to a trusted proxy.
+func isHTTPS(c *gin.Context) bool {
+	return c.Request.TLS != nil || c.Request.URL.Scheme == "https"
+}
+
+// parseTrustedProxies parses proxies, IP addresses or CIDRs.
+func parseTrustedProxies(proxies []string) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	for _, p := range proxies {
+		p = strings.TrimSpace(p)
+		if !strings.Contains(p, "/") {
+			ip := net.ParseIP(p)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", p)
+			}
+			if ip.To4() != nil {
+				p += "/32"
+			} else {
+				p += "/128"
+			}
+		}
+		_, n, err := net.ParseCIDR(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q", p)
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
+
+// trusted reports whether ip is in any of proxies.
+func trusted(proxies []*net.IPNet, ip net.IP) bool {
+	for _, n := range proxies {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// forwarded takes the client address, scheme and host of requests
+// from X-Forwarded-For, X-Forwarded-Proto and X-Forwarded-Host,
+// if they were made by one of proxies. Otherwise those headers
+// are ignored, since clients could send anything in them.
+//
+// The client address is the last one in X-Forwarded-For that isn't
+// a trusted proxy, so clients can't choose it by sending the header.
+func forwarded(proxies []*net.IPNet) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		host, port, err := net.SplitHostPort(c.Request.RemoteAddr)
+		if err != nil || !trusted(proxies, net.ParseIP(host)) {
+			c.Next()
+			return
+		}
+		addrs := strings.Split(c.GetHeader("X-Forwarded-For"), ",")
+		for i := len(addrs) - 1; i >= 0; i-- {
+			ip := net.ParseIP(strings.TrimSpace(addrs[i]))
+			if ip == nil {
+				break
+			}
+			c.Request.RemoteAddr = net.JoinHostPort(ip.String(), port)
+			if !trusted(proxies, ip) {
+				break
+			}
+		}
+		switch proto := strings.ToLower(c.GetHeader("X-Forwarded-Proto")); proto {
+		case "http", "https":
+			c.Request.URL.Scheme = proto
+		}
+		if h := c.GetHeader("X-Forwarded-Host"); h != "" {
+			c.Request.Host = h
+			c.Request.URL.Host = h
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,118 @@
+package server_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/JonathanGzzBen/ingenialists/api/v1/server"
+)
+
+// newProxiedServer returns a test server behind proxies that
+// lets each client make one request for categories.
+func newProxiedServer(proxies ...string) *server.Server {
+	sc := NewTestConfig()
+	sc.TrustedProxies = proxies
+	sc.Security = server.SecurityConfig{HSTSMaxAge: time.Hour}
+	sc.RateLimit = &server.RateLimitConfig{
+		Routes: map[string]server.RateLimitPolicy{
+			"GET /v1/categories/": {Rate: 0.001, Burst: 1},
+		},
+	}
+	return server.NewServer(sc)
+}
+
+func TestSecurityHeaders(t *testing.T) {
+	t.Parallel()
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	res := doAs(t, ts, http.MethodGet, "/v1/categories/", "", nil, nil)
+
+	if o := res.Header.Get("X-Content-Type-Options"); o != "nosniff" {
+		t.Fatalf("Expected %v, got %v", "nosniff", o)
+	}
+	if p := res.Header.Get("Referrer-Policy"); p == "" {
+		t.Fatalf("Expected Referrer-Policy to be set")
+	}
+	if csp := res.Header.Get("Content-Security-Policy"); !strings.Contains(csp, "default-src 'none'") {
+		t.Fatalf("Expected %v to contain %v", csp, "default-src 'none'")
+	}
+	if h := res.Header.Get("Strict-Transport-Security"); h != "" {
+		t.Fatalf("Expected no HSTS over HTTP, got %v", h)
+	}
+}
+
+func TestSecurityHeadersSwaggerContentSecurityPolicy(t *testing.T) {
+	t.Parallel()
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	res := doAs(t, ts, http.MethodGet, "/v1/swagger/index.html", "", nil, nil)
+
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+	if csp := res.Header.Get("Content-Security-Policy"); !strings.Contains(csp, "script-src 'self' 'unsafe-inline'") {
+		t.Fatalf("Expected %v to allow the swagger UI scripts", csp)
+	}
+}
+
+func TestTrustedProxyForwardedHeaders(t *testing.T) {
+	t.Parallel()
+	s := newProxiedServer("127.0.0.1")
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	res := doAs(t, ts, http.MethodGet, "/v1/categories/", "", nil, map[string]string{"X-Forwarded-For": "203.0.113.1", "X-Forwarded-Proto": "https"})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+	if h := res.Header.Get("Strict-Transport-Security"); h != "max-age=3600" {
+		t.Fatalf("Expected %v, got %v", "max-age=3600", h)
+	}
+
+	// Clients forwarded by the proxy are limited by their own address
+	res = doAs(t, ts, http.MethodGet, "/v1/categories/", "", nil, map[string]string{"X-Forwarded-For": "203.0.113.2", "X-Forwarded-Proto": "https"})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+	res = doAs(t, ts, http.MethodGet, "/v1/categories/", "", nil, map[string]string{"X-Forwarded-For": "198.51.100.1, 203.0.113.1", "X-Forwarded-Proto": "https"})
+	if res.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("Expected status code %v, got %v", http.StatusTooManyRequests, res.StatusCode)
+	}
+}
+
+func TestUntrustedProxyForwardedHeadersIgnored(t *testing.T) {
+	t.Parallel()
+	s := newProxiedServer("10.0.0.1")
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	res := doAs(t, ts, http.MethodGet, "/v1/categories/", "", nil, map[string]string{"X-Forwarded-For": "203.0.113.1", "X-Forwarded-Proto": "https"})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+	if h := res.Header.Get("Strict-Transport-Security"); h != "" {
+		t.Fatalf("Expected no HSTS over HTTP, got %v", h)
+	}
+
+	res = doAs(t, ts, http.MethodGet, "/v1/categories/", "", nil, map[string]string{"X-Forwarded-For": "203.0.113.2", "X-Forwarded-Proto": "https"})
+	if res.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("Expected status code %v, got %v", http.StatusTooManyRequests, res.StatusCode)
+	}
+}
+
+func TestNewServerWithInvalidTrustedProxyPanics(t *testing.T) {
+	t.Parallel()
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("Expected NewServer to panic")
+		}
+	}()
+	newProxiedServer("not an address")
+}
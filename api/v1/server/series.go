@@ -0,0 +1,346 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/JonathanGzzBen/ingenialists/api/v1/models"
+	"github.com/JonathanGzzBen/ingenialists/api/v1/repository"
+	"github.com/gin-gonic/gin"
+)
+
+type SaveSeriesDTO struct {
+	Title       string `json:"title" binding:"required"`
+	Description string `json:"description"`
+}
+
+type SetSeriesArticlesDTO struct {
+	// ArticleIDs are the IDs of the articles in the series, in order
+	ArticleIDs []uint `json:"articleIds"`
+}
+
+// GetAllSeries is the handler for GET requests to /series
+// 	@ID GetAllSeries
+// 	@Summary Get all series
+// 	@Description Get all series with their published articles in order.
+// 	@Tags series
+// 	@Success 200 {array} models.Series
+// 	@Failure 500 {object} models.APIError
+// 	@Router /series [get]
+func (s *Server) GetAllSeries(c *gin.Context) {
+	series, err := s.SeriesRepo.GetAllSeries(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: "could not get series"})
+		return
+	}
+	visible := s.seriesArticleVisibility(c)
+	for i := range series {
+		hideUnpublishedSeriesArticles(&series[i], visible)
+	}
+	c.JSON(http.StatusOK, series)
+}
+
+// GetSeries is the handler for GET requests to /series/:id
+// 	@ID GetSeries
+// 	@Summary Get series
+// 	@Description Get series with matching ID with its published articles in order.
+// 	@Tags series
+// 	@Param id path int true "Series ID"
+// 	@Success 200 {object} models.Series
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 404 {object} models.APIError
+// 	@Failure 500 {object} models.APIError
+// 	@Router /series/{id} [get]
+func (s *Server) GetSeries(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "invalid id: " + err.Error()})
+		return
+	}
+	series, err := s.SeriesRepo.GetSeries(c.Request.Context(), uint(id))
+	if err == repository.ErrNotFound {
+		c.JSON(http.StatusNotFound, models.APIError{Code: http.StatusNotFound, Message: "series not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+	hideUnpublishedSeriesArticles(series, s.seriesArticleVisibility(c))
+	c.JSON(http.StatusOK, series)
+}
+
+// CreateSeries is the handler for POST requests to /series
+// 	@ID CreateSeries
+// 	@Summary Create series
+// 	@Description Register a new series without articles.
+// 	@Tags series
+// 	@Security AccessToken
+// 	@Param series body SaveSeriesDTO true "Series"
+// 	@Success 200 {object} models.Series
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 403 {object} models.APIError
+// 	@Failure 500 {object} models.APIError
+// 	@Router /series [post]
+func (s *Server) CreateSeries(c *gin.Context) {
+	au, err := s.authenticate(c)
+	if err != nil {
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "you must be authenticated to create a series"})
+		return
+	}
+	if !hasScope(c, models.ScopeArticlesWrite) {
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "api key lacks scope " + string(models.ScopeArticlesWrite)})
+		return
+	}
+	if !s.can(c.Request.Context(), au, models.PermissionArticlePublish) {
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "you don't have permission to publish articles"})
+		return
+	}
+	var ss SaveSeriesDTO
+	if err := c.ShouldBindJSON(&ss); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "invalid series: " + err.Error()})
+		return
+	}
+	series, err := s.SeriesRepo.CreateSeries(c.Request.Context(), &models.Series{
+		UserID:      au.ID,
+		Title:       ss.Title,
+		Description: ss.Description,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: "could not create series: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, series)
+}
+
+// UpdateSeries is the handler for PUT requests to /series/:id
+// 	@ID UpdateSeries
+// 	@Summary Update series
+// 	@Description Update title and description of series.
+// 	@Tags series
+// 	@Security AccessToken
+// 	@Param id path int true "Series ID"
+// 	@Param series body SaveSeriesDTO true "Series"
+// 	@Success 200 {object} models.Series
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 403 {object} models.APIError
+// 	@Failure 404 {object} models.APIError
+// 	@Failure 500 {object} models.APIError
+// 	@Router /series/{id} [put]
+func (s *Server) UpdateSeries(c *gin.Context) {
+	_, series, ok := s.seriesOwner(c)
+	if !ok {
+		return
+	}
+	var ss SaveSeriesDTO
+	if err := c.ShouldBindJSON(&ss); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "invalid series: " + err.Error()})
+		return
+	}
+	series.Title = ss.Title
+	series.Description = ss.Description
+	series, err := s.SeriesRepo.UpdateSeries(c.Request.Context(), series)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: "could not update series: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, series)
+}
+
+// DeleteSeries is the handler for DELETE requests to /series/:id
+// 	@ID DeleteSeries
+// 	@Summary Delete series
+// 	@Description Delete series with matching ID, its articles are kept.
+// 	@Tags series
+// 	@Security AccessToken
+// 	@Param id path int true "Series ID"
+// 	@Success 204 {object} string
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 403 {object} models.APIError
+// 	@Failure 404 {object} models.APIError
+// 	@Failure 500 {object} models.APIError
+// 	@Router /series/{id} [delete]
+func (s *Server) DeleteSeries(c *gin.Context) {
+	_, series, ok := s.seriesOwner(c)
+	if !ok {
+		return
+	}
+	if err := s.SeriesRepo.DeleteSeries(c.Request.Context(), series.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: "could not delete series: " + err.Error()})
+		return
+	}
+	c.String(http.StatusNoContent, "deleted")
+}
+
+// SetSeriesArticles is the handler for PUT requests to /series/:id/articles
+// 	@ID SetSeriesArticles
+// 	@Summary Set articles of series
+// 	@Description Replace the articles of series, in the provided order.
+// 	@Description You must be an author of every article added.
+// 	@Tags series
+// 	@Security AccessToken
+// 	@Param id path int true "Series ID"
+// 	@Param articles body SetSeriesArticlesDTO true "Articles"
+// 	@Success 200 {object} models.Series
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 403 {object} models.APIError
+// 	@Failure 404 {object} models.APIError
+// 	@Failure 409 {object} models.APIError
+// 	@Failure 500 {object} models.APIError
+// 	@Router /series/{id}/articles [put]
+func (s *Server) SetSeriesArticles(c *gin.Context) {
+	au, series, ok := s.seriesOwner(c)
+	if !ok {
+		return
+	}
+	var ssa SetSeriesArticlesDTO
+	if err := c.ShouldBindJSON(&ssa); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "invalid articles: " + err.Error()})
+		return
+	}
+	inSeries := map[uint]bool{}
+	for _, sa := range series.Articles {
+		inSeries[sa.ArticleID] = true
+	}
+	seen := map[uint]bool{}
+	for _, aid := range ssa.ArticleIDs {
+		if seen[aid] {
+			c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "articles can only appear once in a series"})
+			return
+		}
+		seen[aid] = true
+		// Articles already in the series can be reordered by its owner
+		if inSeries[aid] {
+			continue
+		}
+		article, err := s.ArticlesRepo.GetArticle(c.Request.Context(), aid, repository.WholeArticle)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "article " + strconv.Itoa(int(aid)) + " not found"})
+			return
+		}
+		if !isArticleAuthor(article, au.ID) && !s.can(c.Request.Context(), au, models.PermissionArticleEditAny) {
+			c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "you can only add articles you are an author of"})
+			return
+		}
+	}
+	series, err := s.SeriesRepo.SetSeriesArticles(c.Request.Context(), series.ID, ssa.ArticleIDs)
+	if err == repository.ErrArticleInOtherSeries {
+		c.JSON(http.StatusConflict, models.APIError{Code: http.StatusConflict, Message: err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: "could not set articles of series: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, series)
+}
+
+// seriesOwner authenticates the request in c and gets the series
+// in its path, writing an error response if the authenticated user
+// can't modify the series.
+func (s *Server) seriesOwner(c *gin.Context) (*models.User, *models.Series, bool) {
+	au, err := s.authenticate(c)
+	if err != nil {
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "you must be authenticated to modify a series"})
+		return nil, nil, false
+	}
+	if !hasScope(c, models.ScopeArticlesWrite) {
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "api key lacks scope " + string(models.ScopeArticlesWrite)})
+		return nil, nil, false
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "invalid id: " + err.Error()})
+		return nil, nil, false
+	}
+	series, err := s.SeriesRepo.GetSeries(c.Request.Context(), uint(id))
+	if err == repository.ErrNotFound {
+		c.JSON(http.StatusNotFound, models.APIError{Code: http.StatusNotFound, Message: "series not found"})
+		return nil, nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return nil, nil, false
+	}
+	if series.UserID != au.ID && !s.can(c.Request.Context(), au, models.PermissionArticleEditAny) {
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "you can only modify series created by you"})
+		return nil, nil, false
+	}
+	return au, series, true
+}
+
+// seriesArticleVisibility returns whether articles of series are
+// visible to the request in c, authenticating it only once.
+func (s *Server) seriesArticleVisibility(c *gin.Context) func(*models.Article) bool {
+	au, err := s.authenticate(c)
+	if err != nil {
+		au = nil
+	}
+	return s.articleVisibility(c.Request.Context(), au)
+}
+
+// hideUnpublishedSeriesArticles removes from series
+// the articles that visible reports can't be seen.
+func hideUnpublishedSeriesArticles(series *models.Series, visible func(*models.Article) bool) {
+	articles := series.Articles[:0]
+	for _, sa := range series.Articles {
+		if sa.Article != nil && visible(sa.Article) {
+			articles = append(articles, sa)
+		}
+	}
+	series.Articles = articles
+}
+
+// attachSeries sets the series navigation of articles
+// that are part of a series. Positions, totals and links
+// only count the articles of series the request in c can see.
+func (s *Server) attachSeries(c *gin.Context, articles []models.Article) error {
+	ids := make([]uint, 0, len(articles))
+	for _, a := range articles {
+		ids = append(ids, a.ID)
+	}
+	series, err := s.SeriesRepo.GetSeriesByArticles(c.Request.Context(), ids)
+	if err != nil {
+		return err
+	}
+	visible := s.seriesArticleVisibility(c)
+	navigation := map[uint]*models.SeriesNavigation{}
+	for _, sr := range series {
+		hideUnpublishedSeriesArticles(&sr, visible)
+		for i, sa := range sr.Articles {
+			nav := &models.SeriesNavigation{
+				ID:       sr.ID,
+				Title:    sr.Title,
+				Position: i + 1,
+				Total:    len(sr.Articles),
+			}
+			if i > 0 {
+				nav.Previous = articleLink(sr.Articles[i-1].Article)
+			}
+			if i+1 < len(sr.Articles) {
+				nav.Next = articleLink(sr.Articles[i+1].Article)
+			}
+			navigation[sa.ArticleID] = nav
+		}
+	}
+	for i := range articles {
+		articles[i].Series = navigation[articles[i].ID]
+	}
+	return nil
+}
+
+// attachArticleSeries sets the series navigation of a
+// if it is part of a series.
+func (s *Server) attachArticleSeries(c *gin.Context, a *models.Article) error {
+	articles := []models.Article{*a}
+	if err := s.attachSeries(c, articles); err != nil {
+		return err
+	}
+	a.Series = articles[0].Series
+	return nil
+}
+
+// articleLink returns a link to a.
+func articleLink(a *models.Article) *models.ArticleLink {
+	return &models.ArticleLink{ID: a.ID, Title: a.Title, Slug: a.Slug}
+}
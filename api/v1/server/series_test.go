@@ -0,0 +1,194 @@
+package server_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/JonathanGzzBen/ingenialists/api/v1/models"
+	"github.com/JonathanGzzBen/ingenialists/api/v1/server"
+)
+
+// createSeries registers a series of three articles
+// written by user with ID = 1.
+func createSeries(t *testing.T, s *server.Server) (*models.Series, []*models.Article) {
+	first := createArticle(t, s)
+	articles := []*models.Article{first}
+	for _, title := range []string{"Second part", "Third part"} {
+		a, err := s.ArticlesRepo.CreateArticle(context.Background(), &models.Article{UserID: 1, CategoryID: first.CategoryID, Title: title})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		articles = append(articles, a)
+	}
+	series, err := s.SeriesRepo.CreateSeries(context.Background(), &models.Series{UserID: 1, Title: "Database internals"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	series, err = s.SeriesRepo.SetSeriesArticles(context.Background(), series.ID, []uint{articles[0].ID, articles[1].ID, articles[2].ID})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return series, articles
+}
+
+func TestCreateSeriesAsReaderReturnForbidden(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/v1/series", ts.URL), bytes.NewBufferString(`{"title":"Database internals"}`))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req.Header.Add(server.AccessTokenName, "Reader")
+	res, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode != http.StatusForbidden {
+		t.Fatalf("Expected status code %v, got %v", http.StatusForbidden, res.StatusCode)
+	}
+}
+
+func TestSetSeriesArticlesAsWriterReturnOk(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	series, articles := createSeries(t, s)
+
+	body := fmt.Sprintf(`{"articleIds":[%d,%d]}`, articles[2].ID, articles[0].ID)
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("%s/v1/series/%d/articles", ts.URL, series.ID), bytes.NewBufferString(body))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req.Header.Add(server.AccessTokenName, "Writer")
+	res, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+	var resSeries models.Series
+	if err := json.NewDecoder(res.Body).Decode(&resSeries); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(resSeries.Articles) != 2 || resSeries.Articles[0].ArticleID != articles[2].ID || resSeries.Articles[1].Position != 2 {
+		t.Fatalf("Expected articles %v and %v in order, got %v", articles[2].ID, articles[0].ID, resSeries.Articles)
+	}
+}
+
+func TestSetSeriesArticlesAlreadyInOtherSeriesReturnConflict(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	_, articles := createSeries(t, s)
+	other, err := s.SeriesRepo.CreateSeries(context.Background(), &models.Series{UserID: 1, Title: "Other series"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	body := fmt.Sprintf(`{"articleIds":[%d]}`, articles[0].ID)
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("%s/v1/series/%d/articles", ts.URL, other.ID), bytes.NewBufferString(body))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req.Header.Add(server.AccessTokenName, "Writer")
+	res, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode != http.StatusConflict {
+		t.Fatalf("Expected status code %v, got %v", http.StatusConflict, res.StatusCode)
+	}
+}
+
+func TestGetArticleInSeriesHasPreviousAndNext(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	series, articles := createSeries(t, s)
+
+	res, err := http.Get(fmt.Sprintf("%s/v1/articles/%d", ts.URL, articles[1].ID))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+	var a models.Article
+	if err := json.NewDecoder(res.Body).Decode(&a); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if a.Series == nil {
+		t.Fatalf("Expected article to be part of series %v", series.ID)
+	}
+	if a.Series.ID != series.ID || a.Series.Position != 2 || a.Series.Total != 3 {
+		t.Fatalf("Expected part %v of %v in series %v, got %v", 2, 3, series.ID, *a.Series)
+	}
+	if a.Series.Previous == nil || a.Series.Previous.ID != articles[0].ID {
+		t.Fatalf("Expected previous article %v, got %v", articles[0].ID, a.Series.Previous)
+	}
+	if a.Series.Next == nil || a.Series.Next.Slug != articles[2].Slug {
+		t.Fatalf("Expected next article %v, got %v", articles[2].Slug, a.Series.Next)
+	}
+}
+
+func TestSeriesOnlyShowArticlesVisibleToClient(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	series, articles := createSeries(t, s)
+	// The second part is scheduled, created by someone else
+	// and co-authored by user with ID = 1
+	later := time.Now().Add(time.Hour)
+	scheduled := *articles[1]
+	scheduled.UserID = 3
+	scheduled.PublishAt = &later
+	scheduled.PublishedAt = nil
+	scheduled.Body = "Body of the second part"
+	if _, err := s.ArticlesRepo.UpdateArticle(context.Background(), &scheduled); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for token, expected := range map[string]int{"": 2, "Reader": 3} {
+		res := doAs(t, ts, http.MethodGet, fmt.Sprintf("/v1/series/%d", series.ID), token, nil, nil)
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+		}
+		var got models.Series
+		if err := json.NewDecoder(res.Body).Decode(&got); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(got.Articles) != expected {
+			t.Fatalf("Expected %v articles for %q, got %v", expected, token, len(got.Articles))
+		}
+		for _, sa := range got.Articles {
+			if sa.Article.Body != "" {
+				t.Fatalf("Expected articles of series to leave body out")
+			}
+		}
+	}
+
+	res := doAs(t, ts, http.MethodGet, fmt.Sprintf("/v1/articles/%d", articles[0].ID), "", nil, nil)
+	var a models.Article
+	if err := json.NewDecoder(res.Body).Decode(&a); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if a.Series == nil || a.Series.Total != 2 {
+		t.Fatalf("Expected article to be part 1 of 2, got %+v", a.Series)
+	}
+	if a.Series.Next == nil || a.Series.Next.ID != articles[2].ID {
+		t.Fatalf("Expected next article %v, got %v", articles[2].ID, a.Series.Next)
+	}
+}
@@ -1,38 +1,136 @@
 package server
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"net/http"
+	"os"
+	"time"
+
 	"github.com/JonathanGzzBen/ingenialists/api/v1/repository"
 	"github.com/gin-gonic/gin"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"github.com/swaggo/gin-swagger/swaggerFiles"
 )
 
+// defaultRefreshTokenTTL is used when ServerConfig
+// doesn't specify RefreshTokenTTL
+const defaultRefreshTokenTTL = 30 * 24 * time.Hour
+
 type Server struct {
-	googleClient   IGoogleClient
-	googleConfig   IOauthConfig
-	development    bool
-	Router         *gin.Engine
-	CategoriesRepo repository.CategoriesRepository
-	UsersRepo      repository.UsersRepository
-	ArticlesRepo   repository.ArticlesRepository
+	googleClient       IGoogleClient
+	googleConfig       IOauthConfig
+	development        bool
+	tokenEncryptionKey []byte
+	refreshTokenTTL    time.Duration
+	trashRetention     time.Duration
+	tls                *TLSConfig
+	Router             *gin.Engine
+	CategoriesRepo     repository.CategoriesRepository
+	UsersRepo          repository.UsersRepository
+	ArticlesRepo       repository.ArticlesRepository
+	RefreshTokensRepo  repository.RefreshTokensRepository
+	APIKeysRepo        repository.APIKeysRepository
+	RolesRepo          repository.RolesRepository
+	RoleRequestsRepo   repository.RoleRequestsRepository
+	NotificationsRepo  repository.NotificationsRepository
+	AuditRepo          repository.AuditRepository
+	SeriesRepo         repository.SeriesRepository
+	UnitOfWork         repository.UnitOfWork
 }
 
 type ServerConfig struct {
-	GoogleConfig   IOauthConfig
-	Hostname       string
-	Development    bool
-	CategoriesRepo repository.CategoriesRepository
-	UsersRepo      repository.UsersRepository
-	ArticlesRepo   repository.ArticlesRepository
+	GoogleConfig IOauthConfig
+	Hostname     string
+	Development  bool
+	// TokenEncryptionKey is the AES key used to encrypt
+	// provider refresh tokens at rest, it must be 16, 24 or 32 bytes long.
+	// If empty, a random key is generated, so stored tokens
+	// won't survive a restart, which is only meant for development.
+	TokenEncryptionKey []byte
+	// RefreshTokenTTL is how long refresh tokens issued to clients are valid
+	RefreshTokenTTL time.Duration
+	// TrashRetention is how long deleted articles and categories
+	// can be restored before they're permanently deleted
+	TrashRetention time.Duration
+	// CacheControl is the Cache-Control header of GET responses
+	// by route, like "/v1/articles/:id". Routes not listed
+	// use defaultCacheControl.
+	CacheControl      map[string]string
+	CategoriesRepo    repository.CategoriesRepository
+	UsersRepo         repository.UsersRepository
+	ArticlesRepo      repository.ArticlesRepository
+	RefreshTokensRepo repository.RefreshTokensRepository
+	APIKeysRepo       repository.APIKeysRepository
+	RolesRepo         repository.RolesRepository
+	RoleRequestsRepo  repository.RoleRequestsRepository
+	NotificationsRepo repository.NotificationsRepository
+	AuditRepo         repository.AuditRepository
+	SeriesRepo        repository.SeriesRepository
+	// UnitOfWork makes changes to users, articles and categories
+	// atomically, if nil they are made one by one
+	UnitOfWork repository.UnitOfWork
+	// Cache configures the cache of users, articles and categories
+	// read often, if nil every read is made to their repositories
+	Cache *repository.CacheConfig
+	// RateLimit configures the rate limits of clients,
+	// if nil requests aren't limited
+	RateLimit *RateLimitConfig
+	// CORS configures the origins browsers let make requests,
+	// if nil development servers use DevelopmentCORSConfig
+	// and others only allow requests from their own origin
+	CORS *CORSConfig
+	// Security configures the security headers of responses
+	Security SecurityConfig
+	// TLS configures serving HTTPS, if nil Run serves HTTP
+	TLS *TLSConfig
+	// TrustedProxies are the IP addresses or CIDRs of the proxies
+	// whose X-Forwarded-For, X-Forwarded-Proto and X-Forwarded-Host
+	// headers are trusted, if empty those headers are ignored
+	TrustedProxies []string
 }
 
 func NewServer(sc ServerConfig) *Server {
 	server := &Server{
-		googleConfig:   sc.GoogleConfig,
-		development:    sc.Development,
-		CategoriesRepo: sc.CategoriesRepo,
-		UsersRepo:      sc.UsersRepo,
-		ArticlesRepo:   sc.ArticlesRepo,
+		googleConfig:       sc.GoogleConfig,
+		development:        sc.Development,
+		tokenEncryptionKey: sc.TokenEncryptionKey,
+		refreshTokenTTL:    sc.RefreshTokenTTL,
+		trashRetention:     sc.TrashRetention,
+		tls:                sc.TLS,
+		CategoriesRepo:     sc.CategoriesRepo,
+		UsersRepo:          sc.UsersRepo,
+		ArticlesRepo:       sc.ArticlesRepo,
+		RefreshTokensRepo:  sc.RefreshTokensRepo,
+		APIKeysRepo:        sc.APIKeysRepo,
+		RolesRepo:          sc.RolesRepo,
+		RoleRequestsRepo:   sc.RoleRequestsRepo,
+		NotificationsRepo:  sc.NotificationsRepo,
+		AuditRepo:          sc.AuditRepo,
+		SeriesRepo:         sc.SeriesRepo,
+		UnitOfWork:         sc.UnitOfWork,
+	}
+	if server.UnitOfWork == nil {
+		server.UnitOfWork = repository.NonTransactionalUnitOfWork{}
+	}
+	if sc.Cache != nil {
+		cache := repository.NewCache(*sc.Cache)
+		server.UsersRepo = repository.NewCachedUsersRepository(server.UsersRepo, cache)
+		server.ArticlesRepo = repository.NewCachedArticlesRepository(server.ArticlesRepo, cache)
+		server.CategoriesRepo = repository.NewCachedCategoriesRepository(server.CategoriesRepo, cache)
+	}
+	if len(server.tokenEncryptionKey) == 0 {
+		server.tokenEncryptionKey = make([]byte, 32)
+		if _, err := rand.Read(server.tokenEncryptionKey); err != nil {
+			panic("Could not generate token encryption key")
+		}
+	}
+	if server.refreshTokenTTL == 0 {
+		server.refreshTokenTTL = defaultRefreshTokenTTL
+	}
+	if server.trashRetention == 0 {
+		server.trashRetention = defaultTrashRetention
 	}
 	if sc.Development {
 		server.googleClient = &GoogleClientMock{}
@@ -40,7 +138,31 @@ func NewServer(sc ServerConfig) *Server {
 		server.googleClient = &GoogleClient{}
 	}
 
+	proxies, err := parseTrustedProxies(sc.TrustedProxies)
+	if err != nil {
+		panic(err.Error())
+	}
+
 	router := gin.Default()
+	// Forwarded headers are handled by forwarded,
+	// so ClientIP is the address it takes from them
+	router.ForwardedByClientIP = false
+	router.TrustedProxies = nil
+	router.Use(forwarded(proxies))
+	router.Use(securityHeaders(sc.Security))
+	router.Use(requestID())
+	if sc.CORS != nil {
+		if err := sc.CORS.validate(); err != nil {
+			panic(err.Error())
+		}
+		router.Use(cors(*sc.CORS))
+	} else if sc.Development {
+		router.Use(cors(DevelopmentCORSConfig))
+	}
+	if sc.RateLimit != nil {
+		router.Use(server.rateLimit(sc.RateLimit))
+	}
+	router.Use(conditionalGET(sc.CacheControl))
 	v1 := router.Group("/v1")
 	{
 		ur := v1.Group("/users")
@@ -48,31 +170,78 @@ func NewServer(sc ServerConfig) *Server {
 			ur.GET("/", server.GetAllUsers)
 			ur.GET("/:id", server.GetUser)
 			ur.PUT("/:id", server.UpdateUser)
+			ur.PATCH("/:id", server.PatchUser)
+			ur.GET("/:id/articles", server.GetUserArticles)
+			ur.GET("/:id/api-keys", server.GetAPIKeys)
+			ur.POST("/:id/api-keys", server.CreateAPIKey)
+			ur.DELETE("/:id/api-keys/:keyId", server.RevokeAPIKey)
 		}
 		ar := v1.Group("/auth")
 		{
 			ar.GET("/", server.GetCurrentUser)
 			ar.GET("/google-login", server.LoginGoogle)
 			ar.GET("/google-callback", server.GoogleCallback)
+			ar.POST("/refresh", server.RefreshToken)
 			if sc.Development {
 				ar.GET("/dev-authorize", server.devOAuthAuthorize)
 			}
 		}
+		rr := v1.Group("/roles")
+		{
+			rr.GET("/", server.GetAllRoles)
+			rr.GET("/:name", server.GetRole)
+			rr.PUT("/:name", server.SaveRole)
+		}
+		rqr := v1.Group("/role-requests")
+		{
+			rqr.GET("/", server.GetAllRoleRequests)
+			rqr.GET("/:id", server.GetRoleRequest)
+			rqr.POST("/", server.CreateRoleRequest)
+			rqr.POST("/:id/approve", server.ApproveRoleRequest)
+			rqr.POST("/:id/reject", server.RejectRoleRequest)
+		}
+		nr := v1.Group("/notifications")
+		{
+			nr.GET("/", server.GetNotifications)
+			nr.PUT("/:id/read", server.ReadNotification)
+		}
+		v1.GET("/audit", server.GetAuditEntries)
 		cr := v1.Group("/categories")
 		{
 			cr.GET("/", server.GetAllCategories)
+			cr.GET("/trash", server.GetDeletedCategories)
+			cr.GET("/tree", server.GetCategoryTree)
+			cr.GET("/by-slug/:slug", server.GetCategoryBySlug)
 			cr.GET("/:id", server.GetCategory)
+			cr.POST("/:id/restore", server.RestoreCategory)
 			cr.POST("/", server.CreateCategory)
 			cr.PUT("/:id", server.UpdateCategory)
+			cr.PATCH("/:id", server.PatchCategory)
 			cr.DELETE("/:id", server.DeleteCategory)
 		}
+		sr := v1.Group("/series")
+		{
+			sr.GET("/", server.GetAllSeries)
+			sr.GET("/:id", server.GetSeries)
+			sr.POST("/", server.CreateSeries)
+			sr.PUT("/:id", server.UpdateSeries)
+			sr.DELETE("/:id", server.DeleteSeries)
+			sr.PUT("/:id/articles", server.SetSeriesArticles)
+		}
 		arr := v1.Group("/articles")
 		{
 			arr.GET("/", server.GetAllArticles)
+			arr.GET("/trash", server.GetDeletedArticles)
+			arr.GET("/by-slug/:slug", server.GetArticleBySlug)
 			arr.GET("/:id", server.GetArticle)
+			arr.POST("/:id/restore", server.RestoreArticle)
 			arr.POST("/", server.CreateArticle)
+			arr.POST("/bulk", server.BulkArticles)
 			arr.PUT("/:id", server.UpdateArticle)
+			arr.PATCH("/:id", server.PatchArticle)
 			arr.DELETE("/:id", server.DeleteArticle)
+			arr.PUT("/:id/authors/:userId", server.SaveArticleAuthor)
+			arr.DELETE("/:id/authors/:userId", server.RemoveArticleAuthor)
 		}
 	}
 
@@ -82,6 +251,48 @@ func NewServer(sc ServerConfig) *Server {
 	return server
 }
 
-func (s *Server) Run(port ...string) {
-	s.Router.Run(port[0])
+// inUnitOfWork calls fn with the repositories of s
+// bound to a unit of work.
+func (s *Server) inUnitOfWork(ctx context.Context, fn func(repository.Repositories) error) error {
+	return s.UnitOfWork.Do(ctx, repository.Repositories{
+		Users:        s.UsersRepo,
+		Articles:     s.ArticlesRepo,
+		Categories:   s.CategoriesRepo,
+		RoleRequests: s.RoleRequestsRepo,
+	}, fn)
+}
+
+// Run serves requests on port, or on the port in the PORT
+// environment variable if not given, or on :8080 if it's not set.
+// It serves HTTPS if the server was configured with TLS.
+func (s *Server) Run(port ...string) error {
+	srv := &http.Server{
+		Addr:    address(port),
+		Handler: s.Router,
+	}
+	if s.tls == nil {
+		s.startBackgroundJobs()
+		return srv.ListenAndServe()
+	}
+	r, err := newCertReloader(*s.tls)
+	if err != nil {
+		return err
+	}
+	srv.TLSConfig = &tls.Config{
+		GetCertificate: r.GetCertificate,
+		MinVersion:     tls.VersionTLS12,
+	}
+	s.startBackgroundJobs()
+	return srv.ListenAndServeTLS("", "")
+}
+
+// address returns the address to serve requests on port.
+func address(port []string) string {
+	if len(port) > 0 && port[0] != "" {
+		return port[0]
+	}
+	if p := os.Getenv("PORT"); p != "" {
+		return ":" + p
+	}
+	return ":8080"
 }
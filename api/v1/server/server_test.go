@@ -1,7 +1,12 @@
 package server_test
 
 import (
+	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
 
 	"github.com/JonathanGzzBen/ingenialists/api/v1/repository"
 	"github.com/JonathanGzzBen/ingenialists/api/v1/server"
@@ -9,50 +14,78 @@ import (
 	"gorm.io/gorm"
 )
 
+// testDir holds the databases of the servers of tests,
+// it's removed when they finish
+var testDir string
+
+// testDatabases is the number of databases created in testDir
+var testDatabases uint64
+
+func TestMain(m *testing.M) {
+	dir, err := ioutil.TempDir("", "ingenialists")
+	if err != nil {
+		panic("Could not create test directory")
+	}
+	testDir = dir
+	code := m.Run()
+	os.RemoveAll(testDir)
+	os.Exit(code)
+}
+
 type TestEnvironment struct {
-	Server *server.Server
+	Server   *server.Server
+	database string
 }
 
 func (e *TestEnvironment) Close() {
-	os.Remove("test.db")
+	os.Remove(e.database)
 }
 
-func NewTestServer() *server.Server {
-	os.Remove("test.db")
-	db, err := gorm.Open(sqlite.Open("test.db"), &gorm.Config{})
+// newTestDatabase returns the path of a database
+// no other server of the tests uses.
+func newTestDatabase() string {
+	n := atomic.AddUint64(&testDatabases, 1)
+	return filepath.Join(testDir, fmt.Sprintf("test-%d.db", n))
+}
+
+// NewTestConfig returns the configuration of a server in development
+// mode using a new database of its own, so tests can run in parallel.
+func NewTestConfig() server.ServerConfig {
+	return testConfig(newTestDatabase())
+}
+
+func testConfig(database string) server.ServerConfig {
+	db, err := gorm.Open(sqlite.Open(database), &gorm.Config{})
 	if err != nil {
 		panic("Could not connect to database")
 	}
-	return server.NewServer(
-		server.ServerConfig{
-			GoogleConfig:   &OAuth2ConfigMock{},
-			Hostname:       "http://localhost:8080",
-			Development:    true,
-			CategoriesRepo: repository.NewCategoriesGormRepository(db),
-			UsersRepo:      repository.NewUsersGormRepository(db),
-			ArticlesRepo:   repository.NewArticlesGormRepository(db),
-		},
-	)
+	return server.ServerConfig{
+		GoogleConfig:      &OAuth2ConfigMock{},
+		Hostname:          "http://localhost:8080",
+		Development:       true,
+		CategoriesRepo:    repository.NewCategoriesGormRepository(db),
+		UsersRepo:         repository.NewUsersGormRepository(db),
+		ArticlesRepo:      repository.NewArticlesGormRepository(db),
+		RefreshTokensRepo: repository.NewRefreshTokensGormRepository(db),
+		APIKeysRepo:       repository.NewAPIKeysGormRepository(db),
+		RolesRepo:         repository.NewRolesGormRepository(db),
+		RoleRequestsRepo:  repository.NewRoleRequestsGormRepository(db),
+		NotificationsRepo: repository.NewNotificationsGormRepository(db),
+		AuditRepo:         repository.NewAuditGormRepository(db),
+		SeriesRepo:        repository.NewSeriesGormRepository(db),
+		UnitOfWork:        repository.NewGormUnitOfWork(db),
+	}
+}
+
+func NewTestServer() *server.Server {
+	return server.NewServer(NewTestConfig())
 }
 
 func NewTestEnvironment() *TestEnvironment {
-	os.Remove("test.db")
-	db, err := gorm.Open(sqlite.Open("test.db"), &gorm.Config{})
-	if err != nil {
-		panic("Could not connect to database")
-	}
-	server := server.NewServer(
-		server.ServerConfig{
-			GoogleConfig:   &OAuth2ConfigMock{},
-			Hostname:       "http://localhost:8080",
-			Development:    true,
-			CategoriesRepo: repository.NewCategoriesGormRepository(db),
-			UsersRepo:      repository.NewUsersGormRepository(db),
-			ArticlesRepo:   repository.NewArticlesGormRepository(db),
-		},
-	)
+	database := newTestDatabase()
 	ts := &TestEnvironment{
-		Server: server,
+		Server:   server.NewServer(testConfig(database)),
+		database: database,
 	}
 	return ts
 }
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"crypto/tls"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// defaultCertReloadInterval is used when TLSConfig
+// doesn't specify ReloadInterval
+const defaultCertReloadInterval = time.Minute
+
+// TLSConfig configures serving HTTPS.
+type TLSConfig struct {
+	// CertFile and KeyFile are the PEM encoded certificate
+	// chain and private key of the server
+	CertFile string
+	KeyFile  string
+	// ReloadInterval is how often the files are checked for changes,
+	// so renewed certificates are used without a restart.
+	// defaultCertReloadInterval if zero
+	ReloadInterval time.Duration
+}
+
+// certReloader keeps the certificate in the files of a TLSConfig,
+// loading it again when they change. It's safe for concurrent use.
+type certReloader struct {
+	cfg TLSConfig
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	modTime   time.Time
+	lastCheck time.Time
+}
+
+// newCertReloader loads the certificate in the files of cfg.
+func newCertReloader(cfg TLSConfig) (*certReloader, error) {
+	if cfg.ReloadInterval <= 0 {
+		cfg.ReloadInterval = defaultCertReloadInterval
+	}
+	r := &certReloader{cfg: cfg}
+	if err := r.load(time.Now()); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// filesModTime returns when the certificate or key file
+// was last modified, whichever is later.
+func (r *certReloader) filesModTime() (time.Time, error) {
+	var latest time.Time
+	for _, f := range []string{r.cfg.CertFile, r.cfg.KeyFile} {
+		fi, err := os.Stat(f)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if fi.ModTime().After(latest) {
+			latest = fi.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+func (r *certReloader) load(now time.Time) error {
+	r.lastCheck = now
+	modTime, err := r.filesModTime()
+	if err != nil {
+		return err
+	}
+	if r.cert != nil && modTime.Equal(r.modTime) {
+		return nil
+	}
+	cert, err := tls.LoadX509KeyPair(r.cfg.CertFile, r.cfg.KeyFile)
+	if err != nil {
+		return err
+	}
+	r.cert = &cert
+	r.modTime = modTime
+	return nil
+}
+
+// GetCertificate returns the certificate, loading it again first
+// if ReloadInterval has passed and its files changed. If it can't
+// be loaded, the previous certificate is kept, since the files may
+// be in the middle of being replaced.
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if now := time.Now(); now.Sub(r.lastCheck) >= r.cfg.ReloadInterval {
+		if err := r.load(now); err != nil {
+			log.Printf("Could not reload TLS certificate: %v", err)
+		}
+	}
+	return r.cert, nil
+}
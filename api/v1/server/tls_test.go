@@ -0,0 +1,123 @@
+package server_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/JonathanGzzBen/ingenialists/api/v1/server"
+)
+
+// writeCertificate writes a self-signed certificate for localhost
+// with serial to certFile and its key to keyFile, modified at modTime.
+func writeCertificate(t *testing.T, certFile string, keyFile string, serial int64, modTime time.Time) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	files := map[string]*pem.Block{
+		certFile: {Type: "CERTIFICATE", Bytes: der},
+		keyFile:  {Type: "EC PRIVATE KEY", Bytes: keyDER},
+	}
+	for f, b := range files {
+		if err := ioutil.WriteFile(f, pem.EncodeToMemory(b), 0600); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if err := os.Chtimes(f, modTime, modTime); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+}
+
+// servedSerial returns the serial of the certificate served on addr.
+func servedSerial(t *testing.T, addr string) int64 {
+	conn, err := tls.Dial("tcp", addr, &tls.Config{InsecureSkipVerify: true})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer conn.Close()
+	return conn.ConnectionState().PeerCertificates[0].SerialNumber.Int64()
+}
+
+func TestRunTLSReloadCertificate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer os.RemoveAll(dir)
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	writeCertificate(t, certFile, keyFile, 1, time.Now().Add(-time.Minute))
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	sc := NewTestConfig()
+	sc.TLS = &server.TLSConfig{CertFile: certFile, KeyFile: keyFile, ReloadInterval: time.Millisecond}
+	s := server.NewServer(sc)
+	go s.Run(addr)
+
+	var res *http.Response
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+	for i := 0; i < 50; i++ {
+		if res, err = client.Get("https://" + addr + "/v1/categories/"); err == nil {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+	if serial := servedSerial(t, addr); serial != 1 {
+		t.Fatalf("Expected %v, got %v", 1, serial)
+	}
+
+	writeCertificate(t, certFile, keyFile, 2, time.Now())
+	time.Sleep(10 * time.Millisecond)
+	if serial := servedSerial(t, addr); serial != 2 {
+		t.Fatalf("Expected %v, got %v", 2, serial)
+	}
+}
+
+func TestRunTLSWithMissingCertificateReturnError(t *testing.T) {
+	sc := NewTestConfig()
+	sc.TLS = &server.TLSConfig{CertFile: "missing.pem", KeyFile: "missing.pem"}
+	s := server.NewServer(sc)
+
+	if err := s.Run("127.0.0.1:0"); err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+}
@@ -0,0 +1,204 @@
+package server_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/JonathanGzzBen/ingenialists/api/v1/models"
+	"github.com/JonathanGzzBen/ingenialists/api/v1/repository"
+	"github.com/JonathanGzzBen/ingenialists/api/v1/server"
+)
+
+// doAs makes a request to ts authenticated with token, unless it's
+// empty, with the headers in header. body is sent JSON encoded,
+// unless it's nil or a string, which is sent as is.
+func doAs(t *testing.T, ts *httptest.Server, method string, path string, token string, body interface{}, header map[string]string) *http.Response {
+	var r io.Reader
+	switch b := body.(type) {
+	case nil:
+	case string:
+		r = strings.NewReader(b)
+	default:
+		encoded, err := json.Marshal(b)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		r = bytes.NewReader(encoded)
+	}
+	req, err := http.NewRequest(method, ts.URL+path, r)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if token != "" {
+		req.Header.Set(server.AccessTokenName, token)
+	}
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	res, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return res
+}
+
+// createArticle registers a category and an article in it
+// written by user with ID = 1, the authenticated user in testing mode.
+func createArticle(t *testing.T, s *server.Server) *models.Article {
+	c, err := s.CategoriesRepo.CreateCategory(context.Background(), &models.Category{Name: "Databases", Slug: "databases"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	a, err := s.ArticlesRepo.CreateArticle(context.Background(), &models.Article{UserID: 1, CategoryID: c.ID, Title: "First article"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return a
+}
+
+func TestDeleteArticleMovesItToTrash(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	a := createArticle(t, s)
+
+	res := doAs(t, ts, http.MethodDelete, fmt.Sprintf("/v1/articles/%d", a.ID), "Writer", nil, nil)
+	if res.StatusCode != http.StatusNoContent {
+		t.Fatalf("Expected status code %v, got %v", http.StatusNoContent, res.StatusCode)
+	}
+
+	res = doAs(t, ts, http.MethodGet, fmt.Sprintf("/v1/articles/%d", a.ID), "Writer", nil, nil)
+	if res.StatusCode != http.StatusNotFound {
+		t.Fatalf("Expected status code %v, got %v", http.StatusNotFound, res.StatusCode)
+	}
+
+	res = doAs(t, ts, http.MethodGet, "/v1/articles/trash", "Writer", nil, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+	var trash []models.Article
+	err := json.NewDecoder(res.Body).Decode(&trash)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(trash) != 1 {
+		t.Fatalf("Expected %v, got %v", 1, len(trash))
+	}
+	if trash[0].DeletedBy == nil || *trash[0].DeletedBy != 1 {
+		t.Fatalf("Expected article to be deleted by user %v, got %v", 1, trash[0].DeletedBy)
+	}
+}
+
+func TestRestoreArticleAsOwnerReturnOk(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	a := createArticle(t, s)
+	if err := s.ArticlesRepo.DeleteArticle(context.Background(), a.ID, 1); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	res := doAs(t, ts, http.MethodPost, fmt.Sprintf("/v1/articles/%d/restore", a.ID), "Writer", nil, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+
+	res = doAs(t, ts, http.MethodGet, fmt.Sprintf("/v1/articles/%d", a.ID), "Writer", nil, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+}
+
+func TestRestoreArticleNotInTrashReturnNotFound(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	a := createArticle(t, s)
+
+	res := doAs(t, ts, http.MethodPost, fmt.Sprintf("/v1/articles/%d/restore", a.ID), "Writer", nil, nil)
+	if res.StatusCode != http.StatusNotFound {
+		t.Fatalf("Expected status code %v, got %v", http.StatusNotFound, res.StatusCode)
+	}
+}
+
+func TestGetDeletedCategoriesAsWriterReturnForbidden(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	res := doAs(t, ts, http.MethodGet, "/v1/categories/trash", "Writer", nil, nil)
+	if res.StatusCode != http.StatusForbidden {
+		t.Fatalf("Expected status code %v, got %v", http.StatusForbidden, res.StatusCode)
+	}
+}
+
+func TestRestoreCategoryAsAdministratorReturnOk(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	c, err := s.CategoriesRepo.CreateCategory(context.Background(), &models.Category{Name: "Databases", Slug: "databases"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := s.CategoriesRepo.DeleteCategory(context.Background(), c.ID, 1, repository.CategoryDeletion{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	res := doAs(t, ts, http.MethodGet, "/v1/categories/trash", "Administrator", nil, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+	var trash []models.Category
+	err = json.NewDecoder(res.Body).Decode(&trash)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(trash) != 1 {
+		t.Fatalf("Expected %v, got %v", 1, len(trash))
+	}
+
+	res = doAs(t, ts, http.MethodPost, fmt.Sprintf("/v1/categories/%d/restore", c.ID), "Administrator", nil, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v", http.StatusOK, res.StatusCode)
+	}
+}
+
+func TestPurgeArticlesOnlyRemovesTrashOlderThanCutoff(t *testing.T) {
+	s := NewTestServer()
+
+	a := createArticle(t, s)
+	if err := s.ArticlesRepo.DeleteArticle(context.Background(), a.ID, 1); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	n, err := s.ArticlesRepo.PurgeArticles(context.Background(), time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if n != 0 {
+		t.Fatalf("Expected %v, got %v", 0, n)
+	}
+
+	n, err = s.ArticlesRepo.PurgeArticles(context.Background(), time.Now().Add(time.Second))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("Expected %v, got %v", 1, n)
+	}
+	if _, err := s.ArticlesRepo.GetDeletedArticle(context.Background(), a.ID); err == nil {
+		t.Fatalf("Expected article to be purged")
+	}
+}
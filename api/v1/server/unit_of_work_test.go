@@ -0,0 +1,102 @@
+package server_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/JonathanGzzBen/ingenialists/api/v1/models"
+	"github.com/JonathanGzzBen/ingenialists/api/v1/repository"
+	"github.com/JonathanGzzBen/ingenialists/api/v1/repository/mocks"
+	"github.com/JonathanGzzBen/ingenialists/api/v1/server"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestUnitOfWorkDiscardsChangesWhenItFails(t *testing.T) {
+	s := NewTestServer()
+
+	errFailed := errors.New("failed")
+	err := s.UnitOfWork.Do(context.Background(), repository.Repositories{
+		Users:      s.UsersRepo,
+		Articles:   s.ArticlesRepo,
+		Categories: s.CategoriesRepo,
+	}, func(r repository.Repositories) error {
+		c, err := r.Categories.CreateCategory(context.Background(), &models.Category{Name: "Databases", Slug: "databases"})
+		if err != nil {
+			return err
+		}
+		if _, err := r.Articles.CreateArticle(context.Background(), &models.Article{UserID: 1, CategoryID: c.ID, Title: "First article"}); err != nil {
+			return err
+		}
+		return errFailed
+	})
+	if err != errFailed {
+		t.Fatalf("Expected %v, got %v", errFailed, err)
+	}
+
+	categories, err := s.CategoriesRepo.GetAllCategories(context.Background(), repository.Projection{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	articles, err := s.ArticlesRepo.GetAllArticles(context.Background(), repository.ArticlesQuery{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(categories) != 0 || len(articles) != 0 {
+		t.Fatalf("Expected no categories nor articles, got %v and %v", categories, articles)
+	}
+}
+
+func TestCreateArticleWhenUnitOfWorkFailsReturnInternalServerError(t *testing.T) {
+	s := NewTestServer()
+	ts := httptest.NewServer(s.Router)
+	defer ts.Close()
+
+	c, err := s.CategoriesRepo.CreateCategory(context.Background(), &models.Category{Name: "Databases", Slug: "databases"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	mockUnitOfWork := &mocks.UnitOfWork{}
+	mockUnitOfWork.On("Do", mock.Anything, mock.Anything, mock.Anything).Return(repository.ErrCouldNotCreate)
+	s.UnitOfWork = mockUnitOfWork
+
+	body, err := json.Marshal(server.CreateArticleDTO{CategoryID: c.ID, Title: "First article"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/v1/articles", ts.URL), bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	req.Header.Add(server.AccessTokenName, "Writer")
+	res, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if res.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("Expected status code %v, got %v", http.StatusInternalServerError, res.StatusCode)
+	}
+	mockUnitOfWork.AssertExpectations(t)
+}
+
+func TestUsersWithSameGoogleSubCantBeCreated(t *testing.T) {
+	s := NewTestServer()
+
+	if _, err := s.UsersRepo.CreateUser(context.Background(), &models.User{Name: "First user", GoogleSub: "sub"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := s.UsersRepo.CreateUser(context.Background(), &models.User{Name: "Second user", GoogleSub: "sub"}); err != repository.ErrCouldNotCreate {
+		t.Fatalf("Expected %v, got %v", repository.ErrCouldNotCreate, err)
+	}
+	// Users that didn't log in with Google have no sub
+	for _, name := range []string{"Third user", "Fourth user"} {
+		if _, err := s.UsersRepo.CreateUser(context.Background(), &models.User{Name: name}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+}
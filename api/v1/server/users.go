@@ -25,16 +25,23 @@ type UpdateUserDTO struct {
 // 	@Summary Get all users
 // 	@Description Get all registered users.
 // 	@Tags users
+// 	@Param fields query string false "Fields to include separated by commas"
 // 	@Success 200 {array} models.User
+// 	@Failure 400 {object} models.APIError
 // 	@Failure 500 {object} models.APIError
 // 	@Router /users [get]
 func (s *Server) GetAllUsers(c *gin.Context) {
-	users, err := s.UsersRepo.GetAllUsers()
+	p := projection(c)
+	if err := checkProjection(p, models.User{}, userAssociations); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: err.Error()})
+		return
+	}
+	users, err := s.UsersRepo.GetAllUsers(c.Request.Context(), p)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: "could not connect to database"})
 		return
 	}
-	c.JSON(http.StatusOK, users)
+	renderProjection(c, http.StatusOK, users, p, userAssociations)
 }
 
 // GetUser is the handler for GET requests to /users/:id
@@ -43,16 +50,23 @@ func (s *Server) GetAllUsers(c *gin.Context) {
 // 	@Description Get user with matching ID.
 // 	@Tags users
 // 	@Param id path int true "User ID"
+// 	@Param fields query string false "Fields to include separated by commas"
 // 	@Success 200 {object} models.User
+// 	@Failure 400 {object} models.APIError
 // 	@Failure 404 {object} models.APIError
 // 	@Router /users/{id} [get]
 func (s *Server) GetUser(c *gin.Context) {
+	p := projection(c)
+	if err := checkProjection(p, models.User{}, userAssociations); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: err.Error()})
+		return
+	}
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "invalid id: " + err.Error()})
 		return
 	}
-	user, err := s.UsersRepo.GetUser(uint(id))
+	user, err := s.UsersRepo.GetUser(c.Request.Context(), uint(id), p)
 	if err == repository.ErrNotFound {
 		c.JSON(http.StatusNotFound, models.APIError{Code: http.StatusNotFound, Message: "user with provided id not found"})
 		return
@@ -61,62 +75,138 @@ func (s *Server) GetUser(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, user)
+	lastModified(c, user.UpdatedAt)
+	etagVersion(c, user.Version)
+	renderProjection(c, http.StatusOK, user, p, userAssociations)
 }
 
 // UpdateUser is the handler for PUT requests to /users/:id
 // 	@ID UpdateUser
 // 	@Summary Update user
 // 	@Description Update matching user with provided data.
+// 	@Description If the user changed since the version in If-Match,
+// 	@Description it's not updated and its current version is returned.
 // 	@Tags users
 // 	@Security AccessToken
 // 	@Param id path int true "User ID"
+// 	@Param If-Match header string false "ETag of the user being updated"
 // 	@Param user body UpdateUserDTO true "User"
 // 	@Success 200 {object} models.User
 // 	@Failure 400 {object} models.APIError
+// 	@Failure 404 {object} models.APIError
+// 	@Failure 412 {object} models.User
 // 	@Router /users/{id} [put]
 func (s *Server) UpdateUser(c *gin.Context) {
-	at := c.GetHeader(AccessTokenName)
-	au, err := s.userByAccessToken(at)
+	s.updateUser(c, false)
+}
+
+// PatchUser is the handler for PATCH requests to /users/:id
+// 	@ID PatchUser
+// 	@Summary Patch user
+// 	@Description Changes only the fields of matching user present in a
+// 	@Description JSON merge patch (application/merge-patch+json) or the fields
+// 	@Description changed by a JSON Patch (application/json-patch+json).
+// 	@Tags users
+// 	@Accept json
+// 	@Security AccessToken
+// 	@Param id path int true "User ID"
+// 	@Param If-Match header string false "ETag of the user being patched"
+// 	@Param patch body UpdateUserDTO true "User fields to change"
+// 	@Success 200 {object} models.User
+// 	@Failure 400 {object} models.APIError
+// 	@Failure 404 {object} models.APIError
+// 	@Failure 412 {object} models.User
+// 	@Router /users/{id} [patch]
+func (s *Server) PatchUser(c *gin.Context) {
+	s.updateUser(c, true)
+}
+
+// updateUser updates the user with the DTO in the request in c,
+// or with the DTO of the user patched with it if patch is true.
+func (s *Server) updateUser(c *gin.Context, patch bool) {
+	au, err := s.authenticate(c)
 	if err != nil {
 		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "not authenticated: " + err.Error()})
 		return
 	}
+	if !hasScope(c, models.ScopeUsersWrite) {
+		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "api key lacks scope " + string(models.ScopeUsersWrite)})
+		return
+	}
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "id is not a valid"})
 		return
 	}
-	if au.ID != uint(id) && au.Role != models.RoleAdministrator {
+	if au.ID != uint(id) && !s.can(c.Request.Context(), au, models.PermissionUserRoleAssign) {
 		c.JSON(http.StatusForbidden, models.APIError{Code: http.StatusForbidden, Message: "id does not match authenticated user"})
 		return
 	}
+	// The user is read once, so that the patch is applied to the same
+	// version that's checked against If-Match and saved
+	u, err := s.UsersRepo.GetUser(c.Request.Context(), uint(id), repository.Projection{})
+	if err == repository.ErrNotFound {
+		c.JSON(http.StatusNotFound, models.APIError{Code: http.StatusNotFound, Message: "user with provided id not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIError{Code: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+	if !ifMatch(c, u.Version) {
+		c.JSON(http.StatusPreconditionFailed, u)
+		return
+	}
 	var uu UpdateUserDTO
-	if err := c.ShouldBindJSON(&uu); err != nil {
+	if patch {
+		uu = UpdateUserDTO{
+			Name:              u.Name,
+			Birthdate:         u.Birthdate,
+			Gender:            u.Gender,
+			ProfilePictureURL: u.ProfilePictureURL,
+			Description:       u.Description,
+			ShortDescription:  u.ShortDescription,
+			Role:              u.Role,
+		}
+		err = bindPatch(c, &uu)
+	} else {
+		err = c.ShouldBindJSON(&uu)
+	}
+	if err != nil {
 		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusNotFound, Message: "invalid update user: " + err.Error()})
 		return
 	}
-	// If administrator is updating other user
-	if au.Role == models.RoleAdministrator && uint(id) != au.ID {
-		u, err := s.UsersRepo.GetUser(uint(id))
-		if err != nil {
-			c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusNotFound, Message: err.Error()})
+	// If user is assigning a role to other user
+	if uint(id) != au.ID {
+		if _, err := s.RolesRepo.GetRole(c.Request.Context(), uu.Role); err != nil {
+			c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "unknown role " + string(uu.Role)})
 			return
 		}
-		// Administrators can only change Role of other users
+		// Only Role of other users can be changed
+		before := *u
 		u.Role = uu.Role
-		u, err = s.UsersRepo.UpdateUser(u)
+		u, err = s.UsersRepo.UpdateUser(c.Request.Context(), u)
+		if err == repository.ErrVersionConflict {
+			// Someone else updated the user since it was read
+			if current, err := s.UsersRepo.GetUser(c.Request.Context(), uint(id), repository.Projection{}); err == nil {
+				c.JSON(http.StatusPreconditionFailed, current)
+				return
+			}
+		}
 		if err != nil {
 			c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusNotFound, Message: "could not update user: " + err.Error()})
 			return
 		}
+		if before.Role != u.Role {
+			s.audit(c, au, models.AuditUserRoleUpdate, "user", u.ID, before, u)
+		}
 		c.JSON(http.StatusOK, u)
 		return
 	}
 	// User is updating his own information
-	u, err := s.UsersRepo.GetUser(uint(id))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusNotFound, Message: "not registered user"})
+	// Users can't change their own role, they must request it
+	if uu.Role != "" && uu.Role != u.Role {
+		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusBadRequest, Message: "you can't change your own role, request it at /role-requests"})
 		return
 	}
 	u.Name = uu.Name
@@ -125,7 +215,14 @@ func (s *Server) UpdateUser(c *gin.Context) {
 	u.ProfilePictureURL = uu.ProfilePictureURL
 	u.Description = uu.Description
 	u.ShortDescription = uu.ShortDescription
-	u, err = s.UsersRepo.UpdateUser(u)
+	u, err = s.UsersRepo.UpdateUser(c.Request.Context(), u)
+	if err == repository.ErrVersionConflict {
+		// Someone else updated the user since it was read
+		if current, err := s.UsersRepo.GetUser(c.Request.Context(), uint(id), repository.Projection{}); err == nil {
+			c.JSON(http.StatusPreconditionFailed, current)
+			return
+		}
+	}
 	if err != nil {
 		c.JSON(http.StatusBadRequest, models.APIError{Code: http.StatusNotFound, Message: err.Error()})
 		return
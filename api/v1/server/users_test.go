@@ -2,6 +2,7 @@ package server_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -9,8 +10,10 @@ import (
 	"testing"
 
 	"github.com/JonathanGzzBen/ingenialists/api/v1/models"
+	"github.com/JonathanGzzBen/ingenialists/api/v1/repository"
 	"github.com/JonathanGzzBen/ingenialists/api/v1/repository/mocks"
 	"github.com/JonathanGzzBen/ingenialists/api/v1/server"
+	"github.com/stretchr/testify/mock"
 )
 
 var mockUsers = []models.User{
@@ -25,11 +28,11 @@ func TestGetAllUsers(t *testing.T) {
 	defer ts.Close()
 
 	for _, u := range mockUsers {
-		s.UsersRepo.CreateUser(&u)
+		s.UsersRepo.CreateUser(context.Background(), &u)
 	}
 
 	mockUsersRepo := &mocks.UsersRepository{}
-	mockUsersRepo.On("GetAllUsers").Return(mockUsers, nil)
+	mockUsersRepo.On("GetAllUsers", mock.Anything, repository.Projection{}).Return(mockUsers, nil)
 	s.UsersRepo = mockUsersRepo
 
 	res, err := http.Get(fmt.Sprintf("%s/v1/users", ts.URL))
@@ -66,7 +69,7 @@ func TestGetUser(t *testing.T) {
 	uToGet := mockUsers[1]
 
 	mockUsersRepo := &mocks.UsersRepository{}
-	mockUsersRepo.On("GetUser", uToGet.ID).Return(&uToGet, nil)
+	mockUsersRepo.On("GetUser", mock.Anything, uToGet.ID, mock.Anything).Return(&uToGet, nil)
 	s.UsersRepo = mockUsersRepo
 
 	res, err := http.Get(fmt.Sprintf("%s/v1/users/%d", ts.URL, uToGet.ID))
@@ -105,8 +108,8 @@ func TestUpdateUserChangeNameAsAdministratorReturnOkDontMakeChanges(t *testing.T
 	uUpdated.Name = "User Updated"
 
 	mockUsersRepo := &mocks.UsersRepository{}
-	mockUsersRepo.On("GetUser", uToUpdate.ID).Return(&uToUpdate, nil)
-	mockUsersRepo.On("UpdateUser", &uToUpdate).Return(&uToUpdate, nil)
+	mockUsersRepo.On("GetUser", mock.Anything, uToUpdate.ID, mock.Anything).Return(&uToUpdate, nil)
+	mockUsersRepo.On("UpdateUser", mock.Anything, &uToUpdate).Return(&uToUpdate, nil)
 	s.UsersRepo = mockUsersRepo
 
 	muJSONBytes, err := json.Marshal(uUpdated)
@@ -155,8 +158,8 @@ func TestUpdateUserChangeRoleAsAdministratorReturnOk(t *testing.T) {
 	uUpdated.Role = models.RoleAdministrator
 
 	mockUsersRepo := &mocks.UsersRepository{}
-	mockUsersRepo.On("GetUser", uToUpdate.ID).Return(&uToUpdate, nil)
-	mockUsersRepo.On("UpdateUser", &uUpdated).Return(&uUpdated, nil)
+	mockUsersRepo.On("GetUser", mock.Anything, uToUpdate.ID, mock.Anything).Return(&uToUpdate, nil)
+	mockUsersRepo.On("UpdateUser", mock.Anything, &uUpdated).Return(&uUpdated, nil)
 	s.UsersRepo = mockUsersRepo
 
 	muJSONBytes, err := json.Marshal(uUpdated)
@@ -254,8 +257,8 @@ func TestUpdateUserChangeNameAsSameUserReturnOk(t *testing.T) {
 	uUpdated.Name = "Updated name"
 
 	mockUsersRepo := &mocks.UsersRepository{}
-	mockUsersRepo.On("GetUser", uToUpdate.ID).Return(&uToUpdate, nil)
-	mockUsersRepo.On("UpdateUser", &uUpdated).Return(&uUpdated, nil)
+	mockUsersRepo.On("GetUser", mock.Anything, uToUpdate.ID, mock.Anything).Return(&uToUpdate, nil)
+	mockUsersRepo.On("UpdateUser", mock.Anything, &uUpdated).Return(&uUpdated, nil)
 	s.UsersRepo = mockUsersRepo
 
 	muJSONBytes, err := json.Marshal(uUpdated)